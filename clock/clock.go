@@ -0,0 +1,59 @@
+package clock
+
+import "time"
+
+// Clock source of the current time and of timers, it allows time-based types
+// to be driven by a fake clock in tests
+type Clock interface {
+	// Now returns the current time
+	Now() time.Time
+	// NewTimer creates a timer which fires after the duration
+	NewTimer(d time.Duration) Timer
+	// After waits for the duration to elapse and then sends the current time on the returned channel
+	After(d time.Duration) <-chan time.Time
+}
+
+// Timer timer created by a clock
+type Timer interface {
+	// C returns the channel on which the time is delivered when the timer fires
+	C() <-chan time.Time
+	// Stop prevents the timer from firing, it returns false if the timer has already expired or been stopped
+	Stop() bool
+	// Reset changes the timer to expire after the duration, it returns true if the timer had been active
+	Reset(d time.Duration) bool
+}
+
+// NewRealClock returns the clock backed by the time package
+func NewRealClock() Clock {
+	return realClock{}
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) NewTimer(d time.Duration) Timer {
+	return &realTimer{time.NewTimer(d)}
+}
+
+func (realClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+type realTimer struct {
+	timer *time.Timer
+}
+
+func (t *realTimer) C() <-chan time.Time {
+	return t.timer.C
+}
+
+func (t *realTimer) Stop() bool {
+	return t.timer.Stop()
+}
+
+func (t *realTimer) Reset(d time.Duration) bool {
+	return t.timer.Reset(d)
+}
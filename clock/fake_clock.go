@@ -0,0 +1,141 @@
+package clock
+
+import (
+	"slices"
+	"sync"
+	"time"
+)
+
+var _ Clock = (*FakeClock)(nil)
+
+// NewFakeClock new fake clock starting at the given time
+func NewFakeClock(now time.Time) *FakeClock {
+	clock := new(FakeClock)
+	clock.now = now
+	clock.changed = sync.NewCond(&clock.mu)
+	return clock
+}
+
+// FakeClock clock whose time only moves when it is advanced,
+// the timers fire synchronously inside Advance and Set
+type FakeClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	timers  []*fakeTimer
+	changed *sync.Cond
+}
+
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *FakeClock) NewTimer(d time.Duration) Timer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	timer := &fakeTimer{clock: c, c: make(chan time.Time, 1)}
+	c.schedule(timer, d)
+	return timer
+}
+
+func (c *FakeClock) After(d time.Duration) <-chan time.Time {
+	return c.NewTimer(d).C()
+}
+
+// Advance moves the clock forward by the duration and fires the expired timers
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.set(c.now.Add(d))
+}
+
+// Set moves the clock to the given time and fires the expired timers
+func (c *FakeClock) Set(now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.set(now)
+}
+
+// Timers returns the number of active timers
+func (c *FakeClock) Timers() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.timers)
+}
+
+// BlockUntil blocks until there are at least n active timers, it is used by
+// tests to wait for a goroutine to start waiting on the clock before advancing it
+func (c *FakeClock) BlockUntil(n int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for len(c.timers) < n {
+		c.changed.Wait()
+	}
+}
+
+func (c *FakeClock) set(now time.Time) {
+	c.now = now
+	for len(c.timers) > 0 && !c.timers[0].until.After(now) {
+		timer := c.timers[0]
+		c.timers = c.timers[1:]
+		select {
+		case timer.c <- now:
+		default:
+		}
+	}
+	c.changed.Broadcast()
+}
+
+func (c *FakeClock) schedule(timer *fakeTimer, d time.Duration) {
+	timer.until = c.now.Add(d)
+	if d <= 0 {
+		select {
+		case timer.c <- c.now:
+		default:
+		}
+		return
+	}
+	index, _ := slices.BinarySearchFunc(c.timers, timer.until, func(t *fakeTimer, until time.Time) int {
+		if t.until.After(until) {
+			return 1
+		}
+		return -1
+	})
+	c.timers = slices.Insert(c.timers, index, timer)
+	c.changed.Broadcast()
+}
+
+func (c *FakeClock) unschedule(timer *fakeTimer) bool {
+	index := slices.Index(c.timers, timer)
+	if index < 0 {
+		return false
+	}
+	c.timers = slices.Delete(c.timers, index, index+1)
+	c.changed.Broadcast()
+	return true
+}
+
+type fakeTimer struct {
+	clock *FakeClock
+	c     chan time.Time
+	until time.Time
+}
+
+func (t *fakeTimer) C() <-chan time.Time {
+	return t.c
+}
+
+func (t *fakeTimer) Stop() bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	return t.clock.unschedule(t)
+}
+
+func (t *fakeTimer) Reset(d time.Duration) bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	active := t.clock.unschedule(t)
+	t.clock.schedule(t, d)
+	return active
+}
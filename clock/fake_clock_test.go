@@ -0,0 +1,63 @@
+package clock
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFakeClock_Now(t *testing.T) {
+	start := time.Date(2024, 5, 17, 0, 0, 0, 0, time.UTC)
+	clock := NewFakeClock(start)
+	assert.Equal(t, start, clock.Now())
+	clock.Advance(time.Hour)
+	assert.Equal(t, start.Add(time.Hour), clock.Now())
+}
+
+func TestFakeClock_NewTimer(t *testing.T) {
+	clock := NewFakeClock(time.Now())
+	first := clock.NewTimer(time.Second)
+	second := clock.NewTimer(2 * time.Second)
+	assert.Equal(t, 2, clock.Timers())
+	clock.Advance(time.Second)
+	select {
+	case <-first.C():
+	default:
+		assert.Fail(t, "timer did not fire")
+	}
+	select {
+	case <-second.C():
+		assert.Fail(t, "timer fired early")
+	default:
+	}
+	assert.False(t, first.Stop())
+	assert.True(t, second.Stop())
+	assert.Equal(t, 0, clock.Timers())
+}
+
+func TestFakeClock_Reset(t *testing.T) {
+	clock := NewFakeClock(time.Now())
+	timer := clock.NewTimer(time.Second)
+	assert.True(t, timer.Reset(3*time.Second))
+	clock.Advance(2 * time.Second)
+	assert.Len(t, timer.C(), 0)
+	clock.Advance(time.Second)
+	assert.Len(t, timer.C(), 1)
+}
+
+func TestFakeClock_After(t *testing.T) {
+	clock := NewFakeClock(time.Now())
+	done := make(chan struct{})
+	go func() {
+		<-clock.After(time.Minute)
+		close(done)
+	}()
+	clock.BlockUntil(1)
+	clock.Advance(time.Minute)
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		assert.Fail(t, "after did not fire")
+	}
+}
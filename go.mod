@@ -4,8 +4,6 @@ go 1.22.2
 
 require (
 	github.com/gopi-frame/contract v0.0.0-20240517013806-dc3242b222d8
-	github.com/gopi-frame/exception v0.0.0-20240517030638-8d9d2c8deff7
-	github.com/gopi-frame/future v0.0.0-20240517035507-5af5a2f786bd
 	github.com/gopi-frame/types v0.0.0-20240517030225-81f02c613247
 	github.com/stretchr/testify v1.9.0
 )
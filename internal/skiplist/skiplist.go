@@ -0,0 +1,256 @@
+package skiplist
+
+import (
+	"math/bits"
+	"math/rand/v2"
+)
+
+// MaxLevel maximum number of levels of a skip list
+const MaxLevel = 32
+
+type node[E any] struct {
+	value E
+	next  []*node[E]
+	// span[i] is the number of elements from the node to next[i], or to the end
+	span []int
+}
+
+// New new skip list ordered by the compare function
+func New[E any](compare func(a, b E) int) *List[E] {
+	return &List[E]{
+		head:    &node[E]{next: make([]*node[E], MaxLevel), span: make([]int, MaxLevel)},
+		level:   1,
+		compare: compare,
+	}
+}
+
+// List indexable skip list of distinct elements, it is not safe for concurrent use.
+//
+// Every level links a quarter of the elements of the level below, and the links
+// count the elements they skip so that the rank of an element is found while
+// searching it. All the operations are O(log n) on average.
+type List[E any] struct {
+	head    *node[E]
+	level   int
+	count   int
+	compare func(a, b E) int
+}
+
+func randomLevel() int {
+	// every level is kept with a probability of 1/4
+	level := 1 + bits.TrailingZeros64(rand.Uint64())/2
+	return min(level, MaxLevel)
+}
+
+// Len returns the number of elements
+func (l *List[E]) Len() int {
+	return l.count
+}
+
+// Insert adds the value, or replaces the equal element; it returns true when the value was added
+func (l *List[E]) Insert(value E) bool {
+	var update [MaxLevel]*node[E]
+	var rank [MaxLevel]int
+	x := l.head
+	for i := l.level - 1; i >= 0; i-- {
+		if i < l.level-1 {
+			rank[i] = rank[i+1]
+		}
+		for x.next[i] != nil && l.compare(x.next[i].value, value) < 0 {
+			rank[i] += x.span[i]
+			x = x.next[i]
+		}
+		update[i] = x
+	}
+	if next := x.next[0]; next != nil && l.compare(next.value, value) == 0 {
+		next.value = value
+		return false
+	}
+	level := randomLevel()
+	if level > l.level {
+		for i := l.level; i < level; i++ {
+			rank[i] = 0
+			update[i] = l.head
+			update[i].span[i] = l.count
+		}
+		l.level = level
+	}
+	n := &node[E]{value: value, next: make([]*node[E], level), span: make([]int, level)}
+	for i := 0; i < level; i++ {
+		n.next[i] = update[i].next[i]
+		update[i].next[i] = n
+		n.span[i] = update[i].span[i] - (rank[0] - rank[i])
+		update[i].span[i] = rank[0] - rank[i] + 1
+	}
+	for i := level; i < l.level; i++ {
+		update[i].span[i]++
+	}
+	l.count++
+	return true
+}
+
+// Remove removes the element equal to the value and returns it
+func (l *List[E]) Remove(value E) (E, bool) {
+	var update [MaxLevel]*node[E]
+	x := l.head
+	for i := l.level - 1; i >= 0; i-- {
+		for x.next[i] != nil && l.compare(x.next[i].value, value) < 0 {
+			x = x.next[i]
+		}
+		update[i] = x
+	}
+	x = x.next[0]
+	if x == nil || l.compare(x.value, value) != 0 {
+		return *new(E), false
+	}
+	for i := 0; i < l.level; i++ {
+		if update[i].next[i] == x {
+			update[i].span[i] += x.span[i] - 1
+			update[i].next[i] = x.next[i]
+		} else {
+			update[i].span[i]--
+		}
+	}
+	for l.level > 1 && l.head.next[l.level-1] == nil {
+		l.level--
+	}
+	l.count--
+	return x.value, true
+}
+
+// lowerBound returns the last node whose value is less than the value, or the head
+func (l *List[E]) lowerBound(value E) *node[E] {
+	x := l.head
+	for i := l.level - 1; i >= 0; i-- {
+		for x.next[i] != nil && l.compare(x.next[i].value, value) < 0 {
+			x = x.next[i]
+		}
+	}
+	return x
+}
+
+// Find returns the element equal to the value
+func (l *List[E]) Find(value E) (E, bool) {
+	if x := l.lowerBound(value).next[0]; x != nil && l.compare(x.value, value) == 0 {
+		return x.value, true
+	}
+	return *new(E), false
+}
+
+// Floor returns the greatest element less than or equal to the value
+func (l *List[E]) Floor(value E) (E, bool) {
+	x := l.head
+	for i := l.level - 1; i >= 0; i-- {
+		for x.next[i] != nil && l.compare(x.next[i].value, value) <= 0 {
+			x = x.next[i]
+		}
+	}
+	if x == l.head {
+		return *new(E), false
+	}
+	return x.value, true
+}
+
+// Ceiling returns the least element greater than or equal to the value
+func (l *List[E]) Ceiling(value E) (E, bool) {
+	if x := l.lowerBound(value).next[0]; x != nil {
+		return x.value, true
+	}
+	return *new(E), false
+}
+
+// Rank returns the number of elements less than the value
+func (l *List[E]) Rank(value E) int {
+	rank := 0
+	x := l.head
+	for i := l.level - 1; i >= 0; i-- {
+		for x.next[i] != nil && l.compare(x.next[i].value, value) < 0 {
+			rank += x.span[i]
+			x = x.next[i]
+		}
+	}
+	return rank
+}
+
+// At returns the element of the given rank
+func (l *List[E]) At(rank int) (E, bool) {
+	if rank < 0 || rank >= l.count {
+		return *new(E), false
+	}
+	traversed := 0
+	x := l.head
+	for i := l.level - 1; i >= 0; i-- {
+		for x.next[i] != nil && traversed+x.span[i] <= rank+1 {
+			traversed += x.span[i]
+			x = x.next[i]
+		}
+		if traversed == rank+1 {
+			break
+		}
+	}
+	return x.value, true
+}
+
+// First returns the least element
+func (l *List[E]) First() (E, bool) {
+	if x := l.head.next[0]; x != nil {
+		return x.value, true
+	}
+	return *new(E), false
+}
+
+// Last returns the greatest element
+func (l *List[E]) Last() (E, bool) {
+	x := l.head
+	for i := l.level - 1; i >= 0; i-- {
+		for x.next[i] != nil {
+			x = x.next[i]
+		}
+	}
+	if x == l.head {
+		return *new(E), false
+	}
+	return x.value, true
+}
+
+// Range calls the callback with the elements in [from, to) in order until it returns false
+func (l *List[E]) Range(from, to E, callback func(value E) bool) {
+	l.Ascend(from, func(value E) bool {
+		return l.compare(value, to) < 0 && callback(value)
+	})
+}
+
+// Ascend calls the callback with the elements greater than or equal to the value in order until it returns false
+func (l *List[E]) Ascend(from E, callback func(value E) bool) {
+	for x := l.lowerBound(from).next[0]; x != nil; x = x.next[0] {
+		if !callback(x.value) {
+			return
+		}
+	}
+}
+
+// Each calls the callback with the elements in order until it returns false
+func (l *List[E]) Each(callback func(index int, value E) bool) {
+	for x, index := l.head.next[0], 0; x != nil; x, index = x.next[0], index+1 {
+		if !callback(index, x.value) {
+			return
+		}
+	}
+}
+
+// Values returns the elements in order
+func (l *List[E]) Values() []E {
+	values := make([]E, 0, l.count)
+	for x := l.head.next[0]; x != nil; x = x.next[0] {
+		values = append(values, x.value)
+	}
+	return values
+}
+
+// Clear removes all the elements
+func (l *List[E]) Clear() {
+	clear(l.head.next)
+	clear(l.head.span)
+	l.level = 1
+	l.count = 0
+}
@@ -0,0 +1,101 @@
+package skiplist
+
+import (
+	"cmp"
+	"math/rand"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// checkList asserts every link skips as many elements as its span and returns the elements
+func checkList(t *testing.T, l *List[int]) []int {
+	values := l.Values()
+	assert.Len(t, values, l.Len())
+	rank := map[*node[int]]int{l.head: 0}
+	for x, i := l.head.next[0], 1; x != nil; x, i = x.next[0], i+1 {
+		rank[x] = i
+	}
+	for i := 0; i < MaxLevel; i++ {
+		for x := l.head; x != nil; x = x.next[i] {
+			if i >= len(x.next) || (x == l.head && i >= l.level) {
+				break
+			}
+			if x.next[i] == nil {
+				assert.Equal(t, l.count-rank[x], x.span[i])
+			} else {
+				assert.Equal(t, rank[x.next[i]]-rank[x], x.span[i])
+				if x != l.head {
+					assert.Less(t, x.value, x.next[i].value)
+				}
+			}
+		}
+	}
+	return values
+}
+
+func TestList_Model(t *testing.T) {
+	rand := rand.New(rand.NewSource(1))
+	l := New(cmp.Compare[int])
+	model := []int{}
+	for round := 0; round < 3000; round++ {
+		value := rand.Intn(500)
+		index, found := slices.BinarySearch(model, value)
+		if rand.Intn(3) < 2 {
+			assert.Equal(t, !found, l.Insert(value))
+			if !found {
+				model = slices.Insert(model, index, value)
+			}
+		} else {
+			removed, ok := l.Remove(value)
+			assert.Equal(t, found, ok)
+			if found {
+				assert.Equal(t, value, removed)
+				model = slices.Delete(model, index, index+1)
+			}
+		}
+		if round%100 == 0 {
+			assert.Equal(t, model, checkList(t, l))
+		}
+	}
+	assert.Equal(t, model, checkList(t, l))
+	for i, value := range model {
+		assert.Equal(t, i, l.Rank(value))
+		at, ok := l.At(i)
+		assert.True(t, ok)
+		assert.Equal(t, value, at)
+	}
+	_, ok := l.At(len(model))
+	assert.False(t, ok)
+}
+
+func TestList_Search(t *testing.T) {
+	l := New(cmp.Compare[int])
+	_, ok := l.First()
+	assert.False(t, ok)
+	_, ok = l.Floor(1)
+	assert.False(t, ok)
+	for _, value := range []int{10, 20, 30} {
+		l.Insert(value)
+	}
+	floor, _ := l.Floor(25)
+	assert.Equal(t, 20, floor)
+	floor, _ = l.Floor(20)
+	assert.Equal(t, 20, floor)
+	_, ok = l.Floor(5)
+	assert.False(t, ok)
+	ceiling, _ := l.Ceiling(25)
+	assert.Equal(t, 30, ceiling)
+	_, ok = l.Ceiling(31)
+	assert.False(t, ok)
+	last, _ := l.Last()
+	assert.Equal(t, 30, last)
+	assert.Equal(t, 3, l.Rank(35))
+
+	l.Clear()
+	assert.Equal(t, 0, l.Len())
+	assert.Equal(t, []int{}, checkList(t, l))
+	l.Insert(1)
+	assert.Equal(t, []int{1}, checkList(t, l))
+}
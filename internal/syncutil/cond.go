@@ -0,0 +1,16 @@
+package syncutil
+
+import (
+	"sync"
+	"time"
+)
+
+// BroadcastAfter broadcasts the condition once the duration elapses,
+// so that the timed waiters on it wake up to check their deadline
+func BroadcastAfter(cond *sync.Cond, duration time.Duration) *time.Timer {
+	return time.AfterFunc(duration, func() {
+		cond.L.Lock()
+		defer cond.L.Unlock()
+		cond.Broadcast()
+	})
+}
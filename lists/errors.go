@@ -0,0 +1,39 @@
+package lists
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrNilComparator is returned when unmarshalling into a sorted list which was not created by its constructor
+var ErrNilComparator = errors.New("lists: sorted list has no comparator")
+
+// RangeError error of an index out of the range of a list
+type RangeError struct {
+	Index int
+	Count int
+}
+
+func (e *RangeError) Error() string {
+	return fmt.Sprintf("lists: index %d out of range [%d, %d)", e.Index, -e.Count, e.Count)
+}
+
+// BoundsError error of a range [From, To) which is not within a list
+type BoundsError struct {
+	From  int
+	To    int
+	Count int
+}
+
+func (e *BoundsError) Error() string {
+	return fmt.Sprintf("lists: range [%d, %d) out of bounds [0, %d]", e.From, e.To, e.Count)
+}
+
+// resolveIndex returns the position of the index in a list of the given count,
+// negative indexes count from the end
+func resolveIndex(index, count int) (int, bool) {
+	if index < 0 {
+		index += count
+	}
+	return index, index >= 0 && index < count
+}
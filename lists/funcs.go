@@ -0,0 +1,148 @@
+package lists
+
+import (
+	"fmt"
+
+	"github.com/gopi-frame/contract/support"
+)
+
+// Pair pair of values zipped from two lists
+type Pair[A, B any] struct {
+	First  A `json:"first"`
+	Second B `json:"second"`
+}
+
+// Chunk splits the list into chunks of the given size, the last chunk may be shorter
+func Chunk[E any](list support.List[E], size int) *List[*List[E]] {
+	if size <= 0 {
+		panic(fmt.Sprintf("lists: invalid chunk size %d", size))
+	}
+	chunks := NewList[*List[E]]()
+	var chunk *List[E]
+	list.Each(func(_ int, value E) bool {
+		if chunk == nil || len(chunk.items) == size {
+			chunk = NewList[E]()
+			chunks.Push(chunk)
+		}
+		chunk.Push(value)
+		return true
+	})
+	return chunks
+}
+
+// Map returns a list of the values returned by the callback for the elements of the list
+func Map[E, R any](list support.List[E], callback func(value E) R) *List[R] {
+	result := NewList[R]()
+	result.items = make([]R, 0, list.Count())
+	list.Each(func(_ int, value E) bool {
+		result.items = append(result.items, callback(value))
+		return true
+	})
+	return result
+}
+
+// FlatMap returns a list of the values returned by the callback for the elements of the list, flattened
+func FlatMap[E, R any](list support.List[E], callback func(value E) []R) *List[R] {
+	result := NewList[R]()
+	list.Each(func(_ int, value E) bool {
+		result.Push(callback(value)...)
+		return true
+	})
+	return result
+}
+
+// Reduce folds the elements of the list into a single value, starting from the initial value
+func Reduce[E, R any](list support.List[E], initial R, callback func(carry R, value E) R) R {
+	list.Each(func(_ int, value E) bool {
+		initial = callback(initial, value)
+		return true
+	})
+	return initial
+}
+
+// GroupBy groups the elements of the list by the key returned by the callback,
+// the elements of a group keep their order.
+//
+// It returns a plain Go map rather than a maps.Map: the maps package imports lists
+// for LinkedMap, so lists cannot import it back without an import cycle.
+func GroupBy[E any, K comparable](list support.List[E], callback func(value E) K) map[K]*List[E] {
+	groups := make(map[K]*List[E])
+	list.Each(func(_ int, value E) bool {
+		key := callback(value)
+		group, ok := groups[key]
+		if !ok {
+			group = NewList[E]()
+			groups[key] = group
+		}
+		group.Push(value)
+		return true
+	})
+	return groups
+}
+
+// Partition splits the list into the elements which match the callback and the others
+func Partition[E any](list support.List[E], callback func(value E) bool) (*List[E], *List[E]) {
+	matched, others := NewList[E](), NewList[E]()
+	list.Each(func(_ int, value E) bool {
+		if callback(value) {
+			matched.Push(value)
+		} else {
+			others.Push(value)
+		}
+		return true
+	})
+	return matched, others
+}
+
+// Zip pairs the elements of the lists by index, the result is as long as the shorter list
+func Zip[A, B any](a support.List[A], b support.List[B]) *List[Pair[A, B]] {
+	values := make([]B, 0, b.Count())
+	b.Each(func(_ int, value B) bool {
+		values = append(values, value)
+		return true
+	})
+	result := NewList[Pair[A, B]]()
+	a.Each(func(index int, value A) bool {
+		if index >= len(values) {
+			return false
+		}
+		result.Push(Pair[A, B]{value, values[index]})
+		return true
+	})
+	return result
+}
+
+// Window returns the windows of the given size over the list, each one starting step elements
+// after the previous one. Only full windows are returned.
+func Window[E any](list support.List[E], size, step int) *List[*List[E]] {
+	if size <= 0 {
+		panic(fmt.Sprintf("lists: invalid window size %d", size))
+	}
+	if step <= 0 {
+		panic(fmt.Sprintf("lists: invalid window step %d", step))
+	}
+	values := make([]E, 0, list.Count())
+	list.Each(func(_ int, value E) bool {
+		values = append(values, value)
+		return true
+	})
+	windows := NewList[*List[E]]()
+	for from := 0; from+size <= len(values); from += step {
+		windows.Push(NewList(values[from : from+size]...))
+	}
+	return windows
+}
+
+// Distinct returns the elements of the list without the duplicates, keeping the first occurrences
+func Distinct[E comparable](list support.List[E]) *List[E] {
+	seen := make(map[E]struct{})
+	result := NewList[E]()
+	list.Each(func(_ int, value E) bool {
+		if _, ok := seen[value]; !ok {
+			seen[value] = struct{}{}
+			result.Push(value)
+		}
+		return true
+	})
+	return result
+}
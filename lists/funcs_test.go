@@ -0,0 +1,110 @@
+package lists
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChunk(t *testing.T) {
+	t.Run("List", func(t *testing.T) {
+		chunks := Chunk[int](NewList(1, 2, 3, 4, 5), 2)
+		assert.Equal(t, int64(3), chunks.Count())
+		assert.Equal(t, []int{1, 2}, chunks.Get(0).ToArray())
+		assert.Equal(t, []int{3, 4}, chunks.Get(1).ToArray())
+		assert.Equal(t, []int{5}, chunks.Get(2).ToArray())
+	})
+
+	t.Run("LinkedList", func(t *testing.T) {
+		chunks := Chunk[int](NewLinkedList(1, 2, 3, 4), 2)
+		assert.Equal(t, int64(2), chunks.Count())
+		assert.Equal(t, []int{3, 4}, chunks.Get(1).ToArray())
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		assert.True(t, Chunk[int](NewList[int](), 2).IsEmpty())
+	})
+
+	t.Run("InvalidSize", func(t *testing.T) {
+		assert.Panics(t, func() {
+			Chunk[int](NewList(1), 0)
+		})
+	})
+}
+
+func TestMap(t *testing.T) {
+	result := Map[int](NewLinkedList(1, 2, 3), strconv.Itoa)
+	assert.Equal(t, []string{"1", "2", "3"}, result.ToArray())
+}
+
+func TestFlatMap(t *testing.T) {
+	result := FlatMap[int](NewList(1, 2, 3), func(value int) []int {
+		return []int{value, value * 10}
+	})
+	assert.Equal(t, []int{1, 10, 2, 20, 3, 30}, result.ToArray())
+}
+
+func TestReduce(t *testing.T) {
+	sum := Reduce[int](NewList(1, 2, 3, 4), 0, func(carry int, value int) int {
+		return carry + value
+	})
+	assert.Equal(t, 10, sum)
+	joined := Reduce[int](NewLinkedList(1, 2, 3), "", func(carry string, value int) string {
+		return carry + strconv.Itoa(value)
+	})
+	assert.Equal(t, "123", joined)
+}
+
+func TestGroupBy(t *testing.T) {
+	groups := GroupBy[int](NewList(1, 2, 3, 4, 5), func(value int) bool {
+		return value%2 == 0
+	})
+	assert.Len(t, groups, 2)
+	assert.Equal(t, []int{2, 4}, groups[true].ToArray())
+	assert.Equal(t, []int{1, 3, 5}, groups[false].ToArray())
+}
+
+func TestPartition(t *testing.T) {
+	matched, others := Partition[int](NewLinkedList(1, 2, 3, 4, 5), func(value int) bool {
+		return value > 3
+	})
+	assert.Equal(t, []int{4, 5}, matched.ToArray())
+	assert.Equal(t, []int{1, 2, 3}, others.ToArray())
+}
+
+func TestZip(t *testing.T) {
+	result := Zip[int, string](NewList(1, 2, 3), NewLinkedList("a", "b"))
+	assert.Equal(t, []Pair[int, string]{{1, "a"}, {2, "b"}}, result.ToArray())
+}
+
+func TestWindow(t *testing.T) {
+	t.Run("Sliding", func(t *testing.T) {
+		windows := Window[int](NewList(1, 2, 3, 4, 5), 3, 1)
+		assert.Equal(t, int64(3), windows.Count())
+		assert.Equal(t, []int{1, 2, 3}, windows.Get(0).ToArray())
+		assert.Equal(t, []int{3, 4, 5}, windows.Get(2).ToArray())
+	})
+
+	t.Run("Step", func(t *testing.T) {
+		windows := Window[int](NewLinkedList(1, 2, 3, 4, 5, 6), 2, 3)
+		assert.Equal(t, int64(2), windows.Count())
+		assert.Equal(t, []int{1, 2}, windows.Get(0).ToArray())
+		assert.Equal(t, []int{4, 5}, windows.Get(1).ToArray())
+	})
+
+	t.Run("TooShort", func(t *testing.T) {
+		assert.True(t, Window[int](NewList(1, 2), 3, 1).IsEmpty())
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		assert.Panics(t, func() {
+			Window[int](NewList(1), 1, 0)
+		})
+	})
+}
+
+func TestDistinct(t *testing.T) {
+	result := Distinct[int](NewList(3, 1, 3, 2, 1))
+	assert.Equal(t, []int{3, 1, 2}, result.ToArray())
+}
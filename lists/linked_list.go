@@ -1,7 +1,6 @@
 package lists
 
 import (
-	listlib "container/list"
 	"encoding/json"
 	"fmt"
 	"reflect"
@@ -10,11 +9,34 @@ import (
 	"sync"
 
 	"github.com/gopi-frame/contract/support"
-	"github.com/gopi-frame/exception"
 )
 
 var _ support.List[any] = (*LinkedList[any])(nil)
 
+// Element node of a linked list
+type Element[E any] struct {
+	Value E
+	next  *Element[E]
+	prev  *Element[E]
+	list  *LinkedList[E]
+}
+
+// Next returns the next element or nil
+func (e *Element[E]) Next() *Element[E] {
+	if next := e.next; e.list != nil && next != &e.list.root {
+		return next
+	}
+	return nil
+}
+
+// Prev returns the previous element or nil
+func (e *Element[E]) Prev() *Element[E] {
+	if prev := e.prev; e.list != nil && prev != &e.list.root {
+		return prev
+	}
+	return nil
+}
+
 // NewLinkedList new linked list
 func NewLinkedList[E any](values ...E) *LinkedList[E] {
 	instance := new(LinkedList[E])
@@ -23,43 +45,188 @@ func NewLinkedList[E any](values ...E) *LinkedList[E] {
 }
 
 // LinkedList linked list
+//
+// It is a doubly linked ring of elements around a sentinel root, the zero value
+// is an empty list. The element operations are O(1) and only valid with elements
+// of the list, they are ignored otherwise.
 type LinkedList[E any] struct {
 	sync.RWMutex
-	list *listlib.List
+	root Element[E]
+	len  int
 }
 
 func (list *LinkedList[E]) init() {
-	if list.list == nil {
-		list.list = listlib.New()
+	if list.root.next == nil {
+		list.root.next = &list.root
+		list.root.prev = &list.root
+		list.len = 0
 	}
 }
 
-func (list *LinkedList[E]) Count() int64 {
+// insert links e after at
+func (list *LinkedList[E]) insert(e, at *Element[E]) *Element[E] {
+	e.prev = at
+	e.next = at.next
+	e.prev.next = e
+	e.next.prev = e
+	e.list = list
+	list.len++
+	return e
+}
+
+// unlink unlinks e from the ring without releasing it
+func (list *LinkedList[E]) unlink(e *Element[E]) {
+	e.prev.next = e.next
+	e.next.prev = e.prev
+	list.len--
+}
+
+// remove unlinks e and releases it
+func (list *LinkedList[E]) remove(e *Element[E]) E {
+	list.unlink(e)
+	e.next = nil
+	e.prev = nil
+	e.list = nil
+	return e.Value
+}
+
+// move moves e after at
+func (list *LinkedList[E]) move(e, at *Element[E]) {
+	if e == at || e.prev == at {
+		return
+	}
+	e.prev.next = e.next
+	e.next.prev = e.prev
+	e.prev = at
+	e.next = at.next
+	e.prev.next = e
+	e.next.prev = e
+}
+
+// Front returns the first element or nil
+func (list *LinkedList[E]) Front() *Element[E] {
+	if list.len == 0 {
+		return nil
+	}
+	return list.root.next
+}
+
+// Back returns the last element or nil
+func (list *LinkedList[E]) Back() *Element[E] {
+	if list.len == 0 {
+		return nil
+	}
+	return list.root.prev
+}
+
+// PushFront inserts the value at the front and returns its element
+func (list *LinkedList[E]) PushFront(value E) *Element[E] {
 	list.init()
-	return int64(list.list.Len())
+	return list.insert(&Element[E]{Value: value}, &list.root)
 }
 
-func (list *LinkedList[E]) IsEmpty() bool {
+// PushBack inserts the value at the back and returns its element
+func (list *LinkedList[E]) PushBack(value E) *Element[E] {
+	list.init()
+	return list.insert(&Element[E]{Value: value}, list.root.prev)
+}
+
+// InsertBefore inserts the value before the mark and returns its element,
+// it returns nil when the mark is not an element of the list
+func (list *LinkedList[E]) InsertBefore(value E, mark *Element[E]) *Element[E] {
+	if mark.list != list {
+		return nil
+	}
+	return list.insert(&Element[E]{Value: value}, mark.prev)
+}
+
+// InsertAfter inserts the value after the mark and returns its element,
+// it returns nil when the mark is not an element of the list
+func (list *LinkedList[E]) InsertAfter(value E, mark *Element[E]) *Element[E] {
+	if mark.list != list {
+		return nil
+	}
+	return list.insert(&Element[E]{Value: value}, mark)
+}
+
+// RemoveElement removes the element and returns its value
+func (list *LinkedList[E]) RemoveElement(e *Element[E]) E {
+	if e.list == list {
+		list.remove(e)
+	}
+	return e.Value
+}
+
+// MoveToFront moves the element to the front
+func (list *LinkedList[E]) MoveToFront(e *Element[E]) {
+	if e.list == list {
+		list.move(e, &list.root)
+	}
+}
+
+// MoveToBack moves the element to the back
+func (list *LinkedList[E]) MoveToBack(e *Element[E]) {
+	if e.list == list {
+		list.move(e, list.root.prev)
+	}
+}
+
+// MoveBefore moves the element before the mark
+func (list *LinkedList[E]) MoveBefore(e, mark *Element[E]) {
+	if e.list == list && mark.list == list && e != mark {
+		list.move(e, mark.prev)
+	}
+}
+
+// MoveAfter moves the element after the mark
+func (list *LinkedList[E]) MoveAfter(e, mark *Element[E]) {
+	if e.list == list && mark.list == list && e != mark {
+		list.move(e, mark)
+	}
+}
+
+// Splice moves the elements of the other list to the back of the list, the other list is left empty.
+// The elements keep their identity, only their owner is updated, which is O(len(other)).
+func (list *LinkedList[E]) Splice(other *LinkedList[E]) {
 	list.init()
+	if other == list || other.len == 0 {
+		return
+	}
+	for e := other.root.next; e != &other.root; e = e.next {
+		e.list = list
+	}
+	first, last := other.root.next, other.root.prev
+	first.prev = list.root.prev
+	last.next = &list.root
+	list.root.prev.next = first
+	list.root.prev = last
+	list.len += other.len
+	other.root.next = &other.root
+	other.root.prev = &other.root
+	other.len = 0
+}
+
+func (list *LinkedList[E]) Count() int64 {
+	return int64(list.len)
+}
+
+func (list *LinkedList[E]) IsEmpty() bool {
 	return list.Count() == 0
 }
 
 func (list *LinkedList[E]) IsNotEmpty() bool {
-	list.init()
 	return !list.IsEmpty()
 }
 
 func (list *LinkedList[E]) Contains(value E) bool {
-	list.init()
 	return list.ContainsWhere(func(item E) bool {
 		return reflect.DeepEqual(item, value)
 	})
 }
 
 func (list *LinkedList[E]) ContainsWhere(callback func(value E) bool) bool {
-	list.init()
-	for e := list.list.Front(); e != nil; e = e.Next() {
-		if callback(e.Value.(E)) {
+	for e := list.Front(); e != nil; e = e.Next() {
+		if callback(e.Value) {
 			return true
 		}
 	}
@@ -67,9 +234,8 @@ func (list *LinkedList[E]) ContainsWhere(callback func(value E) bool) bool {
 }
 
 func (list *LinkedList[E]) Push(values ...E) {
-	list.init()
 	for _, value := range values {
-		list.list.PushBack(value)
+		list.PushBack(value)
 	}
 }
 
@@ -80,119 +246,180 @@ func (list *LinkedList[E]) Remove(value E) {
 }
 
 func (list *LinkedList[E]) RemoveWhere(callback func(item E) bool) {
-	list.init()
-	var next *listlib.Element
-	for e := list.list.Front(); e != nil; e = next {
+	var next *Element[E]
+	for e := list.Front(); e != nil; e = next {
 		next = e.Next()
-		if callback(e.Value.(E)) {
-			list.list.Remove(e)
+		if callback(e.Value) {
+			list.remove(e)
 		}
 	}
 }
 
+// RemoveAt removes the element at the index, negative indexes count from the end.
+// It panics with a *RangeError when the index is out of range.
 func (list *LinkedList[E]) RemoveAt(index int) {
-	list.init()
-	var next *listlib.Element
-	for e, i := list.list.Front(), 0; e != nil; e, i = next, i+1 {
-		next = e.Next()
-		if i == index {
-			list.list.Remove(e)
-			break
+	if _, err := list.TryRemoveAt(index); err != nil {
+		panic(err)
+	}
+}
+
+// TryRemoveAt removes and returns the element at the index, negative indexes count from the end
+func (list *LinkedList[E]) TryRemoveAt(index int) (E, error) {
+	e, err := list.element(index)
+	if err != nil {
+		return *new(E), err
+	}
+	list.remove(e)
+	return e.Value, nil
+}
+
+// element returns the element at the index, negative indexes count from the end
+func (list *LinkedList[E]) element(index int) (*Element[E], error) {
+	i, ok := resolveIndex(index, list.len)
+	if !ok {
+		return nil, &RangeError{index, list.len}
+	}
+	return list.at(i), nil
+}
+
+// at returns the element at the index, which must be in range, it walks from the nearest end
+func (list *LinkedList[E]) at(index int) *Element[E] {
+	if index < list.len/2 {
+		e := list.root.next
+		for i := 0; i < index; i++ {
+			e = e.next
 		}
+		return e
 	}
+	e := list.root.prev
+	for i := list.len - 1; i > index; i-- {
+		e = e.prev
+	}
+	return e
 }
 
+// Clear removes all the elements, they are released so that the element operations ignore them afterwards
 func (list *LinkedList[E]) Clear() {
+	for e := list.root.next; e != nil && e != &list.root; {
+		next := e.next
+		e.next = nil
+		e.prev = nil
+		e.list = nil
+		e = next
+	}
+	list.root.next = nil
 	list.init()
-	list.list.Init()
 }
 
+// Get returns the element at the index, negative indexes count from the end.
+// It panics with a *RangeError when the index is out of range.
 func (list *LinkedList[E]) Get(index int) E {
-	list.init()
-	if index < 0 || index >= list.list.Len() {
-		panic(exception.NewRangeException(0, list.list.Len()-1))
+	value, err := list.TryGet(index)
+	if err != nil {
+		panic(err)
 	}
-	for i, e := 0, list.list.Front(); e != nil; i, e = i+1, e.Next() {
-		if i == index {
-			return e.Value.(E)
-		}
+	return value
+}
+
+// TryGet returns the element at the index, negative indexes count from the end
+func (list *LinkedList[E]) TryGet(index int) (E, error) {
+	e, err := list.element(index)
+	if err != nil {
+		return *new(E), err
 	}
-	return *new(E)
+	return e.Value, nil
 }
 
+// Set replaces the element at the index, negative indexes count from the end.
+// It panics with a *RangeError when the index is out of range.
 func (list *LinkedList[E]) Set(index int, value E) {
-	list.init()
-	for i, e := 0, list.list.Front(); e != nil; i, e = i+1, e.Next() {
-		if i == index {
-			e.Value = value
-		}
+	if _, err := list.TrySet(index, value); err != nil {
+		panic(err)
+	}
+}
+
+// TrySet replaces the element at the index and returns the previous one, negative indexes count from the end
+func (list *LinkedList[E]) TrySet(index int, value E) (E, error) {
+	e, err := list.element(index)
+	if err != nil {
+		return *new(E), err
+	}
+	previous := e.Value
+	e.Value = value
+	return previous, nil
+}
+
+// Insert inserts the values before the element at the index, negative indexes count from the end
+// and inserting at the count appends the values
+func (list *LinkedList[E]) Insert(index int, values ...E) error {
+	if index == list.len {
+		list.Push(values...)
+		return nil
+	}
+	mark, err := list.element(index)
+	if err != nil {
+		return err
 	}
+	for _, value := range values {
+		list.InsertBefore(value, mark)
+	}
+	return nil
 }
 
 func (list *LinkedList[E]) First() (E, bool) {
-	list.init()
-	if list.list.Len() == 0 {
+	if list.len == 0 {
 		return *new(E), false
 	}
-	return list.list.Front().Value.(E), true
+	return list.root.next.Value, true
 }
 
 func (list *LinkedList[E]) FirstOr(value E) E {
-	list.init()
-	if list.list.Len() == 0 {
-		return value
+	if v, ok := list.First(); ok {
+		return v
 	}
-	return list.list.Front().Value.(E)
+	return value
 }
 
 func (list *LinkedList[E]) FirstWhere(callback func(item E) bool) (E, bool) {
-	list.init()
-	for e := list.list.Front(); e != nil; e = e.Next() {
-		if callback(e.Value.(E)) {
-			return e.Value.(E), true
+	for e := list.Front(); e != nil; e = e.Next() {
+		if callback(e.Value) {
+			return e.Value, true
 		}
 	}
 	return *new(E), false
 }
 
 func (list *LinkedList[E]) FirstWhereOr(callback func(item E) bool, value E) E {
-	list.init()
-	for e := list.list.Front(); e != nil; e = e.Next() {
-		if callback(e.Value.(E)) {
-			return e.Value.(E)
-		}
+	if v, ok := list.FirstWhere(callback); ok {
+		return v
 	}
 	return value
 }
 
 func (list *LinkedList[E]) Last() (E, bool) {
-	list.init()
-	if list.list.Len() == 0 {
+	if list.len == 0 {
 		return *new(E), false
 	}
-	return list.list.Back().Value.(E), true
+	return list.root.prev.Value, true
 }
 
 func (list *LinkedList[E]) LastOr(value E) E {
-	list.init()
-	if list.list.Back() == nil {
-		return value
+	if v, ok := list.Last(); ok {
+		return v
 	}
-	return list.list.Back().Value.(E)
+	return value
 }
 
 func (list *LinkedList[E]) LastWhere(callback func(item E) bool) (E, bool) {
-	list.init()
-	for e := list.list.Back(); e != nil; e = e.Prev() {
-		if callback(e.Value.(E)) {
-			return e.Value.(E), true
+	for e := list.Back(); e != nil; e = e.Prev() {
+		if callback(e.Value) {
+			return e.Value, true
 		}
 	}
 	return *new(E), false
 }
 
 func (list *LinkedList[E]) LastWhereOr(callback func(item E) bool, value E) E {
-	list.init()
 	if v, ok := list.LastWhere(callback); ok {
 		return v
 	}
@@ -200,43 +427,34 @@ func (list *LinkedList[E]) LastWhereOr(callback func(item E) bool, value E) E {
 }
 
 func (list *LinkedList[E]) Pop() (E, bool) {
-	list.init()
-	if list.list.Len() == 0 {
+	if list.len == 0 {
 		return *new(E), false
 	}
-	item := list.list.Back()
-	list.list.Remove(item)
-	return item.Value.(E), true
+	return list.remove(list.root.prev), true
 }
 
 func (list *LinkedList[E]) Shift() (E, bool) {
-	list.init()
-	if list.list.Len() == 0 {
+	if list.len == 0 {
 		return *new(E), false
 	}
-	item := list.list.Front()
-	list.list.Remove(item)
-	return item.Value.(E), true
+	return list.remove(list.root.next), true
 }
 
 func (list *LinkedList[E]) Unshift(values ...E) {
-	list.init()
 	for _, value := range values {
-		list.list.PushFront(value)
+		list.PushFront(value)
 	}
 }
 
 func (list *LinkedList[E]) IndexOf(value E) int {
-	list.init()
 	return list.IndexOfWhere(func(item E) bool {
 		return reflect.DeepEqual(item, value)
 	})
 }
 
 func (list *LinkedList[E]) IndexOfWhere(callback func(item E) bool) int {
-	list.init()
-	for i, e := 0, list.list.Front(); e != nil; i, e = i+1, e.Next() {
-		if callback(e.Value.(E)) {
+	for i, e := 0, list.Front(); e != nil; i, e = i+1, e.Next() {
+		if callback(e.Value) {
 			return i
 		}
 	}
@@ -244,34 +462,27 @@ func (list *LinkedList[E]) IndexOfWhere(callback func(item E) bool) int {
 }
 
 func (list *LinkedList[E]) Sub(from, to int) *LinkedList[E] {
-	list.init()
 	linked := NewLinkedList[E]()
-	for i, e := 0, list.list.Front(); e != nil; i, e = i+1, e.Next() {
-		if i < from {
-			continue
-		} else if i >= from && i < to {
-			linked.Push(e.Value.(E))
-		} else {
-			break
+	for i, e := 0, list.Front(); e != nil && i < to; i, e = i+1, e.Next() {
+		if i >= from {
+			linked.Push(e.Value)
 		}
 	}
 	return linked
 }
 
 func (list *LinkedList[E]) Where(callback func(item E) bool) *LinkedList[E] {
-	list.init()
 	linked := &LinkedList[E]{}
-	for e := list.list.Front(); e != nil; e = e.Next() {
-		if callback(e.Value.(E)) {
-			linked.Push(e.Value.(E))
+	for e := list.Front(); e != nil; e = e.Next() {
+		if callback(e.Value) {
+			linked.Push(e.Value)
 		}
 	}
 	return linked
 }
 
 func (list *LinkedList[E]) Compact(callback func(a, b E) bool) {
-	list.init()
-	if list.list.Len() < 2 {
+	if list.len < 2 {
 		return
 	}
 	if callback == nil {
@@ -279,52 +490,88 @@ func (list *LinkedList[E]) Compact(callback func(a, b E) bool) {
 			return reflect.DeepEqual(a, b)
 		}
 	}
-	var next *listlib.Element
-	for e := list.list.Front().Next(); e != nil; e = next {
+	var next *Element[E]
+	for e := list.Front().Next(); e != nil; e = next {
 		next = e.Next()
-		if callback(e.Value.(E), e.Prev().Value.(E)) {
-			list.list.Remove(e)
+		if callback(e.Value, e.prev.Value) {
+			list.remove(e)
 		}
 	}
 }
 
 func (list *LinkedList[E]) Min(callback func(a, b E) int) E {
-	list.init()
 	return slices.MinFunc(list.ToArray(), callback)
 }
 
 func (list *LinkedList[E]) Max(callback func(a, b E) int) E {
-	list.init()
 	return slices.MaxFunc(list.ToArray(), callback)
 }
 
+// Sort sorts the list in place with a stable bottom-up merge sort in O(n log n),
+// the elements are relinked and keep their identity, nothing is allocated
 func (list *LinkedList[E]) Sort(callback func(a, b E) int) {
-	list.init()
-	var newList = listlib.New()
-	for e := list.list.Front(); e != nil; e = e.Next() {
-		node := newList.Front()
-		for node != nil {
-			if callback(e.Value.(E), node.Value.(E)) < 0 {
-				newList.InsertBefore(e.Value, node)
-				break
+	if list.len < 2 {
+		return
+	}
+	// sort the elements as a nil-terminated singly linked list,
+	// merging the runs of width 1, 2, 4... until a single run is left
+	head := list.root.next
+	list.root.prev.next = nil
+	for width := 1; ; width *= 2 {
+		var first, last *Element[E]
+		merges := 0
+		for left := head; left != nil; {
+			merges++
+			right, leftSize := left, 0
+			for leftSize < width && right != nil {
+				right = right.next
+				leftSize++
+			}
+			rightSize := width
+			for leftSize > 0 || (rightSize > 0 && right != nil) {
+				var e *Element[E]
+				// taking from the left run on ties keeps the sort stable
+				if rightSize == 0 || right == nil || (leftSize > 0 && callback(left.Value, right.Value) <= 0) {
+					e, left = left, left.next
+					leftSize--
+				} else {
+					e, right = right, right.next
+					rightSize--
+				}
+				if last == nil {
+					first = e
+				} else {
+					last.next = e
+				}
+				last = e
 			}
-			node = node.Next()
+			left = right
 		}
-		newList.PushBack(e.Value)
+		last.next = nil
+		head = first
+		if merges <= 1 {
+			break
+		}
+	}
+	prev := &list.root
+	for e := head; e != nil; e = e.next {
+		e.prev = prev
+		prev.next = e
+		prev = e
 	}
-	list.list = newList
+	prev.next = &list.root
+	list.root.prev = prev
 }
 
 func (list *LinkedList[E]) Chunk(size int) *LinkedList[*LinkedList[any]] {
-	list.init()
 	chunks := NewLinkedList[*LinkedList[any]]()
 	chunk := NewLinkedList[any]()
-	for e := list.list.Front(); e != nil; e = e.Next() {
-		if chunk.list.Len() < size {
-			chunk.Push(e.Value.(E))
+	for e := list.Front(); e != nil; e = e.Next() {
+		if chunk.len < size {
+			chunk.Push(e.Value)
 		} else {
 			chunks.Push(chunk)
-			chunk = NewLinkedList(e.Value)
+			chunk = NewLinkedList[any](e.Value)
 		}
 	}
 	chunks.Push(chunk)
@@ -332,35 +579,36 @@ func (list *LinkedList[E]) Chunk(size int) *LinkedList[*LinkedList[any]] {
 }
 
 func (list *LinkedList[E]) Each(callback func(index int, value E) bool) {
-	list.init()
-	for e, i := list.list.Front(), 0; e != nil; e, i = e.Next(), i+1 {
-		if !callback(i, e.Value.(E)) {
+	for e, i := list.Front(), 0; e != nil; e, i = e.Next(), i+1 {
+		if !callback(i, e.Value) {
 			break
 		}
 	}
 }
 
 func (list *LinkedList[E]) Reverse() {
-	list.init()
-	var next *listlib.Element
-	for e := list.list.Front(); e != nil; e = next {
-		next = e.Next()
-		list.list.PushFront(e.Value)
-		list.list.Remove(e)
+	if list.len < 2 {
+		return
+	}
+	e := &list.root
+	for {
+		e.next, e.prev = e.prev, e.next
+		e = e.prev
+		if e == &list.root {
+			break
+		}
 	}
 }
 
 func (list *LinkedList[E]) Clone() *LinkedList[E] {
-	list.init()
 	linked := &LinkedList[E]{}
-	for e := list.list.Front(); e != nil; e = e.Next() {
-		linked.Push(e.Value.(E))
+	for e := list.Front(); e != nil; e = e.Next() {
+		linked.Push(e.Value)
 	}
 	return linked
 }
 
 func (list *LinkedList[E]) String() string {
-	list.init()
 	str := new(strings.Builder)
 	str.WriteString(fmt.Sprintf("LinkedList[%T](len=%d)", *new(E), list.Count()))
 	str.WriteByte('{')
@@ -376,7 +624,7 @@ func (list *LinkedList[E]) String() string {
 		str.WriteByte('\n')
 		return index < 4
 	})
-	if list.list.Len() > 5 {
+	if list.len > 5 {
 		str.WriteString("\t...\n")
 	}
 	str.WriteByte('}')
@@ -384,33 +632,27 @@ func (list *LinkedList[E]) String() string {
 }
 
 func (list *LinkedList[E]) ToJSON() ([]byte, error) {
-	list.init()
 	return json.Marshal(list.ToArray())
 }
 
 func (list *LinkedList[E]) ToArray() []E {
-	list.init()
 	var items []E
-	for e := list.list.Front(); e != nil; e = e.Next() {
-		items = append(items, e.Value.(E))
+	for e := list.Front(); e != nil; e = e.Next() {
+		items = append(items, e.Value)
 	}
 	return items
 }
 
 func (list *LinkedList[E]) MarshalJSON() ([]byte, error) {
-	list.init()
 	return list.ToJSON()
 }
 
 func (list *LinkedList[E]) UnmarshalJSON(data []byte) error {
-	list.init()
 	items := []E{}
 	err := json.Unmarshal(data, &items)
 	if err != nil {
 		return err
 	}
-	for _, item := range items {
-		list.list.PushBack(item)
-	}
+	list.Push(items...)
 	return nil
 }
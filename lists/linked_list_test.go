@@ -3,7 +3,9 @@ package lists
 import (
 	"encoding/json"
 	"fmt"
+	"math/rand"
 	"regexp"
+	"slices"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -37,6 +39,22 @@ func TestLinkedList_Clear(t *testing.T) {
 	assert.True(t, list.IsEmpty())
 }
 
+func TestLinkedList_ClearStaleElement(t *testing.T) {
+	list := NewLinkedList[int]()
+	stale := list.PushBack(1)
+	list.Clear()
+	assert.Nil(t, stale.Next())
+	assert.Nil(t, stale.Prev())
+	list.PushBack(2)
+	// the elements removed by Clear are not elements of the list anymore
+	list.RemoveElement(stale)
+	list.MoveToFront(stale)
+	assert.Nil(t, list.InsertBefore(3, stale))
+	assert.Equal(t, int64(1), list.Count())
+	assert.Equal(t, []int{2}, list.ToArray())
+	assert.Equal(t, 2, list.Front().Value)
+}
+
 func TestLinkedList_Get(t *testing.T) {
 	list := NewLinkedList(1, 2, 3)
 	assert.Equal(t, 2, list.Get(1))
@@ -251,3 +269,198 @@ func TestLinkedList_UnmarshalJSON(t *testing.T) {
 	assert.Equal(t, []int{1, 2, 3}, list.ToArray())
 	assert.Nil(t, err)
 }
+
+func TestLinkedList_Elements(t *testing.T) {
+	list := new(LinkedList[int])
+	assert.Nil(t, list.Front())
+	assert.Nil(t, list.Back())
+	two := list.PushBack(2)
+	one := list.PushFront(1)
+	four := list.InsertAfter(4, two)
+	three := list.InsertBefore(3, four)
+	assert.Equal(t, []int{1, 2, 3, 4}, list.ToArray())
+	assert.Equal(t, one, list.Front())
+	assert.Equal(t, four, list.Back())
+	assert.Equal(t, two, one.Next())
+	assert.Equal(t, three, four.Prev())
+	assert.Nil(t, one.Prev())
+	assert.Nil(t, four.Next())
+
+	values := []int{}
+	for e := list.Back(); e != nil; e = e.Prev() {
+		values = append(values, e.Value)
+	}
+	assert.Equal(t, []int{4, 3, 2, 1}, values)
+
+	list.MoveToFront(three)
+	assert.Equal(t, []int{3, 1, 2, 4}, list.ToArray())
+	list.MoveToBack(three)
+	assert.Equal(t, []int{1, 2, 4, 3}, list.ToArray())
+	list.MoveBefore(three, four)
+	assert.Equal(t, []int{1, 2, 3, 4}, list.ToArray())
+	list.MoveAfter(one, four)
+	assert.Equal(t, []int{2, 3, 4, 1}, list.ToArray())
+
+	assert.Equal(t, 3, list.RemoveElement(three))
+	assert.Nil(t, three.Next())
+	assert.Equal(t, []int{2, 4, 1}, list.ToArray())
+	// the elements of another list are ignored
+	other := NewLinkedList(9)
+	assert.Nil(t, list.InsertAfter(5, other.Front()))
+	list.MoveToFront(other.Front())
+	list.RemoveElement(other.Front())
+	assert.Equal(t, int64(3), list.Count())
+	assert.Equal(t, int64(1), other.Count())
+}
+
+func TestLinkedList_Splice(t *testing.T) {
+	list := NewLinkedList(1, 2)
+	other := NewLinkedList(3, 4)
+	three := other.Front()
+	list.Splice(other)
+	assert.Equal(t, []int{1, 2, 3, 4}, list.ToArray())
+	assert.True(t, other.IsEmpty())
+	assert.Nil(t, other.Front())
+	assert.Equal(t, int64(4), list.Count())
+	// the moved elements now belong to the list
+	list.MoveToFront(three)
+	assert.Equal(t, []int{3, 1, 2, 4}, list.ToArray())
+	other.Push(5)
+	assert.Equal(t, []int{5}, other.ToArray())
+	list.Splice(new(LinkedList[int]))
+	assert.Equal(t, int64(4), list.Count())
+}
+
+func TestLinkedList_ReverseElements(t *testing.T) {
+	list := NewLinkedList(1, 2, 3, 4)
+	list.Reverse()
+	assert.Equal(t, []int{4, 3, 2, 1}, list.ToArray())
+	assert.Equal(t, 4, list.Front().Value)
+	assert.Equal(t, 1, list.Back().Value)
+	list.Push(0)
+	assert.Equal(t, []int{4, 3, 2, 1, 0}, list.ToArray())
+}
+
+func TestLinkedList_SortStable(t *testing.T) {
+	type item struct {
+		key   int
+		index int
+	}
+	compare := func(a, b item) int {
+		return a.key - b.key
+	}
+	rand := rand.New(rand.NewSource(1))
+	for round := 0; round < 200; round++ {
+		values := make([]item, rand.Intn(100))
+		for i := range values {
+			values[i] = item{rand.Intn(10), i}
+		}
+		list := NewLinkedList(values...)
+		elements := map[item]*Element[item]{}
+		for e := list.Front(); e != nil; e = e.Next() {
+			elements[e.Value] = e
+		}
+		list.Sort(compare)
+		slices.SortStableFunc(values, compare)
+		assert.Equal(t, int64(len(values)), list.Count())
+		// the elements are relinked, not copied, and the links are consistent both ways
+		forward := []item{}
+		for e := list.Front(); e != nil; e = e.Next() {
+			assert.Same(t, elements[e.Value], e)
+			forward = append(forward, e.Value)
+		}
+		backward := []item{}
+		for e := list.Back(); e != nil; e = e.Prev() {
+			backward = append(backward, e.Value)
+		}
+		slices.Reverse(backward)
+		assert.Equal(t, values, forward)
+		assert.Equal(t, values, backward)
+	}
+}
+
+func TestLinkedList_SortAllocs(t *testing.T) {
+	list := NewLinkedList(rand.Perm(1000)...)
+	compare := func(a, b int) int {
+		return a - b
+	}
+	allocs := testing.AllocsPerRun(10, func() {
+		list.Reverse()
+		list.Sort(compare)
+	})
+	assert.Equal(t, float64(0), allocs)
+	assert.True(t, slices.IsSorted(list.ToArray()))
+}
+
+func BenchmarkLinkedList_Sort(b *testing.B) {
+	values := rand.Perm(10000)
+	compare := func(a, b int) int {
+		return a - b
+	}
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		list := NewLinkedList(values...)
+		b.StartTimer()
+		list.Sort(compare)
+	}
+}
+
+func TestLinkedList_NegativeIndex(t *testing.T) {
+	list := NewLinkedList(1, 2, 3)
+	assert.Equal(t, 3, list.Get(-1))
+	assert.Equal(t, 1, list.Get(-3))
+	list.Set(-1, 30)
+	list.RemoveAt(-3)
+	assert.Equal(t, []int{2, 30}, list.ToArray())
+}
+
+func TestLinkedList_TryGet(t *testing.T) {
+	list := NewLinkedList(1, 2, 3)
+	value, err := list.TryGet(-2)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, value)
+	_, err = list.TryGet(3)
+	var rangeErr *RangeError
+	assert.ErrorAs(t, err, &rangeErr)
+	assert.Equal(t, &RangeError{3, 3}, rangeErr)
+	assert.PanicsWithError(t, "lists: index -4 out of range [-3, 3)", func() {
+		list.Get(-4)
+	})
+	assert.Panics(t, func() {
+		list.Set(3, 3)
+	})
+}
+
+func TestLinkedList_TrySet(t *testing.T) {
+	list := NewLinkedList(1, 2, 3)
+	previous, err := list.TrySet(0, 10)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, previous)
+	_, err = list.TrySet(3, 30)
+	assert.IsType(t, &RangeError{}, err)
+	assert.Equal(t, []int{10, 2, 3}, list.ToArray())
+}
+
+func TestLinkedList_TryRemoveAt(t *testing.T) {
+	list := NewLinkedList(1, 2, 3)
+	value, err := list.TryRemoveAt(-1)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, value)
+	_, err = list.TryRemoveAt(2)
+	assert.IsType(t, &RangeError{}, err)
+	assert.Equal(t, []int{1, 2}, list.ToArray())
+}
+
+func TestLinkedList_Insert(t *testing.T) {
+	list := NewLinkedList(1, 4)
+	assert.Nil(t, list.Insert(1, 2, 3))
+	assert.Nil(t, list.Insert(4, 6))
+	assert.Nil(t, list.Insert(-1, 5))
+	assert.Nil(t, list.Insert(0, 0))
+	assert.Equal(t, []int{0, 1, 2, 3, 4, 5, 6}, list.ToArray())
+	assert.IsType(t, &RangeError{}, list.Insert(8, 8))
+	assert.IsType(t, &RangeError{}, list.Insert(-8, 8))
+	empty := NewLinkedList[int]()
+	assert.Nil(t, empty.Insert(0, 1))
+	assert.Equal(t, []int{1}, empty.ToArray())
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"reflect"
 	"slices"
+	"sort"
 	"strings"
 	"sync"
 
@@ -62,20 +63,76 @@ func (list *List[E]) RemoveWhere(callback func(item E) bool) {
 	list.items = slices.DeleteFunc(list.items, callback)
 }
 
+// RemoveAt removes the element at the index, negative indexes count from the end.
+// It panics with a *RangeError when the index is out of range.
 func (list *List[E]) RemoveAt(index int) {
-	list.items = slices.Delete(list.items, index, index+1)
+	if _, err := list.TryRemoveAt(index); err != nil {
+		panic(err)
+	}
+}
+
+// TryRemoveAt removes and returns the element at the index, negative indexes count from the end
+func (list *List[E]) TryRemoveAt(index int) (E, error) {
+	i, ok := resolveIndex(index, len(list.items))
+	if !ok {
+		return *new(E), &RangeError{index, len(list.items)}
+	}
+	value := list.items[i]
+	list.items = slices.Delete(list.items, i, i+1)
+	return value, nil
 }
 
 func (list *List[E]) Clear() {
 	list.items = []E{}
 }
 
+// Get returns the element at the index, negative indexes count from the end.
+// It panics with a *RangeError when the index is out of range.
 func (list *List[E]) Get(index int) E {
-	return list.items[index]
+	value, err := list.TryGet(index)
+	if err != nil {
+		panic(err)
+	}
+	return value
+}
+
+// TryGet returns the element at the index, negative indexes count from the end
+func (list *List[E]) TryGet(index int) (E, error) {
+	i, ok := resolveIndex(index, len(list.items))
+	if !ok {
+		return *new(E), &RangeError{index, len(list.items)}
+	}
+	return list.items[i], nil
 }
 
+// Set replaces the element at the index, negative indexes count from the end.
+// It panics with a *RangeError when the index is out of range.
 func (list *List[E]) Set(index int, value E) {
-	list.items[index] = value
+	if _, err := list.TrySet(index, value); err != nil {
+		panic(err)
+	}
+}
+
+// TrySet replaces the element at the index and returns the previous one, negative indexes count from the end
+func (list *List[E]) TrySet(index int, value E) (E, error) {
+	i, ok := resolveIndex(index, len(list.items))
+	if !ok {
+		return *new(E), &RangeError{index, len(list.items)}
+	}
+	previous := list.items[i]
+	list.items[i] = value
+	return previous, nil
+}
+
+// Insert inserts the values before the element at the index, negative indexes count from the end
+// and inserting at the count appends the values
+func (list *List[E]) Insert(index int, values ...E) error {
+	i, ok := resolveIndex(index, len(list.items))
+	if !ok && index != len(list.items) {
+		return &RangeError{index, len(list.items)}
+	}
+	list.items = slices.Insert(list.items, i, values...)
+	return nil
 }
 
 func (list *List[E]) First() (E, bool) {
@@ -173,8 +230,25 @@ func (list *List[E]) IndexOfWhere(callback func(item E) bool) int {
 	return slices.IndexFunc(list.items, callback)
 }
 
+// Sub returns a new list with a copy of the elements in [from, to)
 func (list *List[E]) Sub(from, to int) *List[E] {
-	return &List[E]{items: list.items[from:to]}
+	list.checkRange(from, to)
+	return &List[E]{items: slices.Clone(list.items[from:to])}
+}
+
+// View returns a list sharing the elements in [from, to) with the list, setting an element
+// of one is seen by the other. The view is capped at to, pushing to it never overwrites
+// the elements of the list after the window but copies the view instead.
+func (list *List[E]) View(from, to int) *List[E] {
+	list.checkRange(from, to)
+	return &List[E]{items: list.items[from:to:to]}
+}
+
+// checkRange panics with a *BoundsError when [from, to) is not a range of the list
+func (list *List[E]) checkRange(from, to int) {
+	if from < 0 || to < from || to > len(list.items) {
+		panic(&BoundsError{from, to, len(list.items)})
+	}
 }
 
 func (list *List[E]) Where(callback func(item E) bool) *List[E] {
@@ -208,6 +282,43 @@ func (list *List[E]) Sort(callback func(a, b E) int) {
 	slices.SortFunc(list.items, callback)
 }
 
+// SortStable sorts the list keeping the order of the equal elements
+func (list *List[E]) SortStable(callback func(a, b E) int) {
+	slices.SortStableFunc(list.items, callback)
+}
+
+// IsSorted reports whether the list is sorted
+func (list *List[E]) IsSorted(callback func(a, b E) int) bool {
+	return slices.IsSortedFunc(list.items, callback)
+}
+
+// BinarySearch searches the target in the sorted list, it returns the index of the first
+// element equal to the target, or the index where it would be inserted, and whether it was found
+func (list *List[E]) BinarySearch(target E, callback func(a, b E) int) (int, bool) {
+	return slices.BinarySearchFunc(list.items, target, callback)
+}
+
+// LowerBound returns the index of the first element of the sorted list which is not less than the target
+func (list *List[E]) LowerBound(target E, callback func(a, b E) int) int {
+	index, _ := list.BinarySearch(target, callback)
+	return index
+}
+
+// UpperBound returns the index of the first element of the sorted list which is greater than the target
+func (list *List[E]) UpperBound(target E, callback func(a, b E) int) int {
+	return sort.Search(len(list.items), func(i int) bool {
+		return callback(list.items[i], target) > 0
+	})
+}
+
+// InsertSorted inserts the value into the sorted list after the elements equal to it,
+// and returns its index
+func (list *List[E]) InsertSorted(value E, callback func(a, b E) int) int {
+	index := list.UpperBound(value, callback)
+	list.items = slices.Insert(list.items, index, value)
+	return index
+}
+
 func (list *List[E]) Chunk(size int) *List[*List[any]] {
 	chunks := NewList[*List[any]]()
 	chunk := NewList[any]()
@@ -235,9 +346,9 @@ func (list *List[E]) Reverse() {
 	slices.Reverse(list.items)
 }
 
+// Clone returns a new list with a copy of the elements
 func (list *List[E]) Clone() *List[E] {
-	list.items = slices.Clone(list.items)
-	return list
+	return &List[E]{items: slices.Clone(list.items)}
 }
 
 func (list *List[E]) String() string {
@@ -269,8 +380,9 @@ func (list *List[E]) ToJSON() ([]byte, error) {
 	return json.Marshal(list.items)
 }
 
+// ToArray returns a copy of the elements
 func (list *List[E]) ToArray() []E {
-	return list.items
+	return slices.Clone(list.items)
 }
 
 func (list *List[E]) MarshalJSON() ([]byte, error) {
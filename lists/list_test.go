@@ -1,9 +1,11 @@
 package lists
 
 import (
+	"cmp"
 	"encoding/json"
 	"fmt"
 	"regexp"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -147,6 +149,23 @@ func TestList_Sub(t *testing.T) {
 	list := NewList(1, 2, 3, 4, 5)
 	subList := list.Sub(1, 3)
 	assert.Equal(t, []int{2, 3}, subList.ToArray())
+	subList.Set(0, 20)
+	subList.Push(30)
+	assert.Equal(t, []int{1, 2, 3, 4, 5}, list.ToArray())
+}
+
+func TestList_View(t *testing.T) {
+	list := NewList(1, 2, 3, 4, 5)
+	view := list.View(1, 3)
+	assert.Equal(t, []int{2, 3}, view.ToArray())
+	view.Set(0, 20)
+	list.Set(2, 30)
+	assert.Equal(t, []int{20, 30}, view.ToArray())
+	assert.Equal(t, []int{1, 20, 30, 4, 5}, list.ToArray())
+	// pushing to the view does not overwrite the list after the window
+	view.Push(40)
+	assert.Equal(t, []int{20, 30, 40}, view.ToArray())
+	assert.Equal(t, []int{1, 20, 30, 4, 5}, list.ToArray())
 }
 
 func TestList_Where(t *testing.T) {
@@ -221,7 +240,20 @@ func TestList_Reverse(t *testing.T) {
 
 func TestList_Clone(t *testing.T) {
 	list := NewList(1, 2, 3)
-	assert.Equal(t, []int{1, 2, 3}, list.Clone().ToArray())
+	clone := list.Clone()
+	assert.NotSame(t, list, clone)
+	assert.Equal(t, []int{1, 2, 3}, clone.ToArray())
+	clone.Set(0, 10)
+	list.Push(4)
+	assert.Equal(t, []int{1, 2, 3, 4}, list.ToArray())
+	assert.Equal(t, []int{10, 2, 3}, clone.ToArray())
+}
+
+func TestList_ToArray(t *testing.T) {
+	list := NewList(1, 2, 3)
+	values := list.ToArray()
+	values[0] = 10
+	assert.Equal(t, []int{1, 2, 3}, list.ToArray())
 }
 
 func TestList_String(t *testing.T) {
@@ -251,3 +283,108 @@ func TestList_UnmarshalJSON(t *testing.T) {
 	assert.Equal(t, []int{1, 2, 3}, list.ToArray())
 	assert.Nil(t, err)
 }
+
+func TestList_BinarySearch(t *testing.T) {
+	list := NewList(1, 3, 3, 3, 5)
+	index, ok := list.BinarySearch(3, cmp.Compare[int])
+	assert.True(t, ok)
+	assert.Equal(t, 1, index)
+	index, ok = list.BinarySearch(4, cmp.Compare[int])
+	assert.False(t, ok)
+	assert.Equal(t, 4, index)
+	assert.Equal(t, 1, list.LowerBound(3, cmp.Compare[int]))
+	assert.Equal(t, 4, list.UpperBound(3, cmp.Compare[int]))
+	assert.Equal(t, 0, list.LowerBound(0, cmp.Compare[int]))
+	assert.Equal(t, 5, list.UpperBound(5, cmp.Compare[int]))
+}
+
+func TestList_InsertSorted(t *testing.T) {
+	type item struct {
+		key   int
+		value string
+	}
+	compare := func(a, b item) int {
+		return cmp.Compare(a.key, b.key)
+	}
+	list := NewList(item{1, "a"}, item{2, "b"}, item{3, "c"})
+	assert.Equal(t, 2, list.InsertSorted(item{2, "d"}, compare))
+	assert.Equal(t, 0, list.InsertSorted(item{0, "e"}, compare))
+	assert.Equal(t, 5, list.InsertSorted(item{4, "f"}, compare))
+	assert.Equal(t, []item{{0, "e"}, {1, "a"}, {2, "b"}, {2, "d"}, {3, "c"}, {4, "f"}}, list.ToArray())
+	assert.True(t, list.IsSorted(compare))
+}
+
+func TestList_SortStable(t *testing.T) {
+	list := NewList("bb", "a", "cc", "b", "aa")
+	list.SortStable(func(a, b string) int {
+		return len(a) - len(b)
+	})
+	assert.Equal(t, []string{"a", "b", "bb", "cc", "aa"}, list.ToArray())
+	assert.False(t, list.IsSorted(strings.Compare))
+}
+
+func TestList_NegativeIndex(t *testing.T) {
+	list := NewList(1, 2, 3)
+	assert.Equal(t, 3, list.Get(-1))
+	assert.Equal(t, 1, list.Get(-3))
+	list.Set(-1, 30)
+	list.RemoveAt(-3)
+	assert.Equal(t, []int{2, 30}, list.ToArray())
+}
+
+func TestList_TryGet(t *testing.T) {
+	list := NewList(1, 2, 3)
+	value, err := list.TryGet(1)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, value)
+	_, err = list.TryGet(3)
+	var rangeErr *RangeError
+	assert.ErrorAs(t, err, &rangeErr)
+	assert.Equal(t, &RangeError{3, 3}, rangeErr)
+	_, err = list.TryGet(-4)
+	assert.EqualError(t, err, "lists: index -4 out of range [-3, 3)")
+	assert.PanicsWithError(t, "lists: index 3 out of range [-3, 3)", func() {
+		list.Get(3)
+	})
+}
+
+func TestList_TrySet(t *testing.T) {
+	list := NewList(1, 2, 3)
+	previous, err := list.TrySet(-1, 30)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, previous)
+	_, err = list.TrySet(5, 50)
+	assert.IsType(t, &RangeError{}, err)
+	assert.Equal(t, []int{1, 2, 30}, list.ToArray())
+}
+
+func TestList_TryRemoveAt(t *testing.T) {
+	list := NewList(1, 2, 3)
+	value, err := list.TryRemoveAt(0)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, value)
+	_, err = list.TryRemoveAt(2)
+	assert.IsType(t, &RangeError{}, err)
+	assert.Equal(t, []int{2, 3}, list.ToArray())
+}
+
+func TestList_Insert(t *testing.T) {
+	list := NewList(1, 4)
+	assert.Nil(t, list.Insert(1, 2, 3))
+	assert.Nil(t, list.Insert(4, 6))
+	assert.Nil(t, list.Insert(-1, 5))
+	assert.Nil(t, list.Insert(0, 0))
+	assert.Equal(t, []int{0, 1, 2, 3, 4, 5, 6}, list.ToArray())
+	assert.IsType(t, &RangeError{}, list.Insert(8, 8))
+	assert.IsType(t, &RangeError{}, list.Insert(-8, 8))
+}
+
+func TestList_SubOutOfRange(t *testing.T) {
+	list := NewList(1, 2, 3)
+	assert.PanicsWithError(t, "lists: range [1, 4) out of bounds [0, 3]", func() {
+		list.Sub(1, 4)
+	})
+	assert.Panics(t, func() {
+		list.View(2, 1)
+	})
+}
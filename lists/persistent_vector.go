@@ -0,0 +1,433 @@
+package lists
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/gopi-frame/contract/support"
+)
+
+const (
+	vectorBits  = 5
+	vectorWidth = 1 << vectorBits
+	vectorMask  = vectorWidth - 1
+)
+
+// vectorEdit identifies the transient which owns a node, it is not zero-sized
+// so that the pointers of distinct transients never compare equal
+type vectorEdit struct {
+	_ byte
+}
+
+// vectorNode node of the trie, the branches have children and the leaves values
+type vectorNode[E any] struct {
+	edit     *vectorEdit
+	children []*vectorNode[E]
+	values   []E
+}
+
+// editable returns the node itself when it is owned by the edit, a copy owned by the edit otherwise.
+// A nil edit always copies, which is how the persistent operations share the untouched nodes.
+func editable[E any](edit *vectorEdit, node *vectorNode[E]) *vectorNode[E] {
+	if node != nil && edit != nil && node.edit == edit {
+		return node
+	}
+	n := &vectorNode[E]{edit: edit}
+	if node != nil {
+		n.children = slices.Clone(node.children)
+		n.values = slices.Clone(node.values)
+	}
+	return n
+}
+
+// newPath returns the branch leading from the level down to the leaf
+func newPath[E any](edit *vectorEdit, level uint, leaf *vectorNode[E]) *vectorNode[E] {
+	if level == 0 {
+		return leaf
+	}
+	return &vectorNode[E]{edit: edit, children: []*vectorNode[E]{newPath(edit, level-vectorBits, leaf)}}
+}
+
+// vector the state shared by the persistent and the transient vectors, the
+// last elements are kept out of the trie in the tail until it is full
+type vector[E any] struct {
+	count int
+	shift uint
+	root  *vectorNode[E]
+	tail  []E
+}
+
+func (v *vector[E]) tailOffset() int {
+	if v.count < vectorWidth {
+		return 0
+	}
+	return ((v.count - 1) >> vectorBits) << vectorBits
+}
+
+func (v *vector[E]) checkIndex(index int) {
+	if index < 0 || index >= v.count {
+		panic(fmt.Sprintf("lists: index %d out of range [0, %d)", index, v.count))
+	}
+}
+
+// leaf returns the values of the leaf holding the index
+func (v *vector[E]) leaf(index int) []E {
+	if index >= v.tailOffset() {
+		return v.tail
+	}
+	node := v.root
+	for level := v.shift; level > 0; level -= vectorBits {
+		node = node.children[(index>>level)&vectorMask]
+	}
+	return node.values
+}
+
+func (v *vector[E]) get(index int) E {
+	v.checkIndex(index)
+	return v.leaf(index)[index&vectorMask]
+}
+
+func (v *vector[E]) each(callback func(index int, value E) bool) {
+	for offset := 0; offset < v.count; offset += vectorWidth {
+		for i, value := range v.leaf(offset) {
+			if !callback(offset+i, value) {
+				return
+			}
+		}
+	}
+}
+
+func (v *vector[E]) append(edit *vectorEdit, value E) {
+	if v.count-v.tailOffset() < vectorWidth {
+		if edit == nil {
+			tail := make([]E, len(v.tail)+1)
+			copy(tail, v.tail)
+			tail[len(v.tail)] = value
+			v.tail = tail
+		} else {
+			v.tail = append(v.tail, value)
+		}
+		v.count++
+		return
+	}
+	// the tail is full, push it into the trie as a leaf
+	if v.root == nil {
+		v.root = &vectorNode[E]{edit: edit}
+		v.shift = vectorBits
+	}
+	leaf := &vectorNode[E]{edit: edit, values: v.tail}
+	if (v.count >> vectorBits) > (1 << v.shift) {
+		// the trie is full, grow it by one level
+		v.root = &vectorNode[E]{edit: edit, children: []*vectorNode[E]{v.root, newPath(edit, v.shift, leaf)}}
+		v.shift += vectorBits
+	} else {
+		v.root = v.pushTail(edit, v.shift, v.root, leaf)
+	}
+	if edit == nil {
+		v.tail = []E{value}
+	} else {
+		v.tail = make([]E, 1, vectorWidth)
+		v.tail[0] = value
+	}
+	v.count++
+}
+
+func (v *vector[E]) pushTail(edit *vectorEdit, level uint, parent, leaf *vectorNode[E]) *vectorNode[E] {
+	node := editable(edit, parent)
+	index := ((v.count - 1) >> level) & vectorMask
+	var child *vectorNode[E]
+	if level == vectorBits {
+		child = leaf
+	} else if index < len(node.children) {
+		child = v.pushTail(edit, level-vectorBits, node.children[index], leaf)
+	} else {
+		child = newPath(edit, level-vectorBits, leaf)
+	}
+	if index < len(node.children) {
+		node.children[index] = child
+	} else {
+		node.children = append(node.children, child)
+	}
+	return node
+}
+
+func (v *vector[E]) set(edit *vectorEdit, index int, value E) {
+	v.checkIndex(index)
+	if index >= v.tailOffset() {
+		if edit == nil {
+			v.tail = slices.Clone(v.tail)
+		}
+		v.tail[index&vectorMask] = value
+		return
+	}
+	v.root = v.setNode(edit, v.shift, v.root, index, value)
+}
+
+func (v *vector[E]) setNode(edit *vectorEdit, level uint, node *vectorNode[E], index int, value E) *vectorNode[E] {
+	node = editable(edit, node)
+	if level == 0 {
+		node.values[index&vectorMask] = value
+	} else {
+		child := (index >> level) & vectorMask
+		node.children[child] = v.setNode(edit, level-vectorBits, node.children[child], index, value)
+	}
+	return node
+}
+
+func (v *vector[E]) pop(edit *vectorEdit) {
+	switch {
+	case v.count == 0:
+		return
+	case v.count == 1:
+		*v = vector[E]{}
+		return
+	case v.count-v.tailOffset() > 1:
+		v.tail = v.tail[:len(v.tail)-1]
+		v.count--
+		return
+	}
+	// the tail is emptied, the last leaf of the trie becomes the tail
+	tail := v.leaf(v.count - 2)
+	if edit != nil {
+		tail = append(make([]E, 0, vectorWidth), tail...)
+	}
+	root := v.popTail(edit, v.shift, v.root)
+	if root == nil {
+		root = &vectorNode[E]{edit: edit}
+	}
+	if v.shift > vectorBits && len(root.children) == 1 {
+		root = root.children[0]
+		v.shift -= vectorBits
+	}
+	v.root = root
+	v.tail = tail
+	v.count--
+}
+
+// popTail removes the last leaf, it returns nil when the node is left empty
+func (v *vector[E]) popTail(edit *vectorEdit, level uint, node *vectorNode[E]) *vectorNode[E] {
+	index := ((v.count - 2) >> level) & vectorMask
+	if level > vectorBits {
+		child := v.popTail(edit, level-vectorBits, node.children[index])
+		if child == nil && index == 0 {
+			return nil
+		}
+		node = editable(edit, node)
+		if child == nil {
+			node.children = node.children[:index]
+		} else {
+			node.children[index] = child
+		}
+		return node
+	}
+	if index == 0 {
+		return nil
+	}
+	node = editable(edit, node)
+	node.children = node.children[:index]
+	return node
+}
+
+// NewPersistentVector new persistent vector
+func NewPersistentVector[E any](values ...E) *PersistentVector[E] {
+	return new(PersistentVector[E]).Append(values...)
+}
+
+// NewPersistentVectorFromList new persistent vector with the elements of the list
+func NewPersistentVectorFromList[E any](list *List[E]) *PersistentVector[E] {
+	return NewPersistentVector(list.items...)
+}
+
+// PersistentVector immutable vector with structural sharing
+//
+// It is a 32-way trie with the last elements kept in a tail, as in Clojure:
+// Get, Set, Append and Pop are O(log32 n) and the updates return a new version
+// which shares all the untouched nodes with the previous one. A vector never
+// changes once built, so it can be passed between goroutines without copying.
+// The zero value is an empty vector.
+type PersistentVector[E any] struct {
+	vector[E]
+}
+
+func (v *PersistentVector[E]) Count() int64 {
+	return int64(v.count)
+}
+
+func (v *PersistentVector[E]) IsEmpty() bool {
+	return v.count == 0
+}
+
+func (v *PersistentVector[E]) IsNotEmpty() bool {
+	return !v.IsEmpty()
+}
+
+// Get returns the element at the index, it panics when the index is out of range
+func (v *PersistentVector[E]) Get(index int) E {
+	return v.get(index)
+}
+
+func (v *PersistentVector[E]) First() (E, bool) {
+	if v.count == 0 {
+		return *new(E), false
+	}
+	return v.get(0), true
+}
+
+func (v *PersistentVector[E]) Last() (E, bool) {
+	if v.count == 0 {
+		return *new(E), false
+	}
+	return v.get(v.count - 1), true
+}
+
+// Set returns a new vector with the element at the index replaced, it panics when the index is out of range
+func (v *PersistentVector[E]) Set(index int, value E) *PersistentVector[E] {
+	next := &PersistentVector[E]{v.vector}
+	next.set(nil, index, value)
+	return next
+}
+
+// Append returns a new vector with the values added at the end
+func (v *PersistentVector[E]) Append(values ...E) *PersistentVector[E] {
+	if len(values) > 1 {
+		return v.Transient().Append(values...).Persistent()
+	}
+	next := &PersistentVector[E]{v.vector}
+	for _, value := range values {
+		next.append(nil, value)
+	}
+	return next
+}
+
+// Pop returns a new vector without the last element
+func (v *PersistentVector[E]) Pop() *PersistentVector[E] {
+	next := &PersistentVector[E]{v.vector}
+	next.pop(nil)
+	return next
+}
+
+// Transient returns a mutable copy of the vector for fast bulk updates,
+// the vector itself is left untouched
+func (v *PersistentVector[E]) Transient() *TransientVector[E] {
+	t := &TransientVector[E]{vector: v.vector, edit: new(vectorEdit)}
+	t.tail = append(make([]E, 0, vectorWidth), v.tail...)
+	return t
+}
+
+func (v *PersistentVector[E]) Each(callback func(index int, value E) bool) {
+	v.each(callback)
+}
+
+func (v *PersistentVector[E]) ToArray() []E {
+	values := make([]E, 0, v.count)
+	v.each(func(_ int, value E) bool {
+		values = append(values, value)
+		return true
+	})
+	return values
+}
+
+// ToList returns a list with the elements of the vector
+func (v *PersistentVector[E]) ToList() *List[E] {
+	return &List[E]{items: v.ToArray()}
+}
+
+func (v *PersistentVector[E]) ToJSON() ([]byte, error) {
+	return json.Marshal(v.ToArray())
+}
+
+func (v *PersistentVector[E]) MarshalJSON() ([]byte, error) {
+	return v.ToJSON()
+}
+
+func (v *PersistentVector[E]) UnmarshalJSON(data []byte) error {
+	values := []E{}
+	if err := json.Unmarshal(data, &values); err != nil {
+		return err
+	}
+	v.vector = NewPersistentVector(values...).vector
+	return nil
+}
+
+func (v *PersistentVector[E]) String() string {
+	str := new(strings.Builder)
+	str.WriteString(fmt.Sprintf("PersistentVector[%T](len=%d)", *new(E), v.count))
+	str.WriteByte('{')
+	str.WriteByte('\n')
+	v.each(func(index int, value E) bool {
+		str.WriteByte('\t')
+		if s, ok := any(value).(support.Stringable); ok {
+			str.WriteString(s.String())
+		} else {
+			str.WriteString(fmt.Sprintf("%v", value))
+		}
+		str.WriteByte(',')
+		str.WriteByte('\n')
+		return index < 4
+	})
+	if v.count > 5 {
+		str.WriteString("\t...\n")
+	}
+	str.WriteByte('}')
+	return str.String()
+}
+
+// TransientVector mutable builder of a persistent vector
+//
+// It updates in place the nodes it owns and copies the ones shared with
+// persistent vectors, which makes bulk construction much cheaper. It must not
+// be used concurrently, nor after Persistent is called.
+type TransientVector[E any] struct {
+	vector[E]
+	edit *vectorEdit
+}
+
+func (t *TransientVector[E]) ensureEditable() {
+	if t.edit == nil {
+		panic("lists: transient vector used after Persistent")
+	}
+}
+
+func (t *TransientVector[E]) Count() int64 {
+	return int64(t.count)
+}
+
+// Get returns the element at the index, it panics when the index is out of range
+func (t *TransientVector[E]) Get(index int) E {
+	t.ensureEditable()
+	return t.get(index)
+}
+
+// Set replaces the element at the index, it panics when the index is out of range
+func (t *TransientVector[E]) Set(index int, value E) *TransientVector[E] {
+	t.ensureEditable()
+	t.set(t.edit, index, value)
+	return t
+}
+
+// Append adds the values at the end
+func (t *TransientVector[E]) Append(values ...E) *TransientVector[E] {
+	t.ensureEditable()
+	for _, value := range values {
+		t.append(t.edit, value)
+	}
+	return t
+}
+
+// Pop removes the last element
+func (t *TransientVector[E]) Pop() *TransientVector[E] {
+	t.ensureEditable()
+	t.pop(t.edit)
+	return t
+}
+
+// Persistent returns the persistent vector with the elements, the transient can't be used afterwards
+func (t *TransientVector[E]) Persistent() *PersistentVector[E] {
+	t.ensureEditable()
+	t.edit = nil
+	v := &PersistentVector[E]{t.vector}
+	v.tail = slices.Clip(v.tail)
+	return v
+}
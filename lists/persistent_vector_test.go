@@ -0,0 +1,208 @@
+package lists
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPersistentVector_Append(t *testing.T) {
+	// crosses the tail, one and two levels of the trie
+	const n = 32*32*32 + 100
+	versions := []*PersistentVector[int]{}
+	vector := new(PersistentVector[int])
+	for i := 0; i < n; i++ {
+		if i%1000 == 0 {
+			versions = append(versions, vector)
+		}
+		vector = vector.Append(i)
+	}
+	assert.Equal(t, int64(n), vector.Count())
+	for i := 0; i < n; i++ {
+		if vector.Get(i) != i {
+			assert.Failf(t, "unexpected value", "index %d: %d", i, vector.Get(i))
+			break
+		}
+	}
+	// the previous versions are left untouched
+	for index, version := range versions {
+		assert.Equal(t, int64(index*1000), version.Count())
+		if version.IsNotEmpty() {
+			last, _ := version.Last()
+			assert.Equal(t, index*1000-1, last)
+		}
+	}
+}
+
+func TestPersistentVector_Set(t *testing.T) {
+	values := make([]int, 2000)
+	for i := range values {
+		values[i] = i
+	}
+	vector := NewPersistentVector(values...)
+	updated := vector.Set(0, -1).Set(1000, -2).Set(1999, -3)
+	assert.Equal(t, values, vector.ToArray())
+	assert.Equal(t, -1, updated.Get(0))
+	assert.Equal(t, -2, updated.Get(1000))
+	assert.Equal(t, -3, updated.Get(1999))
+	assert.Equal(t, 999, updated.Get(999))
+	assert.Panics(t, func() {
+		vector.Set(2000, 0)
+	})
+	assert.Panics(t, func() {
+		vector.Get(-1)
+	})
+}
+
+func TestPersistentVector_Pop(t *testing.T) {
+	const n = 32*32 + 70
+	values := make([]int, n)
+	for i := range values {
+		values[i] = i
+	}
+	vector := NewPersistentVector(values...)
+	for i := n; i > 0; i-- {
+		next := vector.Pop()
+		assert.Equal(t, int64(i), vector.Count())
+		assert.Equal(t, int64(i-1), next.Count())
+		last, _ := vector.Last()
+		assert.Equal(t, i-1, last)
+		vector = next
+	}
+	assert.True(t, vector.IsEmpty())
+	assert.True(t, vector.Pop().IsEmpty())
+	// an emptied vector is still usable
+	assert.Equal(t, []int{1, 2}, vector.Append(1, 2).ToArray())
+}
+
+func TestPersistentVector_Model(t *testing.T) {
+	rand := rand.New(rand.NewSource(1))
+	model := []int{}
+	vector := NewPersistentVector[int]()
+	history := []struct {
+		vector *PersistentVector[int]
+		values []int
+	}{}
+	for round := 0; round < 5000; round++ {
+		switch op := rand.Intn(10); {
+		case op < 6:
+			value := rand.Int()
+			model = append(model[:len(model):len(model)], value)
+			vector = vector.Append(value)
+		case op < 8 && len(model) > 0:
+			index := rand.Intn(len(model))
+			value := rand.Int()
+			model = append([]int{}, model...)
+			model[index] = value
+			vector = vector.Set(index, value)
+		default:
+			if len(model) > 0 {
+				model = model[:len(model)-1]
+			}
+			vector = vector.Pop()
+		}
+		if round%250 == 0 {
+			history = append(history, struct {
+				vector *PersistentVector[int]
+				values []int
+			}{vector, model})
+		}
+	}
+	assert.Equal(t, model, vector.ToArray())
+	for _, h := range history {
+		assert.Equal(t, h.values, h.vector.ToArray())
+	}
+}
+
+func TestPersistentVector_Transient(t *testing.T) {
+	vector := NewPersistentVector(1, 2, 3)
+	transient := vector.Transient()
+	for i := 4; i <= 100; i++ {
+		transient.Append(i)
+	}
+	transient.Set(0, 10).Set(50, 500).Pop()
+	assert.Equal(t, int64(99), transient.Count())
+	assert.Equal(t, 500, transient.Get(50))
+	built := transient.Persistent()
+	assert.Equal(t, []int{1, 2, 3}, vector.ToArray())
+	assert.Equal(t, int64(99), built.Count())
+	assert.Equal(t, 10, built.Get(0))
+	assert.Equal(t, 99, built.Get(98))
+	assert.Panics(t, func() {
+		transient.Append(1)
+	})
+
+	// a new transient does not write through to the shared nodes
+	other := built.Transient().Set(0, 0).Set(50, 0).Persistent()
+	assert.Equal(t, 10, built.Get(0))
+	assert.Equal(t, 500, built.Get(50))
+	assert.Equal(t, 0, other.Get(50))
+}
+
+func TestPersistentVector_List(t *testing.T) {
+	list := NewList(1, 2, 3)
+	vector := NewPersistentVectorFromList(list)
+	list.Set(0, 10)
+	assert.Equal(t, []int{1, 2, 3}, vector.ToArray())
+	converted := vector.ToList()
+	converted.Push(4)
+	assert.Equal(t, []int{1, 2, 3, 4}, converted.ToArray())
+	assert.Equal(t, int64(3), vector.Count())
+}
+
+func TestPersistentVector_Each(t *testing.T) {
+	vector := NewPersistentVector(1, 2, 3, 4)
+	values := []int{}
+	vector.Each(func(index int, value int) bool {
+		values = append(values, value)
+		return index < 2
+	})
+	assert.Equal(t, []int{1, 2, 3}, values)
+}
+
+func TestPersistentVector_JSON(t *testing.T) {
+	vector := NewPersistentVector(1, 2, 3)
+	jsonBytes, err := json.Marshal(vector)
+	assert.Nil(t, err)
+	assert.JSONEq(t, `[1,2,3]`, string(jsonBytes))
+	jsonBytes, err = json.Marshal(NewPersistentVector[int]())
+	assert.Nil(t, err)
+	assert.JSONEq(t, `[]`, string(jsonBytes))
+
+	var unmarshalled PersistentVector[int]
+	err = json.Unmarshal([]byte(`[4,5,6]`), &unmarshalled)
+	assert.Nil(t, err)
+	assert.Equal(t, []int{4, 5, 6}, unmarshalled.ToArray())
+}
+
+func TestPersistentVector_String(t *testing.T) {
+	vector := NewPersistentVector(1, 2, 3, 4, 5, 6, 7, 8, 9, 10)
+	str := vector.String()
+	pattern := regexp.MustCompile(fmt.Sprintf(`PersistentVector\[int\]\(len=%d\)\{\n(\t\d+,\n){5}\t(\.){3}\n\}`, vector.Count()))
+	assert.True(t, pattern.Match([]byte(str)))
+}
+
+func BenchmarkPersistentVector_Append(b *testing.B) {
+	b.Run("Persistent", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			vector := new(PersistentVector[int])
+			for j := 0; j < 10000; j++ {
+				vector = vector.Append(j)
+			}
+		}
+	})
+
+	b.Run("Transient", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			transient := new(PersistentVector[int]).Transient()
+			for j := 0; j < 10000; j++ {
+				transient.Append(j)
+			}
+			transient.Persistent()
+		}
+	})
+}
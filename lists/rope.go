@@ -0,0 +1,458 @@
+package lists
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/gopi-frame/contract/support"
+)
+
+// ropeChunkSize maximum number of elements of a leaf built or merged by the rope
+const ropeChunkSize = 64
+
+// ropeNode node of a rope, the leaves hold the elements and the branches the concatenation
+// of their children. Nodes are never modified once built, which lets ropes share them.
+type ropeNode[E any] struct {
+	left   *ropeNode[E]
+	right  *ropeNode[E]
+	values []E
+	size   int
+	height int
+}
+
+func (node *ropeNode[E]) isLeaf() bool {
+	return node.left == nil
+}
+
+func (node *ropeNode[E]) count() int {
+	if node == nil {
+		return 0
+	}
+	return node.size
+}
+
+func newRopeLeaf[E any](values []E) *ropeNode[E] {
+	if len(values) == 0 {
+		return nil
+	}
+	return &ropeNode[E]{values: values, size: len(values)}
+}
+
+func newRopeBranch[E any](left, right *ropeNode[E]) *ropeNode[E] {
+	return &ropeNode[E]{left: left, right: right, size: left.size + right.size, height: max(left.height, right.height) + 1}
+}
+
+// buildRope builds a balanced rope of the values, the values are not copied
+func buildRope[E any](values []E) *ropeNode[E] {
+	if len(values) <= ropeChunkSize {
+		return newRopeLeaf(values[:len(values):len(values)])
+	}
+	// split on a chunk boundary so that the leaves are full
+	middle := (len(values)/ropeChunkSize + 1) / 2 * ropeChunkSize
+	return newRopeBranch(buildRope(values[:middle]), buildRope(values[middle:]))
+}
+
+// balanceRope concatenates the trees whose heights differ by at most two into an AVL tree
+func balanceRope[E any](left, right *ropeNode[E]) *ropeNode[E] {
+	if left.height > right.height+1 {
+		if left.left.height >= left.right.height {
+			return newRopeBranch(left.left, newRopeBranch(left.right, right))
+		}
+		middle := left.right
+		return newRopeBranch(newRopeBranch(left.left, middle.left), newRopeBranch(middle.right, right))
+	}
+	if right.height > left.height+1 {
+		if right.right.height >= right.left.height {
+			return newRopeBranch(newRopeBranch(left, right.left), right.right)
+		}
+		middle := right.left
+		return newRopeBranch(newRopeBranch(left, middle.left), newRopeBranch(middle.right, right.right))
+	}
+	return newRopeBranch(left, right)
+}
+
+// joinRope concatenates the trees in O(|height(left) - height(right)|)
+func joinRope[E any](left, right *ropeNode[E]) *ropeNode[E] {
+	if left == nil {
+		return right
+	}
+	if right == nil {
+		return left
+	}
+	if left.isLeaf() && right.isLeaf() && left.size+right.size <= ropeChunkSize {
+		return newRopeLeaf(slices.Concat(left.values, right.values))
+	}
+	if left.height > right.height+1 {
+		return balanceRope(left.left, joinRope(left.right, right))
+	}
+	if right.height > left.height+1 {
+		return balanceRope(joinRope(left, right.left), right.right)
+	}
+	return newRopeBranch(left, right)
+}
+
+// splitRope splits the tree into the first index elements and the others, in O(log n)
+func splitRope[E any](node *ropeNode[E], index int) (*ropeNode[E], *ropeNode[E]) {
+	if index <= 0 {
+		return nil, node
+	}
+	if index >= node.count() {
+		return node, nil
+	}
+	if node.isLeaf() {
+		return newRopeLeaf(node.values[:index:index]), newRopeLeaf(node.values[index:])
+	}
+	if index <= node.left.size {
+		left, right := splitRope(node.left, index)
+		return left, joinRope(right, node.right)
+	}
+	left, right := splitRope(node.right, index-node.left.size)
+	return joinRope(node.left, left), right
+}
+
+func (node *ropeNode[E]) get(index int) E {
+	for !node.isLeaf() {
+		if index < node.left.size {
+			node = node.left
+		} else {
+			index -= node.left.size
+			node = node.right
+		}
+	}
+	return node.values[index]
+}
+
+// set returns a copy of the tree with the element at the index replaced
+func (node *ropeNode[E]) set(index int, value E) *ropeNode[E] {
+	if node.isLeaf() {
+		values := slices.Clone(node.values)
+		values[index] = value
+		return newRopeLeaf(values)
+	}
+	if index < node.left.size {
+		return newRopeBranch(node.left.set(index, value), node.right)
+	}
+	return newRopeBranch(node.left, node.right.set(index-node.left.size, value))
+}
+
+// each calls the callback with the elements from the offset, it returns false once the callback does
+func (node *ropeNode[E]) each(offset int, callback func(index int, value E) bool) bool {
+	if node == nil {
+		return true
+	}
+	if node.isLeaf() {
+		for i, value := range node.values {
+			if !callback(offset+i, value) {
+				return false
+			}
+		}
+		return true
+	}
+	return node.left.each(offset, callback) && node.right.each(offset+node.left.size, callback)
+}
+
+// eachReverse calls the callback with the elements from the end, it returns false once the callback does
+func (node *ropeNode[E]) eachReverse(offset int, callback func(index int, value E) bool) bool {
+	if node == nil {
+		return true
+	}
+	if node.isLeaf() {
+		for i := len(node.values) - 1; i >= 0; i-- {
+			if !callback(offset+i, node.values[i]) {
+				return false
+			}
+		}
+		return true
+	}
+	return node.right.eachReverse(offset+node.left.size, callback) && node.left.eachReverse(offset, callback)
+}
+
+// NewRope new rope
+func NewRope[E any](values ...E) *Rope[E] {
+	rope := new(Rope[E])
+	rope.Push(values...)
+	return rope
+}
+
+// Rope rope
+//
+// It is a balanced tree of chunks of elements: Get, Set, Insert, Delete, Split and
+// Concat are O(log n), which suits large sequences edited in the middle. The nodes
+// are immutable and shared, so Clone, Split and Concat never copy the elements.
+type Rope[E any] struct {
+	sync.RWMutex
+	root *ropeNode[E]
+}
+
+func (rope *Rope[E]) Count() int64 {
+	return int64(rope.root.count())
+}
+
+func (rope *Rope[E]) IsEmpty() bool {
+	return rope.Count() == 0
+}
+
+func (rope *Rope[E]) IsNotEmpty() bool {
+	return !rope.IsEmpty()
+}
+
+func (rope *Rope[E]) Contains(value E) bool {
+	return rope.ContainsWhere(func(e E) bool {
+		return reflect.DeepEqual(e, value)
+	})
+}
+
+func (rope *Rope[E]) ContainsWhere(callback func(value E) bool) bool {
+	return rope.IndexOfWhere(callback) >= 0
+}
+
+// Push adds the values at the end
+func (rope *Rope[E]) Push(values ...E) {
+	rope.root = joinRope(rope.root, buildRope(slices.Clone(values)))
+}
+
+// Insert inserts the values before the element at the index, negative indexes count from the end
+// and inserting at the count appends the values
+func (rope *Rope[E]) Insert(index int, values ...E) error {
+	i, ok := resolveIndex(index, rope.root.count())
+	if !ok && index != rope.root.count() {
+		return &RangeError{index, rope.root.count()}
+	}
+	left, right := splitRope(rope.root, i)
+	rope.root = joinRope(joinRope(left, buildRope(slices.Clone(values))), right)
+	return nil
+}
+
+// Delete removes the elements in [from, to)
+func (rope *Rope[E]) Delete(from, to int) error {
+	if err := rope.checkRange(from, to); err != nil {
+		return err
+	}
+	left, rest := splitRope(rope.root, from)
+	_, right := splitRope(rest, to-from)
+	rope.root = joinRope(left, right)
+	return nil
+}
+
+// RemoveAt removes the element at the index, negative indexes count from the end.
+// It panics with a *RangeError when the index is out of range.
+func (rope *Rope[E]) RemoveAt(index int) {
+	i, ok := resolveIndex(index, rope.root.count())
+	if !ok {
+		panic(&RangeError{index, rope.root.count()})
+	}
+	_ = rope.Delete(i, i+1)
+}
+
+// Split returns the ropes of the elements before the index and from the index, the rope
+// itself is left untouched. It panics with a *RangeError when the index is out of range.
+func (rope *Rope[E]) Split(index int) (*Rope[E], *Rope[E]) {
+	if index < 0 || index > rope.root.count() {
+		panic(&RangeError{index, rope.root.count()})
+	}
+	left, right := splitRope(rope.root, index)
+	return &Rope[E]{root: left}, &Rope[E]{root: right}
+}
+
+// Concat appends the elements of the other rope, which is left untouched
+func (rope *Rope[E]) Concat(other *Rope[E]) {
+	rope.root = joinRope(rope.root, other.root)
+}
+
+// Sub returns a new rope with the elements in [from, to).
+// It panics with a *BoundsError when the range is out of the rope.
+func (rope *Rope[E]) Sub(from, to int) *Rope[E] {
+	if err := rope.checkRange(from, to); err != nil {
+		panic(err)
+	}
+	_, rest := splitRope(rope.root, from)
+	sub, _ := splitRope(rest, to-from)
+	return &Rope[E]{root: sub}
+}
+
+func (rope *Rope[E]) checkRange(from, to int) error {
+	if from < 0 || to < from || to > rope.root.count() {
+		return &BoundsError{from, to, rope.root.count()}
+	}
+	return nil
+}
+
+func (rope *Rope[E]) Clear() {
+	rope.root = nil
+}
+
+// Get returns the element at the index, negative indexes count from the end.
+// It panics with a *RangeError when the index is out of range.
+func (rope *Rope[E]) Get(index int) E {
+	value, err := rope.TryGet(index)
+	if err != nil {
+		panic(err)
+	}
+	return value
+}
+
+// TryGet returns the element at the index, negative indexes count from the end
+func (rope *Rope[E]) TryGet(index int) (E, error) {
+	i, ok := resolveIndex(index, rope.root.count())
+	if !ok {
+		return *new(E), &RangeError{index, rope.root.count()}
+	}
+	return rope.root.get(i), nil
+}
+
+// Set replaces the element at the index, negative indexes count from the end.
+// It panics with a *RangeError when the index is out of range.
+func (rope *Rope[E]) Set(index int, value E) {
+	i, ok := resolveIndex(index, rope.root.count())
+	if !ok {
+		panic(&RangeError{index, rope.root.count()})
+	}
+	rope.root = rope.root.set(i, value)
+}
+
+func (rope *Rope[E]) First() (E, bool) {
+	return rope.FirstWhere(func(E) bool {
+		return true
+	})
+}
+
+func (rope *Rope[E]) FirstOr(value E) E {
+	if v, ok := rope.First(); ok {
+		return v
+	}
+	return value
+}
+
+func (rope *Rope[E]) FirstWhere(callback func(item E) bool) (E, bool) {
+	if index := rope.IndexOfWhere(callback); index >= 0 {
+		return rope.root.get(index), true
+	}
+	return *new(E), false
+}
+
+func (rope *Rope[E]) FirstWhereOr(callback func(item E) bool, value E) E {
+	if v, ok := rope.FirstWhere(callback); ok {
+		return v
+	}
+	return value
+}
+
+func (rope *Rope[E]) Last() (E, bool) {
+	return rope.LastWhere(func(E) bool {
+		return true
+	})
+}
+
+func (rope *Rope[E]) LastOr(value E) E {
+	if v, ok := rope.Last(); ok {
+		return v
+	}
+	return value
+}
+
+func (rope *Rope[E]) LastWhere(callback func(item E) bool) (E, bool) {
+	var found E
+	ok := false
+	rope.root.eachReverse(0, func(_ int, value E) bool {
+		if callback(value) {
+			found, ok = value, true
+		}
+		return !ok
+	})
+	return found, ok
+}
+
+func (rope *Rope[E]) LastWhereOr(callback func(item E) bool, value E) E {
+	if v, ok := rope.LastWhere(callback); ok {
+		return v
+	}
+	return value
+}
+
+func (rope *Rope[E]) IndexOf(value E) int {
+	return rope.IndexOfWhere(func(e E) bool {
+		return reflect.DeepEqual(e, value)
+	})
+}
+
+func (rope *Rope[E]) IndexOfWhere(callback func(item E) bool) int {
+	found := -1
+	rope.root.each(0, func(index int, value E) bool {
+		if callback(value) {
+			found = index
+		}
+		return found < 0
+	})
+	return found
+}
+
+func (rope *Rope[E]) Min(callback func(a, b E) int) E {
+	return slices.MinFunc(rope.ToArray(), callback)
+}
+
+func (rope *Rope[E]) Max(callback func(a, b E) int) E {
+	return slices.MaxFunc(rope.ToArray(), callback)
+}
+
+func (rope *Rope[E]) Each(callback func(index int, value E) bool) {
+	rope.root.each(0, callback)
+}
+
+// Clone returns a new rope with the elements, the nodes are shared and nothing is copied
+func (rope *Rope[E]) Clone() *Rope[E] {
+	return &Rope[E]{root: rope.root}
+}
+
+// ToArray returns a copy of the elements
+func (rope *Rope[E]) ToArray() []E {
+	values := make([]E, 0, rope.root.count())
+	rope.root.each(0, func(_ int, value E) bool {
+		values = append(values, value)
+		return true
+	})
+	return values
+}
+
+func (rope *Rope[E]) ToJSON() ([]byte, error) {
+	return json.Marshal(rope.ToArray())
+}
+
+func (rope *Rope[E]) MarshalJSON() ([]byte, error) {
+	return rope.ToJSON()
+}
+
+func (rope *Rope[E]) UnmarshalJSON(data []byte) error {
+	values := []E{}
+	if err := json.Unmarshal(data, &values); err != nil {
+		return err
+	}
+	rope.root = buildRope(values)
+	return nil
+}
+
+func (rope *Rope[E]) String() string {
+	str := new(strings.Builder)
+	str.WriteString(fmt.Sprintf("Rope[%T](len=%d)", *new(E), rope.Count()))
+	str.WriteByte('{')
+	str.WriteByte('\n')
+	rope.root.each(0, func(index int, value E) bool {
+		str.WriteByte('\t')
+		if v, ok := any(value).(support.Stringable); ok {
+			str.WriteString(v.String())
+		} else {
+			str.WriteString(fmt.Sprintf("%v", value))
+		}
+		str.WriteByte(',')
+		str.WriteByte('\n')
+		return index < 4
+	})
+	if rope.Count() > 5 {
+		str.WriteString("\t...\n")
+	}
+	str.WriteByte('}')
+	return str.String()
+}
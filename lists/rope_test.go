@@ -0,0 +1,213 @@
+package lists
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"regexp"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// checkRope asserts the tree is an AVL tree with consistent sizes and returns the elements
+func checkRope(t *testing.T, rope *Rope[int]) []int {
+	var check func(node *ropeNode[int])
+	check = func(node *ropeNode[int]) {
+		if node == nil {
+			return
+		}
+		if node.isLeaf() {
+			assert.NotEmpty(t, node.values)
+			assert.Equal(t, len(node.values), node.size)
+			assert.Equal(t, 0, node.height)
+			return
+		}
+		check(node.left)
+		check(node.right)
+		assert.Equal(t, node.left.size+node.right.size, node.size)
+		assert.Equal(t, max(node.left.height, node.right.height)+1, node.height)
+		assert.LessOrEqual(t, node.left.height-node.right.height, 1)
+		assert.GreaterOrEqual(t, node.left.height-node.right.height, -1)
+	}
+	check(rope.root)
+	return rope.ToArray()
+}
+
+func TestRope_Push(t *testing.T) {
+	values := make([]int, 1000)
+	for i := range values {
+		values[i] = i
+	}
+	rope := NewRope(values...)
+	rope.Push(1000, 1001)
+	assert.Equal(t, int64(1002), rope.Count())
+	assert.Equal(t, append(values, 1000, 1001), checkRope(t, rope))
+	assert.Equal(t, 500, rope.Get(500))
+	assert.Equal(t, 1001, rope.Get(-1))
+	values[0] = -1
+	assert.Equal(t, 0, rope.Get(0))
+}
+
+func TestRope_Model(t *testing.T) {
+	rand := rand.New(rand.NewSource(1))
+	rope := NewRope[int]()
+	model := []int{}
+	for round := 0; round < 2000; round++ {
+		switch op := rand.Intn(10); {
+		case op < 5:
+			index := rand.Intn(len(model) + 1)
+			values := make([]int, rand.Intn(100)+1)
+			for i := range values {
+				values[i] = rand.Int()
+			}
+			assert.Nil(t, rope.Insert(index, values...))
+			model = slices.Insert(model, index, values...)
+		case op < 8 && len(model) > 0:
+			from := rand.Intn(len(model))
+			to := from + rand.Intn(min(len(model)-from, 50)+1)
+			assert.Nil(t, rope.Delete(from, to))
+			model = slices.Delete(model, from, to)
+		case len(model) > 0:
+			index := rand.Intn(len(model))
+			value := rand.Int()
+			rope.Set(index, value)
+			model[index] = value
+		}
+		if round%100 == 0 {
+			assert.Equal(t, model, checkRope(t, rope))
+		}
+	}
+	assert.Equal(t, model, checkRope(t, rope))
+	for i := 0; i < len(model); i += 37 {
+		assert.Equal(t, model[i], rope.Get(i))
+	}
+}
+
+func TestRope_SplitConcat(t *testing.T) {
+	values := make([]int, 500)
+	for i := range values {
+		values[i] = i
+	}
+	rope := NewRope(values...)
+	for _, index := range []int{0, 1, 63, 64, 65, 250, 499, 500} {
+		left, right := rope.Split(index)
+		assert.Equal(t, values[:index], checkRope(t, left))
+		assert.Equal(t, values[index:], checkRope(t, right))
+		right.Concat(left)
+		assert.Equal(t, append(slices.Clone(values[index:]), values[:index]...), checkRope(t, right))
+	}
+	// the rope is left untouched
+	assert.Equal(t, values, checkRope(t, rope))
+	assert.Panics(t, func() {
+		rope.Split(501)
+	})
+
+	small := NewRope(1, 2)
+	small.Concat(rope)
+	assert.Equal(t, int64(502), small.Count())
+	assert.Equal(t, 0, small.Get(2))
+	checkRope(t, small)
+}
+
+func TestRope_Sub(t *testing.T) {
+	rope := NewRope(0, 1, 2, 3, 4, 5)
+	assert.Equal(t, []int{2, 3}, rope.Sub(2, 4).ToArray())
+	assert.Equal(t, []int{}, rope.Sub(3, 3).ToArray())
+	assert.Panics(t, func() {
+		rope.Sub(4, 2)
+	})
+}
+
+func TestRope_Clone(t *testing.T) {
+	rope := NewRope(1, 2, 3)
+	clone := rope.Clone()
+	clone.Set(0, 10)
+	clone.Push(4)
+	rope.RemoveAt(-1)
+	assert.Equal(t, []int{1, 2}, rope.ToArray())
+	assert.Equal(t, []int{10, 2, 3, 4}, clone.ToArray())
+}
+
+func TestRope_OutOfRange(t *testing.T) {
+	rope := NewRope(1, 2, 3)
+	_, err := rope.TryGet(3)
+	assert.IsType(t, &RangeError{}, err)
+	assert.IsType(t, &RangeError{}, rope.Insert(4, 4))
+	assert.Equal(t, &BoundsError{2, 4, 3}, rope.Delete(2, 4))
+	assert.Panics(t, func() {
+		rope.Get(-4)
+	})
+	assert.Panics(t, func() {
+		rope.Set(3, 3)
+	})
+	assert.Panics(t, func() {
+		rope.RemoveAt(3)
+	})
+	assert.Equal(t, []int{1, 2, 3}, rope.ToArray())
+}
+
+func TestRope_Read(t *testing.T) {
+	rope := NewRope(3, 1, 4, 1, 5)
+	assert.True(t, rope.Contains(4))
+	assert.False(t, rope.Contains(2))
+	assert.Equal(t, 1, rope.IndexOf(1))
+	assert.Equal(t, -1, rope.IndexOf(2))
+	first, _ := rope.First()
+	last, _ := rope.Last()
+	assert.Equal(t, 3, first)
+	assert.Equal(t, 5, last)
+	even, ok := rope.LastWhere(func(item int) bool {
+		return item%2 == 0
+	})
+	assert.True(t, ok)
+	assert.Equal(t, 4, even)
+	assert.Equal(t, 0, rope.FirstWhereOr(func(item int) bool {
+		return item > 5
+	}, 0))
+	assert.Equal(t, 1, rope.Min(func(a, b int) int {
+		return a - b
+	}))
+	assert.Equal(t, 5, rope.Max(func(a, b int) int {
+		return a - b
+	}))
+	empty := NewRope[int]()
+	assert.Equal(t, 7, empty.LastOr(7))
+	assert.Equal(t, 7, empty.FirstOr(7))
+}
+
+func TestRope_JSON(t *testing.T) {
+	rope := NewRope(1, 2, 3)
+	jsonBytes, err := json.Marshal(rope)
+	assert.Nil(t, err)
+	assert.JSONEq(t, `[1,2,3]`, string(jsonBytes))
+
+	unmarshalled := NewRope[int]()
+	err = json.Unmarshal([]byte(`[4,5,6]`), unmarshalled)
+	assert.Nil(t, err)
+	assert.Equal(t, []int{4, 5, 6}, unmarshalled.ToArray())
+}
+
+func TestRope_String(t *testing.T) {
+	rope := NewRope(1, 2, 3, 4, 5, 6, 7, 8, 9, 10)
+	str := rope.String()
+	pattern := regexp.MustCompile(fmt.Sprintf(`Rope\[int\]\(len=%d\)\{\n(\t\d+,\n){5}\t(\.){3}\n\}`, rope.Count()))
+	assert.True(t, pattern.Match([]byte(str)))
+}
+
+func BenchmarkRope_Insert(b *testing.B) {
+	b.Run("Rope", func(b *testing.B) {
+		rope := NewRope[int]()
+		for i := 0; i < b.N; i++ {
+			_ = rope.Insert(i/2, i)
+		}
+	})
+
+	b.Run("List", func(b *testing.B) {
+		list := NewList[int]()
+		for i := 0; i < b.N; i++ {
+			_ = list.Insert(i/2, i)
+		}
+	})
+}
@@ -0,0 +1,219 @@
+package lists
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/gopi-frame/contract/support"
+	"github.com/gopi-frame/support/internal/skiplist"
+)
+
+// NewSkipList new skip list
+func NewSkipList[E any](comparator support.Comparator[E], values ...E) *SkipList[E] {
+	list := new(SkipList[E])
+	list.comparator = comparator
+	list.items = skiplist.New(comparator.Compare)
+	list.Push(values...)
+	return list
+}
+
+// SkipList set of distinct values kept sorted by the comparator
+//
+// A value equal to an element replaces it. Insert, Remove, the lookups and the rank
+// queries are O(log n) on average, as with tree.RBTree and tree.AVLTree, without any
+// rebalancing. The list is safe for concurrent use: the readers share a lock and the
+// callbacks of Range and Each run on a copy, without the lock held.
+type SkipList[E any] struct {
+	mu         sync.RWMutex
+	comparator support.Comparator[E]
+	items      *skiplist.List[E]
+}
+
+func (list *SkipList[E]) Comparator() support.Comparator[E] {
+	return list.comparator
+}
+
+func (list *SkipList[E]) Count() int64 {
+	list.mu.RLock()
+	defer list.mu.RUnlock()
+	return int64(list.items.Len())
+}
+
+func (list *SkipList[E]) IsEmpty() bool {
+	return list.Count() == 0
+}
+
+func (list *SkipList[E]) IsNotEmpty() bool {
+	return list.Count() > 0
+}
+
+// Insert inserts the value, or replaces the equal element; it returns true when the value was added
+func (list *SkipList[E]) Insert(value E) bool {
+	list.mu.Lock()
+	defer list.mu.Unlock()
+	return list.items.Insert(value)
+}
+
+// Push inserts the values
+func (list *SkipList[E]) Push(values ...E) {
+	list.mu.Lock()
+	defer list.mu.Unlock()
+	for _, value := range values {
+		list.items.Insert(value)
+	}
+}
+
+// Remove removes the element equal to the value, it returns true when there was one
+func (list *SkipList[E]) Remove(value E) bool {
+	list.mu.Lock()
+	defer list.mu.Unlock()
+	_, ok := list.items.Remove(value)
+	return ok
+}
+
+// Contains reports whether the list has an element equal to the value according to the comparator
+func (list *SkipList[E]) Contains(value E) bool {
+	_, ok := list.Find(value)
+	return ok
+}
+
+// Find returns the element equal to the value according to the comparator
+func (list *SkipList[E]) Find(value E) (E, bool) {
+	list.mu.RLock()
+	defer list.mu.RUnlock()
+	return list.items.Find(value)
+}
+
+// Floor returns the greatest element less than or equal to the value
+func (list *SkipList[E]) Floor(value E) (E, bool) {
+	list.mu.RLock()
+	defer list.mu.RUnlock()
+	return list.items.Floor(value)
+}
+
+// Ceiling returns the least element greater than or equal to the value
+func (list *SkipList[E]) Ceiling(value E) (E, bool) {
+	list.mu.RLock()
+	defer list.mu.RUnlock()
+	return list.items.Ceiling(value)
+}
+
+// Rank returns the number of elements less than the value, which is the index of the value when present
+func (list *SkipList[E]) Rank(value E) int {
+	list.mu.RLock()
+	defer list.mu.RUnlock()
+	return list.items.Rank(value)
+}
+
+// At returns the element at the index, negative indexes count from the end
+func (list *SkipList[E]) At(index int) (E, error) {
+	list.mu.RLock()
+	defer list.mu.RUnlock()
+	i, ok := resolveIndex(index, list.items.Len())
+	if !ok {
+		return *new(E), &RangeError{Index: index, Count: list.items.Len()}
+	}
+	value, _ := list.items.At(i)
+	return value, nil
+}
+
+func (list *SkipList[E]) First() (E, bool) {
+	list.mu.RLock()
+	defer list.mu.RUnlock()
+	return list.items.First()
+}
+
+func (list *SkipList[E]) Last() (E, bool) {
+	list.mu.RLock()
+	defer list.mu.RUnlock()
+	return list.items.Last()
+}
+
+// Range calls the callback for the elements in [from, to), in order, until it returns false
+func (list *SkipList[E]) Range(from, to E, callback func(value E) bool) {
+	list.mu.RLock()
+	values := []E{}
+	list.items.Range(from, to, func(value E) bool {
+		values = append(values, value)
+		return true
+	})
+	list.mu.RUnlock()
+	for _, value := range values {
+		if !callback(value) {
+			return
+		}
+	}
+}
+
+// Each calls the callback for the elements, in order, until it returns false
+func (list *SkipList[E]) Each(callback func(index int, value E) bool) {
+	for index, value := range list.ToArray() {
+		if !callback(index, value) {
+			return
+		}
+	}
+}
+
+func (list *SkipList[E]) Clear() {
+	list.mu.Lock()
+	defer list.mu.Unlock()
+	list.items.Clear()
+}
+
+// ToArray returns the elements in order
+func (list *SkipList[E]) ToArray() []E {
+	list.mu.RLock()
+	defer list.mu.RUnlock()
+	return list.items.Values()
+}
+
+func (list *SkipList[E]) ToJSON() ([]byte, error) {
+	return json.Marshal(list.ToArray())
+}
+
+func (list *SkipList[E]) MarshalJSON() ([]byte, error) {
+	return list.ToJSON()
+}
+
+// UnmarshalJSON replaces the elements of the list, it keeps the comparator of the list
+func (list *SkipList[E]) UnmarshalJSON(data []byte) error {
+	values := []E{}
+	if err := json.Unmarshal(data, &values); err != nil {
+		return err
+	}
+	list.mu.Lock()
+	defer list.mu.Unlock()
+	list.items.Clear()
+	for _, value := range values {
+		list.items.Insert(value)
+	}
+	return nil
+}
+
+func (list *SkipList[E]) String() string {
+	values := list.ToArray()
+	str := new(strings.Builder)
+	str.WriteString(fmt.Sprintf("SkipList[%T](len=%d)", *new(E), len(values)))
+	str.WriteByte('{')
+	str.WriteByte('\n')
+	for index, value := range values {
+		str.WriteByte('\t')
+		if v, ok := any(value).(support.Stringable); ok {
+			str.WriteString(v.String())
+		} else {
+			str.WriteString(fmt.Sprintf("%v", value))
+		}
+		str.WriteByte(',')
+		str.WriteByte('\n')
+		if index >= 4 {
+			break
+		}
+	}
+	if len(values) > 5 {
+		str.WriteString("\t...\n")
+	}
+	str.WriteByte('}')
+	return str.String()
+}
@@ -0,0 +1,147 @@
+package lists
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"regexp"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSkipList_Insert(t *testing.T) {
+	list := NewSkipList[int](_intComparator{}, 5, 1, 3)
+	assert.True(t, list.Insert(4))
+	assert.False(t, list.Insert(3))
+	list.Push(2, 0, 6)
+	assert.Equal(t, []int{0, 1, 2, 3, 4, 5, 6}, list.ToArray())
+	assert.Equal(t, int64(7), list.Count())
+	assert.True(t, list.Remove(3))
+	assert.False(t, list.Remove(3))
+	assert.False(t, list.Contains(3))
+	assert.True(t, list.Contains(4))
+	assert.Equal(t, []int{0, 1, 2, 4, 5, 6}, list.ToArray())
+}
+
+func TestSkipList_Model(t *testing.T) {
+	rand := rand.New(rand.NewSource(1))
+	list := NewSkipList[int](_intComparator{})
+	model := []int{}
+	for round := 0; round < 2000; round++ {
+		value := rand.Intn(300)
+		index, found := slices.BinarySearch(model, value)
+		if rand.Intn(3) < 2 {
+			list.Insert(value)
+			if !found {
+				model = slices.Insert(model, index, value)
+			}
+		} else {
+			list.Remove(value)
+			if found {
+				model = slices.Delete(model, index, index+1)
+			}
+		}
+	}
+	assert.Equal(t, model, list.ToArray())
+	for i := 0; i < len(model); i += 7 {
+		assert.Equal(t, i, list.Rank(model[i]))
+		value, err := list.At(i)
+		assert.Nil(t, err)
+		assert.Equal(t, model[i], value)
+	}
+}
+
+func TestSkipList_Search(t *testing.T) {
+	list := NewSkipList[int](_intComparator{}, 10, 20, 30, 40)
+	floor, ok := list.Floor(25)
+	assert.True(t, ok)
+	assert.Equal(t, 20, floor)
+	_, ok = list.Floor(5)
+	assert.False(t, ok)
+	ceiling, ok := list.Ceiling(25)
+	assert.True(t, ok)
+	assert.Equal(t, 30, ceiling)
+	ceiling, _ = list.Ceiling(30)
+	assert.Equal(t, 30, ceiling)
+	_, ok = list.Ceiling(45)
+	assert.False(t, ok)
+	assert.Equal(t, 2, list.Rank(25))
+	assert.Equal(t, 2, list.Rank(30))
+	last, err := list.At(-1)
+	assert.Nil(t, err)
+	assert.Equal(t, 40, last)
+	_, err = list.At(4)
+	assert.IsType(t, &RangeError{}, err)
+	first, _ := list.First()
+	assert.Equal(t, 10, first)
+}
+
+func TestSkipList_Range(t *testing.T) {
+	list := NewSkipList[int](_intComparator{}, 1, 2, 3, 4, 5, 6)
+	values := []int{}
+	list.Range(2, 5, func(value int) bool {
+		values = append(values, value)
+		return true
+	})
+	assert.Equal(t, []int{2, 3, 4}, values)
+	values = values[:0]
+	list.Each(func(index int, value int) bool {
+		// the callback runs without the lock held
+		list.Remove(value + 1)
+		values = append(values, value)
+		return index < 2
+	})
+	assert.Equal(t, []int{1, 2, 3}, values)
+	assert.Equal(t, []int{1, 5, 6}, list.ToArray())
+}
+
+func TestSkipList_Concurrent(t *testing.T) {
+	const workers, perWorker = 8, 500
+	list := NewSkipList[int](_intComparator{})
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < perWorker; i++ {
+				list.Insert(w*perWorker + i)
+				list.Floor(i)
+				list.Range(i, i+10, func(int) bool {
+					return true
+				})
+				if i%2 == 1 {
+					list.Remove(w*perWorker + i)
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+	assert.Equal(t, int64(workers*perWorker/2), list.Count())
+	values := list.ToArray()
+	assert.True(t, slices.IsSorted(values))
+	for _, value := range values {
+		assert.Equal(t, 0, value%2)
+	}
+}
+
+func TestSkipList_JSON(t *testing.T) {
+	list := NewSkipList[int](_intComparator{}, 2, 1)
+	jsonBytes, err := json.Marshal(list)
+	assert.Nil(t, err)
+	assert.JSONEq(t, `[1,2]`, string(jsonBytes))
+
+	unmarshalled := NewSkipList[int](_intComparator{}, 9)
+	err = json.Unmarshal([]byte(`[3,1,2,1]`), unmarshalled)
+	assert.Nil(t, err)
+	assert.Equal(t, []int{1, 2, 3}, unmarshalled.ToArray())
+}
+
+func TestSkipList_String(t *testing.T) {
+	list := NewSkipList[int](_intComparator{}, 10, 9, 8, 7, 6, 5, 4, 3, 2, 1)
+	str := list.String()
+	pattern := regexp.MustCompile(fmt.Sprintf(`SkipList\[int\]\(len=%d\)\{\n\t1,\n\t2,\n\t3,\n\t4,\n\t5,\n\t(\.){3}\n\}`, list.Count()))
+	assert.True(t, pattern.Match([]byte(str)))
+}
@@ -0,0 +1,206 @@
+package lists
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/gopi-frame/contract/support"
+)
+
+// NewSortedList new sorted list
+func NewSortedList[E any](comparator support.Comparator[E], values ...E) *SortedList[E] {
+	list := new(SortedList[E])
+	list.comparator = comparator
+	list.items = NewList[E]()
+	list.Push(values...)
+	return list
+}
+
+// SortedList list kept sorted by the comparator
+//
+// The values are inserted at their place, after the equal ones, so the list is
+// always sorted and stable. The lookups are binary searches.
+type SortedList[E any] struct {
+	sync.RWMutex
+	comparator support.Comparator[E]
+	items      *List[E]
+}
+
+func (list *SortedList[E]) compare(a, b E) int {
+	return list.comparator.Compare(a, b)
+}
+
+func (list *SortedList[E]) Comparator() support.Comparator[E] {
+	return list.comparator
+}
+
+func (list *SortedList[E]) Count() int64 {
+	return list.items.Count()
+}
+
+func (list *SortedList[E]) IsEmpty() bool {
+	return list.items.IsEmpty()
+}
+
+func (list *SortedList[E]) IsNotEmpty() bool {
+	return list.items.IsNotEmpty()
+}
+
+// Push inserts the values at their place
+func (list *SortedList[E]) Push(values ...E) {
+	if len(values) > 1 {
+		// append and merge the sorted runs rather than shift the elements for every value
+		sorted := slices.Clone(values)
+		slices.SortStableFunc(sorted, list.compare)
+		merged := make([]E, 0, len(list.items.items)+len(sorted))
+		i, j := 0, 0
+		for i < len(list.items.items) && j < len(sorted) {
+			if list.compare(sorted[j], list.items.items[i]) < 0 {
+				merged = append(merged, sorted[j])
+				j++
+			} else {
+				merged = append(merged, list.items.items[i])
+				i++
+			}
+		}
+		merged = append(merged, list.items.items[i:]...)
+		list.items.items = append(merged, sorted[j:]...)
+		return
+	}
+	for _, value := range values {
+		list.items.InsertSorted(value, list.compare)
+	}
+}
+
+func (list *SortedList[E]) Get(index int) E {
+	return list.items.Get(index)
+}
+
+func (list *SortedList[E]) First() (E, bool) {
+	return list.items.First()
+}
+
+func (list *SortedList[E]) Last() (E, bool) {
+	return list.items.Last()
+}
+
+func (list *SortedList[E]) Pop() (E, bool) {
+	return list.items.Pop()
+}
+
+func (list *SortedList[E]) Shift() (E, bool) {
+	return list.items.Shift()
+}
+
+// Contains reports whether the list has an element equal to the value according to the comparator
+func (list *SortedList[E]) Contains(value E) bool {
+	_, ok := list.items.BinarySearch(value, list.compare)
+	return ok
+}
+
+// IndexOf returns the index of the first element equal to the value according to the comparator, or -1
+func (list *SortedList[E]) IndexOf(value E) int {
+	index, ok := list.items.BinarySearch(value, list.compare)
+	if !ok {
+		return -1
+	}
+	return index
+}
+
+// LowerBound returns the index of the first element which is not less than the target
+func (list *SortedList[E]) LowerBound(target E) int {
+	return list.items.LowerBound(target, list.compare)
+}
+
+// UpperBound returns the index of the first element which is greater than the target
+func (list *SortedList[E]) UpperBound(target E) int {
+	return list.items.UpperBound(target, list.compare)
+}
+
+// Remove removes the elements equal to the value according to the comparator
+func (list *SortedList[E]) Remove(value E) {
+	from, to := list.LowerBound(value), list.UpperBound(value)
+	list.items.items = slices.Delete(list.items.items, from, to)
+}
+
+func (list *SortedList[E]) RemoveWhere(callback func(item E) bool) {
+	list.items.RemoveWhere(callback)
+}
+
+func (list *SortedList[E]) RemoveAt(index int) {
+	list.items.RemoveAt(index)
+}
+
+func (list *SortedList[E]) Clear() {
+	list.items.Clear()
+}
+
+func (list *SortedList[E]) Each(callback func(index int, value E) bool) {
+	list.items.Each(callback)
+}
+
+// Clone returns a new sorted list with a copy of the elements
+func (list *SortedList[E]) Clone() *SortedList[E] {
+	return &SortedList[E]{comparator: list.comparator, items: list.items.Clone()}
+}
+
+// ToList returns a list with a copy of the elements
+func (list *SortedList[E]) ToList() *List[E] {
+	return list.items.Clone()
+}
+
+// ToArray returns a copy of the elements
+func (list *SortedList[E]) ToArray() []E {
+	return list.items.ToArray()
+}
+
+func (list *SortedList[E]) ToJSON() ([]byte, error) {
+	return list.items.ToJSON()
+}
+
+func (list *SortedList[E]) MarshalJSON() ([]byte, error) {
+	return list.ToJSON()
+}
+
+// UnmarshalJSON replaces the elements of the list, it returns ErrNilComparator
+// when the list has no comparator, like the zero value
+func (list *SortedList[E]) UnmarshalJSON(data []byte) error {
+	if list.comparator == nil {
+		return ErrNilComparator
+	}
+	items := []E{}
+	if err := json.Unmarshal(data, &items); err != nil {
+		return err
+	}
+	slices.SortStableFunc(items, list.compare)
+	list.items = &List[E]{items: items}
+	return nil
+}
+
+func (list *SortedList[E]) String() string {
+	str := new(strings.Builder)
+	str.WriteString(fmt.Sprintf("SortedList[%T](len=%d)", *new(E), list.Count()))
+	str.WriteByte('{')
+	str.WriteByte('\n')
+	for index, value := range list.items.items {
+		str.WriteByte('\t')
+		if v, ok := any(value).(support.Stringable); ok {
+			str.WriteString(v.String())
+		} else {
+			str.WriteString(fmt.Sprintf("%v", value))
+		}
+		str.WriteByte(',')
+		str.WriteByte('\n')
+		if index >= 4 {
+			break
+		}
+	}
+	if list.Count() > 5 {
+		str.WriteString("\t...\n")
+	}
+	str.WriteByte('}')
+	return str.String()
+}
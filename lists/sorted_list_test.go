@@ -0,0 +1,98 @@
+package lists
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"regexp"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type _intComparator struct{}
+
+func (c _intComparator) Compare(a, b int) int {
+	return cmp.Compare(a, b)
+}
+
+func TestSortedList_Push(t *testing.T) {
+	list := NewSortedList[int](_intComparator{}, 5, 1, 3)
+	list.Push(4)
+	list.Push(2, 0, 6, 3)
+	assert.Equal(t, []int{0, 1, 2, 3, 3, 4, 5, 6}, list.ToArray())
+	assert.Equal(t, int64(8), list.Count())
+}
+
+func TestSortedList_Model(t *testing.T) {
+	rand := rand.New(rand.NewSource(1))
+	list := NewSortedList[int](_intComparator{})
+	model := []int{}
+	for round := 0; round < 500; round++ {
+		values := make([]int, rand.Intn(3)+1)
+		for i := range values {
+			values[i] = rand.Intn(50)
+		}
+		list.Push(values...)
+		model = append(model, values...)
+		if round%5 == 0 {
+			value := rand.Intn(50)
+			list.Remove(value)
+			model = slices.DeleteFunc(model, func(v int) bool {
+				return v == value
+			})
+		}
+	}
+	slices.Sort(model)
+	assert.Equal(t, model, list.ToArray())
+}
+
+func TestSortedList_Search(t *testing.T) {
+	list := NewSortedList[int](_intComparator{}, 1, 3, 3, 5)
+	assert.True(t, list.Contains(3))
+	assert.False(t, list.Contains(4))
+	assert.Equal(t, 1, list.IndexOf(3))
+	assert.Equal(t, -1, list.IndexOf(4))
+	assert.Equal(t, 1, list.LowerBound(3))
+	assert.Equal(t, 3, list.UpperBound(3))
+	list.Remove(3)
+	assert.Equal(t, []int{1, 5}, list.ToArray())
+	first, _ := list.First()
+	last, _ := list.Last()
+	assert.Equal(t, 1, first)
+	assert.Equal(t, 5, last)
+}
+
+func TestSortedList_Clone(t *testing.T) {
+	list := NewSortedList[int](_intComparator{}, 2, 1)
+	clone := list.Clone()
+	clone.Push(0)
+	converted := list.ToList()
+	converted.Push(-1)
+	assert.Equal(t, []int{1, 2}, list.ToArray())
+	assert.Equal(t, []int{0, 1, 2}, clone.ToArray())
+}
+
+func TestSortedList_JSON(t *testing.T) {
+	list := NewSortedList[int](_intComparator{}, 2, 1)
+	jsonBytes, err := json.Marshal(list)
+	assert.Nil(t, err)
+	assert.JSONEq(t, `[1,2]`, string(jsonBytes))
+
+	unmarshalled := NewSortedList[int](_intComparator{})
+	err = json.Unmarshal([]byte(`[3,1,2]`), unmarshalled)
+	assert.Nil(t, err)
+	assert.Equal(t, []int{1, 2, 3}, unmarshalled.ToArray())
+
+	var zero SortedList[int]
+	assert.ErrorIs(t, json.Unmarshal([]byte(`[1]`), &zero), ErrNilComparator)
+}
+
+func TestSortedList_String(t *testing.T) {
+	list := NewSortedList[int](_intComparator{}, 10, 9, 8, 7, 6, 5, 4, 3, 2, 1)
+	str := list.String()
+	pattern := regexp.MustCompile(fmt.Sprintf(`SortedList\[int\]\(len=%d\)\{\n\t1,\n\t2,\n\t3,\n\t4,\n\t5,\n\t(\.){3}\n\}`, list.Count()))
+	assert.True(t, pattern.Match([]byte(str)))
+}
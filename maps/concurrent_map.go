@@ -0,0 +1,473 @@
+package maps
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"hash/maphash"
+	"math"
+	"math/bits"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/gopi-frame/contract/support"
+)
+
+// DefaultShardCount number of shards of a concurrent map created with a non-positive count
+const DefaultShardCount = 32
+
+var hashSeed = maphash.MakeSeed()
+
+// hashKey hashes the key with a fast path for the common key kinds,
+// other keys are hashed by walking their value so that equal keys share a hash
+func hashKey[K comparable](key K) uint64 {
+	switch k := any(key).(type) {
+	case string:
+		return maphash.String(hashSeed, k)
+	case int:
+		return mix(uint64(k))
+	case int8:
+		return mix(uint64(k))
+	case int16:
+		return mix(uint64(k))
+	case int32:
+		return mix(uint64(k))
+	case int64:
+		return mix(uint64(k))
+	case uint:
+		return mix(uint64(k))
+	case uint8:
+		return mix(uint64(k))
+	case uint16:
+		return mix(uint64(k))
+	case uint32:
+		return mix(uint64(k))
+	case uint64:
+		return mix(k)
+	case uintptr:
+		return mix(uint64(k))
+	case float32:
+		return mix(floatBits(float64(k)))
+	case float64:
+		return mix(floatBits(k))
+	case bool:
+		if k {
+			return 1
+		}
+		return 0
+	}
+	h := new(maphash.Hash)
+	h.SetSeed(hashSeed)
+	hashValue(h, reflect.ValueOf(&key).Elem())
+	return h.Sum64()
+}
+
+// floatBits returns the bits of the float with the zeros, which are equal, sharing their bits
+func floatBits(f float64) uint64 {
+	if f == 0 {
+		return 0
+	}
+	return math.Float64bits(f)
+}
+
+// hashValue writes the value to the hash as the == operator sees it: pointers,
+// channels and interfaces by identity rather than by what they point to, structs
+// and arrays field by field
+func hashValue(h *maphash.Hash, v reflect.Value) {
+	var buf [8]byte
+	writeUint64 := func(x uint64) {
+		binary.LittleEndian.PutUint64(buf[:], x)
+		_, _ = h.Write(buf[:])
+	}
+	switch v.Kind() {
+	case reflect.Bool:
+		if v.Bool() {
+			writeUint64(1)
+		} else {
+			writeUint64(0)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		writeUint64(uint64(v.Int()))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		writeUint64(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		writeUint64(floatBits(v.Float()))
+	case reflect.Complex64, reflect.Complex128:
+		writeUint64(floatBits(real(v.Complex())))
+		writeUint64(floatBits(imag(v.Complex())))
+	case reflect.String:
+		writeUint64(uint64(v.Len()))
+		_, _ = h.WriteString(v.String())
+	case reflect.Pointer, reflect.Chan, reflect.UnsafePointer:
+		writeUint64(uint64(v.Pointer()))
+	case reflect.Interface:
+		if v.IsNil() {
+			writeUint64(0)
+			return
+		}
+		hashValue(h, v.Elem())
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			hashValue(h, v.Index(i))
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			// blank fields are ignored by ==
+			if v.Type().Field(i).Name != "_" {
+				hashValue(h, v.Field(i))
+			}
+		}
+	default:
+		panic(fmt.Sprintf("maps: unhashable key type %s", v.Type()))
+	}
+}
+
+// mix spreads the bits of an integer key so that consecutive keys land on different shards
+func mix(x uint64) uint64 {
+	x ^= x >> 33
+	x *= 0xff51afd7ed558ccd
+	x ^= x >> 33
+	x *= 0xc4ceb9fe1a85ec53
+	x ^= x >> 33
+	return x
+}
+
+type concurrentMapShard[K comparable, V any] struct {
+	sync.RWMutex
+	items map[K]V
+}
+
+// NewConcurrentMap new concurrent map with the given number of shards,
+// it is rounded up to a power of two
+func NewConcurrentMap[K comparable, V any](shards int) *ConcurrentMap[K, V] {
+	return NewConcurrentMapFunc[K, V](shards, hashKey[K])
+}
+
+// NewConcurrentMapFunc new concurrent map which distributes the keys over the shards with the hash function
+func NewConcurrentMapFunc[K comparable, V any](shards int, hash func(key K) uint64) *ConcurrentMap[K, V] {
+	if shards <= 0 {
+		shards = DefaultShardCount
+	}
+	shards = 1 << bits.Len(uint(shards-1))
+	m := new(ConcurrentMap[K, V])
+	m.hash = hash
+	m.shards = make([]*concurrentMapShard[K, V], shards)
+	for index := range m.shards {
+		m.shards[index] = &concurrentMapShard[K, V]{items: make(map[K]V)}
+	}
+	return m
+}
+
+// ConcurrentMap concurrent map
+//
+// The keys are split by hash over shards which have their own lock, so writers
+// of different shards do not contend. The operations on a single key are
+// atomic, the operations on the whole map visit the shards one at a time and
+// are weakly consistent: they never lock all the shards at once, and may or
+// may not observe the writes made while they run.
+type ConcurrentMap[K comparable, V any] struct {
+	hash   func(key K) uint64
+	shards []*concurrentMapShard[K, V]
+}
+
+func (m *ConcurrentMap[K, V]) shard(key K) *concurrentMapShard[K, V] {
+	return m.shards[m.hash(key)&uint64(len(m.shards)-1)]
+}
+
+func (m *ConcurrentMap[K, V]) Count() int64 {
+	count := int64(0)
+	for _, shard := range m.shards {
+		shard.RLock()
+		count += int64(len(shard.items))
+		shard.RUnlock()
+	}
+	return count
+}
+
+func (m *ConcurrentMap[K, V]) IsEmpty() bool {
+	for _, shard := range m.shards {
+		shard.RLock()
+		count := len(shard.items)
+		shard.RUnlock()
+		if count > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func (m *ConcurrentMap[K, V]) IsNotEmpty() bool {
+	return !m.IsEmpty()
+}
+
+func (m *ConcurrentMap[K, V]) Get(key K) (V, bool) {
+	shard := m.shard(key)
+	shard.RLock()
+	defer shard.RUnlock()
+	v, ok := shard.items[key]
+	return v, ok
+}
+
+func (m *ConcurrentMap[K, V]) GetOr(key K, value V) V {
+	if v, ok := m.Get(key); ok {
+		return v
+	}
+	return value
+}
+
+func (m *ConcurrentMap[K, V]) Set(key K, value V) {
+	shard := m.shard(key)
+	shard.Lock()
+	defer shard.Unlock()
+	shard.items[key] = value
+}
+
+func (m *ConcurrentMap[K, V]) Remove(key K) {
+	shard := m.shard(key)
+	shard.Lock()
+	defer shard.Unlock()
+	delete(shard.items, key)
+}
+
+// GetOrSet returns the value of the key if present, otherwise it sets and returns the given value.
+// The result is true when the value was already present.
+func (m *ConcurrentMap[K, V]) GetOrSet(key K, value V) (V, bool) {
+	shard := m.shard(key)
+	shard.Lock()
+	defer shard.Unlock()
+	if v, ok := shard.items[key]; ok {
+		return v, true
+	}
+	shard.items[key] = value
+	return value, false
+}
+
+// GetAndRemove removes the key and returns its previous value
+func (m *ConcurrentMap[K, V]) GetAndRemove(key K) (V, bool) {
+	shard := m.shard(key)
+	shard.Lock()
+	defer shard.Unlock()
+	v, ok := shard.items[key]
+	if ok {
+		delete(shard.items, key)
+	}
+	return v, ok
+}
+
+// Compute sets the key to the value returned by the callback, which receives the current
+// value and whether it is present; the key is removed when the callback returns false.
+// The callback runs with the shard locked, it must not access the map.
+func (m *ConcurrentMap[K, V]) Compute(key K, callback func(value V, ok bool) (V, bool)) (V, bool) {
+	shard := m.shard(key)
+	shard.Lock()
+	defer shard.Unlock()
+	v, ok := shard.items[key]
+	v, ok = callback(v, ok)
+	if !ok {
+		delete(shard.items, key)
+		return *new(V), false
+	}
+	shard.items[key] = v
+	return v, true
+}
+
+// ComputeIfAbsent sets the key to the value returned by the callback when the key is not present,
+// and returns the value of the key. The callback runs with the shard locked, it must not access the map.
+func (m *ConcurrentMap[K, V]) ComputeIfAbsent(key K, callback func() V) V {
+	shard := m.shard(key)
+	shard.Lock()
+	defer shard.Unlock()
+	if v, ok := shard.items[key]; ok {
+		return v
+	}
+	v := callback()
+	shard.items[key] = v
+	return v
+}
+
+// ComputeIfPresent sets the key to the value returned by the callback when the key is present,
+// the key is removed when the callback returns false. The callback runs with the shard locked,
+// it must not access the map.
+func (m *ConcurrentMap[K, V]) ComputeIfPresent(key K, callback func(value V) (V, bool)) (V, bool) {
+	shard := m.shard(key)
+	shard.Lock()
+	defer shard.Unlock()
+	v, ok := shard.items[key]
+	if !ok {
+		return *new(V), false
+	}
+	if v, ok = callback(v); !ok {
+		delete(shard.items, key)
+		return *new(V), false
+	}
+	shard.items[key] = v
+	return v, true
+}
+
+// CompareAndSwap sets the key to the new value if its current value is deeply equal to the old one
+func (m *ConcurrentMap[K, V]) CompareAndSwap(key K, old, new V) bool {
+	shard := m.shard(key)
+	shard.Lock()
+	defer shard.Unlock()
+	v, ok := shard.items[key]
+	if !ok || !reflect.DeepEqual(v, old) {
+		return false
+	}
+	shard.items[key] = new
+	return true
+}
+
+// CompareAndDelete removes the key if its current value is deeply equal to the old one
+func (m *ConcurrentMap[K, V]) CompareAndDelete(key K, old V) bool {
+	shard := m.shard(key)
+	shard.Lock()
+	defer shard.Unlock()
+	v, ok := shard.items[key]
+	if !ok || !reflect.DeepEqual(v, old) {
+		return false
+	}
+	delete(shard.items, key)
+	return true
+}
+
+// Range calls the callback for the entries until it returns false.
+// The entries of a shard are copied under its lock and the callback runs
+// without any lock held, so it may access the map.
+func (m *ConcurrentMap[K, V]) Range(callback func(key K, value V) bool) {
+	type entry struct {
+		key   K
+		value V
+	}
+	entries := []entry{}
+	for _, shard := range m.shards {
+		entries = entries[:0]
+		shard.RLock()
+		for key, value := range shard.items {
+			entries = append(entries, entry{key, value})
+		}
+		shard.RUnlock()
+		for _, e := range entries {
+			if !callback(e.key, e.value) {
+				return
+			}
+		}
+	}
+}
+
+func (m *ConcurrentMap[K, V]) Each(callback func(key K, value V) bool) {
+	m.Range(callback)
+}
+
+func (m *ConcurrentMap[K, V]) Keys() []K {
+	keys := []K{}
+	m.Range(func(key K, _ V) bool {
+		keys = append(keys, key)
+		return true
+	})
+	return keys
+}
+
+func (m *ConcurrentMap[K, V]) Values() []V {
+	values := []V{}
+	m.Range(func(_ K, value V) bool {
+		values = append(values, value)
+		return true
+	})
+	return values
+}
+
+func (m *ConcurrentMap[K, V]) Clear() {
+	for _, shard := range m.shards {
+		shard.Lock()
+		shard.items = make(map[K]V)
+		shard.Unlock()
+	}
+}
+
+func (m *ConcurrentMap[K, V]) ContainsKey(key K) bool {
+	_, ok := m.Get(key)
+	return ok
+}
+
+func (m *ConcurrentMap[K, V]) Contains(value V) bool {
+	return m.ContainsWhere(func(v V) bool {
+		return reflect.DeepEqual(v, value)
+	})
+}
+
+func (m *ConcurrentMap[K, V]) ContainsWhere(callback func(value V) bool) bool {
+	found := false
+	m.Range(func(_ K, value V) bool {
+		found = callback(value)
+		return !found
+	})
+	return found
+}
+
+func (m *ConcurrentMap[K, V]) ToJSON() ([]byte, error) {
+	return json.Marshal(m.ToMap())
+}
+
+func (m *ConcurrentMap[K, V]) MarshalJSON() ([]byte, error) {
+	return m.ToJSON()
+}
+
+// UnmarshalJSON replaces the entries of the map
+func (m *ConcurrentMap[K, V]) UnmarshalJSON(data []byte) error {
+	values := map[K]V{}
+	if err := json.Unmarshal(data, &values); err != nil {
+		return err
+	}
+	m.Clear()
+	for key, value := range values {
+		m.Set(key, value)
+	}
+	return nil
+}
+
+// ToMap returns a copy of the entries
+func (m *ConcurrentMap[K, V]) ToMap() map[K]V {
+	items := make(map[K]V)
+	m.Range(func(key K, value V) bool {
+		items[key] = value
+		return true
+	})
+	return items
+}
+
+func (m *ConcurrentMap[K, V]) String() string {
+	str := new(strings.Builder)
+	items := m.ToMap()
+	str.WriteString(fmt.Sprintf("ConcurrentMap[%T, %T](len=%d)", *new(K), *new(V), len(items)))
+	str.WriteByte('{')
+	str.WriteByte('\n')
+	for k, v := range items {
+		str.WriteByte('\t')
+		if key, ok := any(k).(support.Stringable); ok {
+			str.WriteString(key.String())
+		} else {
+			str.WriteString(fmt.Sprintf("%v", k))
+		}
+		str.WriteByte(':')
+		str.WriteByte(' ')
+		if value, ok := any(v).(support.Stringable); ok {
+			str.WriteString(value.String())
+		} else {
+			str.WriteString(fmt.Sprintf("%v", v))
+		}
+		str.WriteByte(',')
+		str.WriteByte('\n')
+	}
+	str.WriteByte('}')
+	return str.String()
+}
+
+func (m *ConcurrentMap[K, V]) Clone() *ConcurrentMap[K, V] {
+	newMap := NewConcurrentMapFunc[K, V](len(m.shards), m.hash)
+	m.Range(func(key K, value V) bool {
+		newMap.Set(key, value)
+		return true
+	})
+	return newMap
+}
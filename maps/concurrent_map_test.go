@@ -0,0 +1,246 @@
+package maps
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConcurrentMap_Shards(t *testing.T) {
+	assert.Len(t, NewConcurrentMap[int, int](0).shards, DefaultShardCount)
+	assert.Len(t, NewConcurrentMap[int, int](1).shards, 1)
+	assert.Len(t, NewConcurrentMap[int, int](5).shards, 8)
+	m := NewConcurrentMap[int, int](8)
+	for i := 0; i < 1000; i++ {
+		m.Set(i, i)
+	}
+	for _, shard := range m.shards {
+		assert.NotEmpty(t, shard.items)
+	}
+}
+
+func TestConcurrentMap_Get(t *testing.T) {
+	m := NewConcurrentMap[string, int](4)
+	m.Set("a", 1)
+	m.Set("b", 2)
+	v, ok := m.Get("a")
+	assert.True(t, ok)
+	assert.Equal(t, 1, v)
+	assert.Equal(t, 3, m.GetOr("c", 3))
+	assert.Equal(t, int64(2), m.Count())
+	m.Remove("a")
+	assert.False(t, m.ContainsKey("a"))
+	assert.True(t, m.Contains(2))
+	m.Clear()
+	assert.True(t, m.IsEmpty())
+}
+
+func TestConcurrentMap_StructKey(t *testing.T) {
+	type key struct {
+		a int
+		b string
+	}
+	m := NewConcurrentMap[key, int](4)
+	m.Set(key{1, "a"}, 1)
+	v, ok := m.Get(key{1, "a"})
+	assert.True(t, ok)
+	assert.Equal(t, 1, v)
+	_, ok = m.Get(key{1, "b"})
+	assert.False(t, ok)
+}
+
+func TestConcurrentMap_PointerKey(t *testing.T) {
+	type value struct {
+		n int
+	}
+	type key struct {
+		p *value
+		s string
+	}
+	k := &value{1}
+	m := NewConcurrentMap[*value, int](16)
+	m.Set(k, 1)
+	// pointers are hashed by address, not by what they point to
+	k.n = 2
+	assert.Equal(t, 1, m.GetOr(k, 0))
+	m.Set(k, 2)
+	assert.Equal(t, int64(1), m.Count())
+	assert.False(t, m.ContainsKey(&value{2}))
+
+	structKeys := NewConcurrentMap[key, int](16)
+	structKeys.Set(key{k, "a"}, 1)
+	k.n = 3
+	assert.Equal(t, 1, structKeys.GetOr(key{k, "a"}, 0))
+
+	anyKeys := NewConcurrentMap[any, int](16)
+	anyKeys.Set(k, 1)
+	anyKeys.Set([2]float64{0, 1}, 2)
+	k.n = 4
+	assert.Equal(t, 1, anyKeys.GetOr(k, 0))
+	assert.Equal(t, 2, anyKeys.GetOr([2]float64{math.Copysign(0, -1), 1}, 0))
+}
+
+func TestConcurrentMap_GetOrSet(t *testing.T) {
+	m := NewConcurrentMap[string, int](4)
+	v, loaded := m.GetOrSet("a", 1)
+	assert.False(t, loaded)
+	assert.Equal(t, 1, v)
+	v, loaded = m.GetOrSet("a", 2)
+	assert.True(t, loaded)
+	assert.Equal(t, 1, v)
+	v, ok := m.GetAndRemove("a")
+	assert.True(t, ok)
+	assert.Equal(t, 1, v)
+	assert.True(t, m.IsEmpty())
+}
+
+func TestConcurrentMap_Compute(t *testing.T) {
+	m := NewConcurrentMap[string, int](4)
+	increment := func(value int, ok bool) (int, bool) {
+		return value + 1, true
+	}
+	m.Compute("a", increment)
+	v, ok := m.Compute("a", increment)
+	assert.True(t, ok)
+	assert.Equal(t, 2, v)
+	_, ok = m.Compute("a", func(value int, ok bool) (int, bool) {
+		return 0, false
+	})
+	assert.False(t, ok)
+	assert.False(t, m.ContainsKey("a"))
+
+	calls := 0
+	absent := func() int {
+		calls++
+		return 10
+	}
+	assert.Equal(t, 10, m.ComputeIfAbsent("b", absent))
+	assert.Equal(t, 10, m.ComputeIfAbsent("b", absent))
+	assert.Equal(t, 1, calls)
+
+	_, ok = m.ComputeIfPresent("c", func(value int) (int, bool) {
+		return value, true
+	})
+	assert.False(t, ok)
+	assert.False(t, m.ContainsKey("c"))
+	v, ok = m.ComputeIfPresent("b", func(value int) (int, bool) {
+		return value * 2, true
+	})
+	assert.True(t, ok)
+	assert.Equal(t, 20, v)
+	_, ok = m.ComputeIfPresent("b", func(value int) (int, bool) {
+		return value, false
+	})
+	assert.False(t, ok)
+	assert.True(t, m.IsEmpty())
+}
+
+func TestConcurrentMap_CompareAndSwap(t *testing.T) {
+	m := NewConcurrentMap[string, []int](4)
+	assert.False(t, m.CompareAndSwap("a", nil, []int{1}))
+	m.Set("a", []int{1})
+	assert.False(t, m.CompareAndSwap("a", []int{2}, []int{3}))
+	assert.True(t, m.CompareAndSwap("a", []int{1}, []int{2}))
+	assert.False(t, m.CompareAndDelete("a", []int{1}))
+	assert.True(t, m.CompareAndDelete("a", []int{2}))
+	assert.True(t, m.IsEmpty())
+}
+
+func TestConcurrentMap_Range(t *testing.T) {
+	m := NewConcurrentMap[int, int](4)
+	for i := 0; i < 100; i++ {
+		m.Set(i, i)
+	}
+	count := 0
+	m.Range(func(key, value int) bool {
+		count++
+		return count < 10
+	})
+	assert.Equal(t, 10, count)
+	// the callback may write to the map
+	m.Range(func(key, value int) bool {
+		m.Remove(key)
+		return true
+	})
+	assert.True(t, m.IsEmpty())
+}
+
+func TestConcurrentMap_Concurrent(t *testing.T) {
+	const workers, perWorker = 8, 1000
+	m := NewConcurrentMap[int, int](16)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < perWorker; i++ {
+				m.Compute(i%100, func(value int, ok bool) (int, bool) {
+					return value + 1, true
+				})
+				m.Range(func(key, value int) bool {
+					return key < 50
+				})
+			}
+		}()
+	}
+	wg.Wait()
+	for i := 0; i < 100; i++ {
+		assert.Equal(t, workers*perWorker/100, m.GetOr(i, 0))
+	}
+}
+
+func TestConcurrentMap_JSON(t *testing.T) {
+	m := NewConcurrentMap[int, int](4)
+	err := json.Unmarshal([]byte(`{"0":0,"1":1,"2":2}`), m)
+	assert.Nil(t, err)
+	assert.Equal(t, map[int]int{0: 0, 1: 1, 2: 2}, m.ToMap())
+	jsonBytes, err := json.Marshal(m)
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{"0":0,"1":1,"2":2}`, string(jsonBytes))
+	keys := m.Keys()
+	sort.Ints(keys)
+	assert.Equal(t, []int{0, 1, 2}, keys)
+	assert.Equal(t, m.ToMap(), m.Clone().ToMap())
+
+	// the entries are replaced
+	err = json.Unmarshal([]byte(`{"3":3}`), m)
+	assert.Nil(t, err)
+	assert.Equal(t, map[int]int{3: 3}, m.ToMap())
+	assert.Equal(t, int64(1), m.Count())
+}
+
+func TestConcurrentMap_String(t *testing.T) {
+	m := NewConcurrentMap[int, int](4)
+	m.Set(0, 0)
+	m.Set(1, 1)
+	m.Set(2, 2)
+	str := m.String()
+	pattern := regexp.MustCompile(fmt.Sprintf(`ConcurrentMap\[int, int\]\(len=%d\)\{\n(\t\d+:\s\d+,\n)+\}`, m.Count()))
+	assert.True(t, pattern.Match([]byte(str)))
+}
+
+func BenchmarkMap_ParallelSet(b *testing.B) {
+	m := NewMap[int, int]()
+	b.RunParallel(func(pb *testing.PB) {
+		for i := 0; pb.Next(); i++ {
+			m.Lock()
+			m.Set(i%1024, i)
+			m.Unlock()
+		}
+	})
+}
+
+func BenchmarkConcurrentMap_ParallelSet(b *testing.B) {
+	m := NewConcurrentMap[int, int](0)
+	b.RunParallel(func(pb *testing.PB) {
+		for i := 0; pb.Next(); i++ {
+			m.Set(i%1024, i)
+		}
+	})
+}
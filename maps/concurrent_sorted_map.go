@@ -0,0 +1,382 @@
+package maps
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/bits"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/gopi-frame/contract/support"
+	"github.com/gopi-frame/support/internal/skiplist"
+)
+
+type concurrentSortedMapShard[K, V any] struct {
+	sync.RWMutex
+	items *skiplist.List[sortedEntry[K, V]]
+}
+
+// NewConcurrentSortedMap new concurrent sorted map with the given number of shards,
+// it is rounded up to a power of two. The keys are distributed over the shards by
+// their hash, so the comparator must return 0 only for keys which are ==, use
+// NewConcurrentSortedMapFunc for other comparators.
+func NewConcurrentSortedMap[K comparable, V any](comparator support.Comparator[K], shards int) *ConcurrentSortedMap[K, V] {
+	return NewConcurrentSortedMapFunc[K, V](comparator, shards, hashKey[K])
+}
+
+// NewConcurrentSortedMapFunc new concurrent sorted map which distributes the keys over the shards
+// with the hash function, which must return the same hash for the keys the comparator finds equal
+func NewConcurrentSortedMapFunc[K comparable, V any](comparator support.Comparator[K], shards int, hash func(key K) uint64) *ConcurrentSortedMap[K, V] {
+	if shards <= 0 {
+		shards = DefaultShardCount
+	}
+	shards = 1 << bits.Len(uint(shards-1))
+	m := new(ConcurrentSortedMap[K, V])
+	m.comparator = comparator
+	m.hash = hash
+	m.shards = make([]*concurrentSortedMapShard[K, V], shards)
+	for index := range m.shards {
+		m.shards[index] = &concurrentSortedMapShard[K, V]{items: skiplist.New(m.compare)}
+	}
+	return m
+}
+
+// ConcurrentSortedMap concurrent map sorted by key
+//
+// The keys are split by hash over shards which hold a skip list and have their own
+// lock, as with ConcurrentMap. The operations on a single key are atomic and
+// O(log n); the ordered operations merge the shards, which are read a few entries
+// at a time, so they are weakly consistent and may or may not observe the writes
+// made while they run.
+type ConcurrentSortedMap[K comparable, V any] struct {
+	comparator support.Comparator[K]
+	hash       func(key K) uint64
+	shards     []*concurrentSortedMapShard[K, V]
+}
+
+func (m *ConcurrentSortedMap[K, V]) compare(a, b sortedEntry[K, V]) int {
+	return m.comparator.Compare(a.key, b.key)
+}
+
+func (m *ConcurrentSortedMap[K, V]) shard(key K) *concurrentSortedMapShard[K, V] {
+	return m.shards[m.hash(key)&uint64(len(m.shards)-1)]
+}
+
+func (m *ConcurrentSortedMap[K, V]) Comparator() support.Comparator[K] {
+	return m.comparator
+}
+
+func (m *ConcurrentSortedMap[K, V]) Count() int64 {
+	count := int64(0)
+	for _, shard := range m.shards {
+		shard.RLock()
+		count += int64(shard.items.Len())
+		shard.RUnlock()
+	}
+	return count
+}
+
+func (m *ConcurrentSortedMap[K, V]) IsEmpty() bool {
+	for _, shard := range m.shards {
+		shard.RLock()
+		count := shard.items.Len()
+		shard.RUnlock()
+		if count > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func (m *ConcurrentSortedMap[K, V]) IsNotEmpty() bool {
+	return !m.IsEmpty()
+}
+
+func (m *ConcurrentSortedMap[K, V]) Get(key K) (V, bool) {
+	shard := m.shard(key)
+	shard.RLock()
+	defer shard.RUnlock()
+	entry, ok := shard.items.Find(sortedEntry[K, V]{key: key})
+	return entry.value, ok
+}
+
+func (m *ConcurrentSortedMap[K, V]) GetOr(key K, value V) V {
+	if v, ok := m.Get(key); ok {
+		return v
+	}
+	return value
+}
+
+func (m *ConcurrentSortedMap[K, V]) Set(key K, value V) {
+	shard := m.shard(key)
+	shard.Lock()
+	defer shard.Unlock()
+	shard.items.Insert(sortedEntry[K, V]{key, value})
+}
+
+func (m *ConcurrentSortedMap[K, V]) Remove(key K) {
+	shard := m.shard(key)
+	shard.Lock()
+	defer shard.Unlock()
+	shard.items.Remove(sortedEntry[K, V]{key: key})
+}
+
+func (m *ConcurrentSortedMap[K, V]) ContainsKey(key K) bool {
+	_, ok := m.Get(key)
+	return ok
+}
+
+func (m *ConcurrentSortedMap[K, V]) Contains(value V) bool {
+	return m.ContainsWhere(func(v V) bool {
+		return reflect.DeepEqual(v, value)
+	})
+}
+
+func (m *ConcurrentSortedMap[K, V]) ContainsWhere(callback func(value V) bool) bool {
+	found := false
+	m.Each(func(_ K, value V) bool {
+		found = callback(value)
+		return !found
+	})
+	return found
+}
+
+// best returns the entry which the shards find for the key and which is preferred by the better function
+func (m *ConcurrentSortedMap[K, V]) best(
+	find func(items *skiplist.List[sortedEntry[K, V]]) (sortedEntry[K, V], bool),
+	better func(a, b sortedEntry[K, V]) bool,
+) (K, V, bool) {
+	var result sortedEntry[K, V]
+	found := false
+	for _, shard := range m.shards {
+		shard.RLock()
+		entry, ok := find(shard.items)
+		shard.RUnlock()
+		if ok && (!found || better(entry, result)) {
+			result, found = entry, true
+		}
+	}
+	return result.key, result.value, found
+}
+
+func (m *ConcurrentSortedMap[K, V]) less(a, b sortedEntry[K, V]) bool {
+	return m.compare(a, b) < 0
+}
+
+func (m *ConcurrentSortedMap[K, V]) greater(a, b sortedEntry[K, V]) bool {
+	return m.compare(a, b) > 0
+}
+
+// First returns the entry with the lowest key
+func (m *ConcurrentSortedMap[K, V]) First() (K, V, bool) {
+	return m.best((*skiplist.List[sortedEntry[K, V]]).First, m.less)
+}
+
+// Last returns the entry with the highest key
+func (m *ConcurrentSortedMap[K, V]) Last() (K, V, bool) {
+	return m.best((*skiplist.List[sortedEntry[K, V]]).Last, m.greater)
+}
+
+// Floor returns the entry with the greatest key less than or equal to the key
+func (m *ConcurrentSortedMap[K, V]) Floor(key K) (K, V, bool) {
+	return m.best(func(items *skiplist.List[sortedEntry[K, V]]) (sortedEntry[K, V], bool) {
+		return items.Floor(sortedEntry[K, V]{key: key})
+	}, m.greater)
+}
+
+// Ceiling returns the entry with the least key greater than or equal to the key
+func (m *ConcurrentSortedMap[K, V]) Ceiling(key K) (K, V, bool) {
+	return m.best(func(items *skiplist.List[sortedEntry[K, V]]) (sortedEntry[K, V], bool) {
+		return items.Ceiling(sortedEntry[K, V]{key: key})
+	}, m.less)
+}
+
+// sortedScanBatch number of entries copied from a shard at a time by the ordered operations
+const sortedScanBatch = 64
+
+// sortedCursor reads the entries of a shard in order, one batch at a time
+type sortedCursor[K, V any] struct {
+	shard   *concurrentSortedMapShard[K, V]
+	entries []sortedEntry[K, V]
+	next    int
+}
+
+// fill copies the next batch of entries of the cursor with a key in [from, to), after the last entry read;
+// it returns false when there are none left
+func (m *ConcurrentSortedMap[K, V]) fill(cursor *sortedCursor[K, V], from, to *K) bool {
+	resume := len(cursor.entries) > 0
+	var last sortedEntry[K, V]
+	if resume {
+		last = cursor.entries[len(cursor.entries)-1]
+	}
+	cursor.entries = cursor.entries[:0]
+	cursor.next = 0
+	visit := func(entry sortedEntry[K, V]) bool {
+		if to != nil && m.comparator.Compare(entry.key, *to) >= 0 {
+			return false
+		}
+		if !resume || m.compare(entry, last) > 0 {
+			cursor.entries = append(cursor.entries, entry)
+		}
+		return len(cursor.entries) < sortedScanBatch
+	}
+	cursor.shard.RLock()
+	switch {
+	case resume:
+		cursor.shard.items.Ascend(last, visit)
+	case from != nil:
+		cursor.shard.items.Ascend(sortedEntry[K, V]{key: *from}, visit)
+	default:
+		cursor.shard.items.Each(func(_ int, entry sortedEntry[K, V]) bool {
+			return visit(entry)
+		})
+	}
+	cursor.shard.RUnlock()
+	return len(cursor.entries) > 0
+}
+
+// scan calls the callback for the entries with a key in [from, to), in key order, until it returns false,
+// a nil bound is unbounded. The shards are merged with a heap of cursors ordered by their next entry.
+func (m *ConcurrentSortedMap[K, V]) scan(from, to *K, callback func(key K, value V) bool) {
+	cursors := make([]*sortedCursor[K, V], 0, len(m.shards))
+	for _, shard := range m.shards {
+		cursor := &sortedCursor[K, V]{shard: shard}
+		if m.fill(cursor, from, to) {
+			cursors = append(cursors, cursor)
+		}
+	}
+	less := func(i, j int) bool {
+		return m.compare(cursors[i].entries[cursors[i].next], cursors[j].entries[cursors[j].next]) < 0
+	}
+	down := func(index int) {
+		for {
+			swapIndex := index*2 + 1
+			if swapIndex >= len(cursors) {
+				return
+			}
+			if right := swapIndex + 1; right < len(cursors) && less(right, swapIndex) {
+				swapIndex = right
+			}
+			if !less(swapIndex, index) {
+				return
+			}
+			cursors[index], cursors[swapIndex] = cursors[swapIndex], cursors[index]
+			index = swapIndex
+		}
+	}
+	for index := len(cursors)/2 - 1; index >= 0; index-- {
+		down(index)
+	}
+	for len(cursors) > 0 {
+		cursor := cursors[0]
+		entry := cursor.entries[cursor.next]
+		cursor.next++
+		if !callback(entry.key, entry.value) {
+			return
+		}
+		if cursor.next == len(cursor.entries) && !m.fill(cursor, nil, to) {
+			cursors[0] = cursors[len(cursors)-1]
+			cursors = cursors[:len(cursors)-1]
+		}
+		down(0)
+	}
+}
+
+// Range calls the callback for the entries with a key in [from, to), in key order, until it returns false.
+// The callback runs without any lock held, so it may access the map.
+func (m *ConcurrentSortedMap[K, V]) Range(from, to K, callback func(key K, value V) bool) {
+	m.scan(&from, &to, callback)
+}
+
+// Each calls the callback for the entries, in key order, until it returns false.
+// The callback runs without any lock held, so it may access the map.
+func (m *ConcurrentSortedMap[K, V]) Each(callback func(key K, value V) bool) {
+	m.scan(nil, nil, callback)
+}
+
+// Keys returns the keys in order
+func (m *ConcurrentSortedMap[K, V]) Keys() []K {
+	keys := []K{}
+	m.Each(func(key K, _ V) bool {
+		keys = append(keys, key)
+		return true
+	})
+	return keys
+}
+
+// Values returns the values in key order
+func (m *ConcurrentSortedMap[K, V]) Values() []V {
+	values := []V{}
+	m.Each(func(_ K, value V) bool {
+		values = append(values, value)
+		return true
+	})
+	return values
+}
+
+func (m *ConcurrentSortedMap[K, V]) Clear() {
+	for _, shard := range m.shards {
+		shard.Lock()
+		shard.items.Clear()
+		shard.Unlock()
+	}
+}
+
+// ToMap returns a copy of the entries
+func (m *ConcurrentSortedMap[K, V]) ToMap() map[K]V {
+	items := make(map[K]V)
+	m.Each(func(key K, value V) bool {
+		items[key] = value
+		return true
+	})
+	return items
+}
+
+func (m *ConcurrentSortedMap[K, V]) ToJSON() ([]byte, error) {
+	return json.Marshal(m.ToMap())
+}
+
+func (m *ConcurrentSortedMap[K, V]) MarshalJSON() ([]byte, error) {
+	return m.ToJSON()
+}
+
+// UnmarshalJSON replaces the entries of the map, it keeps the comparator of the map
+func (m *ConcurrentSortedMap[K, V]) UnmarshalJSON(data []byte) error {
+	values := map[K]V{}
+	if err := json.Unmarshal(data, &values); err != nil {
+		return err
+	}
+	m.Clear()
+	for key, value := range values {
+		m.Set(key, value)
+	}
+	return nil
+}
+
+func (m *ConcurrentSortedMap[K, V]) String() string {
+	str := new(strings.Builder)
+	str.WriteString(fmt.Sprintf("ConcurrentSortedMap[%T, %T](len=%d)", *new(K), *new(V), m.Count()))
+	str.WriteByte('{')
+	str.WriteByte('\n')
+	m.Each(func(k K, v V) bool {
+		str.WriteByte('\t')
+		if key, ok := any(k).(support.Stringable); ok {
+			str.WriteString(key.String())
+		} else {
+			str.WriteString(fmt.Sprintf("%v", k))
+		}
+		str.WriteByte(':')
+		str.WriteByte(' ')
+		if value, ok := any(v).(support.Stringable); ok {
+			str.WriteString(value.String())
+		} else {
+			str.WriteString(fmt.Sprintf("%v", v))
+		}
+		str.WriteByte(',')
+		str.WriteByte('\n')
+		return true
+	})
+	str.WriteByte('}')
+	return str.String()
+}
@@ -0,0 +1,178 @@
+package maps
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type _caseInsensitiveComparator struct{}
+
+func (c _caseInsensitiveComparator) Compare(a, b string) int {
+	return strings.Compare(strings.ToLower(a), strings.ToLower(b))
+}
+
+func TestConcurrentSortedMap_Shards(t *testing.T) {
+	assert.Len(t, NewConcurrentSortedMap[int, int](_intComparator{}, 0).shards, DefaultShardCount)
+	assert.Len(t, NewConcurrentSortedMap[int, int](_intComparator{}, 5).shards, 8)
+	m := NewConcurrentSortedMap[int, int](_intComparator{}, 8)
+	for i := 99; i >= 0; i-- {
+		m.Set(i, i*i)
+	}
+	for _, shard := range m.shards {
+		assert.Greater(t, shard.items.Len(), 0)
+	}
+	keys := m.Keys()
+	assert.Len(t, keys, 100)
+	for i, key := range keys {
+		assert.Equal(t, i, key)
+	}
+	assert.Equal(t, 81, m.GetOr(9, 0))
+}
+
+func TestConcurrentSortedMap_Get(t *testing.T) {
+	m := NewConcurrentSortedMap[int, string](_intComparator{}, 4)
+	m.Set(1, "a")
+	m.Set(1, "b")
+	m.Set(2, "c")
+	assert.Equal(t, int64(2), m.Count())
+	assert.Equal(t, "b", m.GetOr(1, ""))
+	assert.True(t, m.ContainsKey(2))
+	assert.True(t, m.Contains("c"))
+	assert.False(t, m.Contains("a"))
+	m.Remove(1)
+	assert.False(t, m.ContainsKey(1))
+	assert.Equal(t, []string{"c"}, m.Values())
+	m.Clear()
+	assert.True(t, m.IsEmpty())
+}
+
+func TestConcurrentSortedMap_Search(t *testing.T) {
+	m := NewConcurrentSortedMap[int, int](_intComparator{}, 4)
+	_, _, ok := m.First()
+	assert.False(t, ok)
+	for i := 10; i <= 50; i += 10 {
+		m.Set(i, i/10)
+	}
+	key, value, ok := m.First()
+	assert.True(t, ok)
+	assert.Equal(t, 10, key)
+	assert.Equal(t, 1, value)
+	key, _, _ = m.Last()
+	assert.Equal(t, 50, key)
+	key, value, _ = m.Floor(35)
+	assert.Equal(t, 30, key)
+	assert.Equal(t, 3, value)
+	key, _, _ = m.Floor(40)
+	assert.Equal(t, 40, key)
+	_, _, ok = m.Floor(5)
+	assert.False(t, ok)
+	key, _, _ = m.Ceiling(35)
+	assert.Equal(t, 40, key)
+	_, _, ok = m.Ceiling(55)
+	assert.False(t, ok)
+
+	keys := []int{}
+	m.Range(20, 45, func(key, _ int) bool {
+		keys = append(keys, key)
+		return true
+	})
+	assert.Equal(t, []int{20, 30, 40}, keys)
+}
+
+func TestConcurrentSortedMap_Merge(t *testing.T) {
+	m := NewConcurrentSortedMap[int, int](_intComparator{}, 4)
+	for i := 999; i >= 0; i-- {
+		m.Set(i, i)
+	}
+	keys := m.Keys()
+	assert.Len(t, keys, 1000)
+	for i, key := range keys {
+		assert.Equal(t, i, key)
+	}
+	count := 0
+	m.Range(100, 900, func(key, _ int) bool {
+		assert.Equal(t, 100+count, key)
+		count++
+		// the callback runs without any lock held
+		m.Remove(key)
+		return true
+	})
+	assert.Equal(t, 800, count)
+	assert.Equal(t, int64(200), m.Count())
+	count = 0
+	m.Each(func(key, _ int) bool {
+		count++
+		return key < 5
+	})
+	assert.Equal(t, 6, count)
+}
+
+func TestConcurrentSortedMap_Comparator(t *testing.T) {
+	// the keys which the comparator finds equal must share a hash
+	m := NewConcurrentSortedMapFunc[string, int](_caseInsensitiveComparator{}, 8, func(key string) uint64 {
+		return hashKey(strings.ToLower(key))
+	})
+	for i, key := range []string{"a", "A", "B", "b", "c", "C"} {
+		m.Set(key, i)
+	}
+	assert.Equal(t, int64(3), m.Count())
+	assert.Equal(t, []string{"A", "b", "C"}, m.Keys())
+	assert.Equal(t, 3, m.GetOr("B", -1))
+}
+
+func TestConcurrentSortedMap_Concurrent(t *testing.T) {
+	const workers, perWorker = 8, 500
+	m := NewConcurrentSortedMap[int, int](_intComparator{}, 16)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < perWorker; i++ {
+				m.Set(w*perWorker+i, w)
+				m.Floor(i)
+				m.Range(i, i+10, func(key, value int) bool {
+					return true
+				})
+				if i%2 == 1 {
+					m.Remove(w*perWorker + i)
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+	assert.Equal(t, int64(workers*perWorker/2), m.Count())
+	previous := -1
+	m.Each(func(key, value int) bool {
+		assert.Greater(t, key, previous)
+		assert.Equal(t, key/perWorker, value)
+		previous = key
+		return true
+	})
+}
+
+func TestConcurrentSortedMap_JSON(t *testing.T) {
+	m := NewConcurrentSortedMap[int, int](_intComparator{}, 4)
+	m.Set(3, 3)
+	err := json.Unmarshal([]byte(`{"2":2,"0":0,"1":1}`), m)
+	assert.Nil(t, err)
+	assert.Equal(t, []int{0, 1, 2}, m.Keys())
+	jsonBytes, err := json.Marshal(m)
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{"0":0,"1":1,"2":2}`, string(jsonBytes))
+}
+
+func TestConcurrentSortedMap_String(t *testing.T) {
+	m := NewConcurrentSortedMap[int, int](_intComparator{}, 4)
+	m.Set(2, 2)
+	m.Set(0, 0)
+	m.Set(1, 1)
+	str := m.String()
+	pattern := regexp.MustCompile(`ConcurrentSortedMap\[int, int\]\(len=3\)\{\n\t0: 0,\n\t1: 1,\n\t2: 2,\n\}`)
+	assert.True(t, pattern.Match([]byte(str)))
+}
@@ -3,6 +3,7 @@ package maps
 import (
 	"encoding/json"
 	"fmt"
+	"maps"
 	"strings"
 	"sync"
 
@@ -143,8 +144,9 @@ func (m *LinkedMap[K, V]) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
+// ToMap returns a copy of the entries
 func (m *LinkedMap[K, V]) ToMap() map[K]V {
-	return m.items
+	return maps.Clone(m.items)
 }
 
 func (m *LinkedMap[K, V]) String() string {
@@ -181,5 +183,5 @@ func (m *LinkedMap[K, V]) Clone() *LinkedMap[K, V] {
 		mm.Set(key, m.items[key])
 		return true
 	})
-	return m
+	return mm
 }
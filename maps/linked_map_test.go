@@ -192,4 +192,11 @@ func TestLinkedMap_Clone(t *testing.T) {
 	assert.EqualValues(t, map[int]int{
 		0: 0, 1: 1, 2: 2,
 	}, m2.ToMap())
+	assert.NotSame(t, m, m2)
+	m2.Set(3, 3)
+	m.ToMap()[4] = 4
+	assert.Equal(t, []int{0, 1, 2}, m.Keys())
+	assert.EqualValues(t, map[int]int{
+		0: 0, 1: 1, 2: 2,
+	}, m.ToMap())
 }
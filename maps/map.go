@@ -3,6 +3,7 @@ package maps
 import (
 	"encoding/json"
 	"fmt"
+	"maps"
 	"reflect"
 	"strings"
 	"sync"
@@ -127,8 +128,9 @@ func (m *Map[K, V]) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
+// ToMap returns a copy of the entries
 func (m *Map[K, V]) ToMap() map[K]V {
-	return m.items
+	return maps.Clone(m.items)
 }
 
 func (m *Map[K, V]) String() string {
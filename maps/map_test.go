@@ -159,4 +159,9 @@ func TestMap_Clone(t *testing.T) {
 	assert.EqualValues(t, map[int]int{
 		0: 0, 1: 1, 2: 2,
 	}, m2.ToMap())
+	m2.Set(3, 3)
+	m.ToMap()[4] = 4
+	assert.EqualValues(t, map[int]int{
+		0: 0, 1: 1, 2: 2,
+	}, m.ToMap())
 }
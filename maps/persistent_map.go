@@ -0,0 +1,368 @@
+package maps
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/bits"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/gopi-frame/contract/support"
+)
+
+const (
+	hamtBits = 5
+	hamtMask = 1<<hamtBits - 1
+)
+
+// hamtEntry entry of a trie node, either a key and its value or a child node
+type hamtEntry[K comparable, V any] struct {
+	hash  uint64
+	key   K
+	value V
+	child *hamtNode[K, V]
+}
+
+// hamtNode node of the hash array mapped trie, the bitmap has a bit set for
+// every chunk of the hash present at the level, the entries are stored in
+// the order of the bits. Once the hash is used up the node holds the keys
+// sharing the whole hash in a plain list and its bitmap is unused.
+type hamtNode[K comparable, V any] struct {
+	bitmap  uint32
+	entries []hamtEntry[K, V]
+}
+
+func hamtBit(hash uint64, shift uint) uint32 {
+	return 1 << ((hash >> shift) & hamtMask)
+}
+
+func (node *hamtNode[K, V]) index(bit uint32) int {
+	return bits.OnesCount32(node.bitmap & (bit - 1))
+}
+
+func (node *hamtNode[K, V]) get(hash uint64, shift uint, key K) (V, bool) {
+	for node != nil {
+		if shift >= 64 {
+			for _, e := range node.entries {
+				if e.key == key {
+					return e.value, true
+				}
+			}
+			break
+		}
+		bit := hamtBit(hash, shift)
+		if node.bitmap&bit == 0 {
+			break
+		}
+		e := node.entries[node.index(bit)]
+		if e.child == nil {
+			if e.hash == hash && e.key == key {
+				return e.value, true
+			}
+			break
+		}
+		node, shift = e.child, shift+hamtBits
+	}
+	return *new(V), false
+}
+
+// set returns a copy of the node with the key set, and whether the key was added
+func (node *hamtNode[K, V]) set(shift uint, entry hamtEntry[K, V]) (*hamtNode[K, V], bool) {
+	if shift >= 64 {
+		for index, e := range node.entries {
+			if e.key == entry.key {
+				n := &hamtNode[K, V]{entries: slices.Clone(node.entries)}
+				n.entries[index] = entry
+				return n, false
+			}
+		}
+		return &hamtNode[K, V]{entries: append(slices.Clip(node.entries), entry)}, true
+	}
+	bit := hamtBit(entry.hash, shift)
+	index := node.index(bit)
+	if node.bitmap&bit == 0 {
+		return &hamtNode[K, V]{bitmap: node.bitmap | bit, entries: slices.Insert(slices.Clip(node.entries), index, entry)}, true
+	}
+	n := &hamtNode[K, V]{bitmap: node.bitmap, entries: slices.Clone(node.entries)}
+	e := node.entries[index]
+	added := false
+	switch {
+	case e.child != nil:
+		var child *hamtNode[K, V]
+		child, added = e.child.set(shift+hamtBits, entry)
+		n.entries[index] = hamtEntry[K, V]{child: child}
+	case e.hash == entry.hash && e.key == entry.key:
+		n.entries[index] = entry
+	default:
+		n.entries[index] = hamtEntry[K, V]{child: hamtMerge(shift+hamtBits, e, entry)}
+		added = true
+	}
+	return n, added
+}
+
+// hamtMerge returns the node holding the two entries whose hashes are equal up to the shift
+func hamtMerge[K comparable, V any](shift uint, a, b hamtEntry[K, V]) *hamtNode[K, V] {
+	if shift >= 64 {
+		return &hamtNode[K, V]{entries: []hamtEntry[K, V]{a, b}}
+	}
+	bitA, bitB := hamtBit(a.hash, shift), hamtBit(b.hash, shift)
+	if bitA == bitB {
+		return &hamtNode[K, V]{bitmap: bitA, entries: []hamtEntry[K, V]{{child: hamtMerge(shift+hamtBits, a, b)}}}
+	}
+	if bitA > bitB {
+		a, b = b, a
+	}
+	return &hamtNode[K, V]{bitmap: bitA | bitB, entries: []hamtEntry[K, V]{a, b}}
+}
+
+// remove returns a copy of the node without the key, nil when it is left empty,
+// and whether the key was removed
+func (node *hamtNode[K, V]) remove(hash uint64, shift uint, key K) (*hamtNode[K, V], bool) {
+	if shift >= 64 {
+		index := slices.IndexFunc(node.entries, func(e hamtEntry[K, V]) bool {
+			return e.key == key
+		})
+		if index < 0 {
+			return node, false
+		}
+		if len(node.entries) == 1 {
+			return nil, true
+		}
+		return &hamtNode[K, V]{entries: slices.Delete(slices.Clone(node.entries), index, index+1)}, true
+	}
+	bit := hamtBit(hash, shift)
+	if node.bitmap&bit == 0 {
+		return node, false
+	}
+	index := node.index(bit)
+	e := node.entries[index]
+	if e.child == nil {
+		if e.hash != hash || e.key != key {
+			return node, false
+		}
+		if len(node.entries) == 1 {
+			return nil, true
+		}
+		return &hamtNode[K, V]{bitmap: node.bitmap &^ bit, entries: slices.Delete(slices.Clone(node.entries), index, index+1)}, true
+	}
+	child, removed := e.child.remove(hash, shift+hamtBits, key)
+	if !removed {
+		return node, false
+	}
+	if child == nil {
+		if len(node.entries) == 1 {
+			return nil, true
+		}
+		return &hamtNode[K, V]{bitmap: node.bitmap &^ bit, entries: slices.Delete(slices.Clone(node.entries), index, index+1)}, true
+	}
+	n := &hamtNode[K, V]{bitmap: node.bitmap, entries: slices.Clone(node.entries)}
+	if len(child.entries) == 1 && child.entries[0].child == nil {
+		// a single key left below, pull it up to keep the trie compact
+		n.entries[index] = child.entries[0]
+	} else {
+		n.entries[index] = hamtEntry[K, V]{child: child}
+	}
+	return n, true
+}
+
+func (node *hamtNode[K, V]) each(callback func(key K, value V) bool) bool {
+	if node == nil {
+		return true
+	}
+	for _, e := range node.entries {
+		if e.child != nil {
+			if !e.child.each(callback) {
+				return false
+			}
+		} else if !callback(e.key, e.value) {
+			return false
+		}
+	}
+	return true
+}
+
+// NewPersistentMap new persistent map
+func NewPersistentMap[K comparable, V any]() *PersistentMap[K, V] {
+	return NewPersistentMapFunc[K, V](hashKey[K])
+}
+
+// NewPersistentMapFunc new persistent map which hashes the keys with the hash function
+func NewPersistentMapFunc[K comparable, V any](hash func(key K) uint64) *PersistentMap[K, V] {
+	return &PersistentMap[K, V]{hash: hash}
+}
+
+// PersistentMap immutable hash map with structural sharing
+//
+// It is a hash array mapped trie: Set and Remove return a new map which shares
+// all the nodes off the updated path with the previous one, and the operations
+// are O(log32 n). A map never changes once built, so it can be shared between
+// goroutines without locking. The zero value is an empty map.
+type PersistentMap[K comparable, V any] struct {
+	hash  func(key K) uint64
+	root  *hamtNode[K, V]
+	count int
+}
+
+func (m *PersistentMap[K, V]) hashOf(key K) uint64 {
+	if m.hash == nil {
+		return hashKey(key)
+	}
+	return m.hash(key)
+}
+
+func (m *PersistentMap[K, V]) Count() int64 {
+	return int64(m.count)
+}
+
+func (m *PersistentMap[K, V]) IsEmpty() bool {
+	return m.count == 0
+}
+
+func (m *PersistentMap[K, V]) IsNotEmpty() bool {
+	return !m.IsEmpty()
+}
+
+func (m *PersistentMap[K, V]) Get(key K) (V, bool) {
+	return m.root.get(m.hashOf(key), 0, key)
+}
+
+func (m *PersistentMap[K, V]) GetOr(key K, value V) V {
+	if v, ok := m.Get(key); ok {
+		return v
+	}
+	return value
+}
+
+// Set returns a new map with the key set to the value
+func (m *PersistentMap[K, V]) Set(key K, value V) *PersistentMap[K, V] {
+	root := m.root
+	if root == nil {
+		root = new(hamtNode[K, V])
+	}
+	root, added := root.set(0, hamtEntry[K, V]{hash: m.hashOf(key), key: key, value: value})
+	next := &PersistentMap[K, V]{hash: m.hash, root: root, count: m.count}
+	if added {
+		next.count++
+	}
+	return next
+}
+
+// Remove returns a new map without the key
+func (m *PersistentMap[K, V]) Remove(key K) *PersistentMap[K, V] {
+	if m.root == nil {
+		return m
+	}
+	root, removed := m.root.remove(m.hashOf(key), 0, key)
+	if !removed {
+		return m
+	}
+	return &PersistentMap[K, V]{hash: m.hash, root: root, count: m.count - 1}
+}
+
+// Clear returns an empty map with the same hash function
+func (m *PersistentMap[K, V]) Clear() *PersistentMap[K, V] {
+	return &PersistentMap[K, V]{hash: m.hash}
+}
+
+func (m *PersistentMap[K, V]) Keys() []K {
+	keys := make([]K, 0, m.count)
+	m.Each(func(key K, _ V) bool {
+		keys = append(keys, key)
+		return true
+	})
+	return keys
+}
+
+func (m *PersistentMap[K, V]) Values() []V {
+	values := make([]V, 0, m.count)
+	m.Each(func(_ K, value V) bool {
+		values = append(values, value)
+		return true
+	})
+	return values
+}
+
+func (m *PersistentMap[K, V]) ContainsKey(key K) bool {
+	_, ok := m.Get(key)
+	return ok
+}
+
+func (m *PersistentMap[K, V]) Contains(value V) bool {
+	return m.ContainsWhere(func(v V) bool {
+		return reflect.DeepEqual(v, value)
+	})
+}
+
+func (m *PersistentMap[K, V]) ContainsWhere(callback func(value V) bool) bool {
+	found := false
+	m.Each(func(_ K, value V) bool {
+		found = callback(value)
+		return !found
+	})
+	return found
+}
+
+// Each calls the callback for the entries, in hash order, until it returns false
+func (m *PersistentMap[K, V]) Each(callback func(key K, value V) bool) {
+	m.root.each(callback)
+}
+
+// ToMap returns a copy of the entries
+func (m *PersistentMap[K, V]) ToMap() map[K]V {
+	items := make(map[K]V, m.count)
+	m.Each(func(key K, value V) bool {
+		items[key] = value
+		return true
+	})
+	return items
+}
+
+func (m *PersistentMap[K, V]) ToJSON() ([]byte, error) {
+	return json.Marshal(m.ToMap())
+}
+
+func (m *PersistentMap[K, V]) MarshalJSON() ([]byte, error) {
+	return m.ToJSON()
+}
+
+// UnmarshalJSON replaces the entries of the map, it keeps the hash function of the map
+func (m *PersistentMap[K, V]) UnmarshalJSON(data []byte) error {
+	values := map[K]V{}
+	if err := json.Unmarshal(data, &values); err != nil {
+		return err
+	}
+	next := m.Clear()
+	for key, value := range values {
+		next = next.Set(key, value)
+	}
+	*m = *next
+	return nil
+}
+
+func (m *PersistentMap[K, V]) String() string {
+	str := new(strings.Builder)
+	str.WriteString(fmt.Sprintf("PersistentMap[%T, %T](len=%d)", *new(K), *new(V), m.count))
+	str.WriteByte('{')
+	str.WriteByte('\n')
+	m.Each(func(k K, v V) bool {
+		str.WriteByte('\t')
+		if key, ok := any(k).(support.Stringable); ok {
+			str.WriteString(key.String())
+		} else {
+			str.WriteString(fmt.Sprintf("%v", k))
+		}
+		str.WriteByte(':')
+		str.WriteByte(' ')
+		if value, ok := any(v).(support.Stringable); ok {
+			str.WriteString(value.String())
+		} else {
+			str.WriteString(fmt.Sprintf("%v", v))
+		}
+		str.WriteByte(',')
+		str.WriteByte('\n')
+		return true
+	})
+	str.WriteByte('}')
+	return str.String()
+}
@@ -0,0 +1,193 @@
+package maps
+
+import (
+	"cmp"
+	"encoding/json"
+	"math/rand"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPersistentMap_Set(t *testing.T) {
+	m := NewPersistentMap[string, int]()
+	next := m.Set("a", 1).Set("b", 2).Set("a", 3)
+	assert.True(t, m.IsEmpty())
+	assert.Equal(t, int64(2), next.Count())
+	assert.Equal(t, 3, next.GetOr("a", 0))
+	assert.Equal(t, 2, next.GetOr("b", 0))
+	assert.Equal(t, 0, next.GetOr("c", 0))
+	assert.Equal(t, map[string]int{"a": 3, "b": 2}, next.ToMap())
+}
+
+func TestPersistentMap_Remove(t *testing.T) {
+	m := NewPersistentMap[int, int]().Set(1, 1).Set(2, 2)
+	next := m.Remove(1)
+	assert.Same(t, next, next.Remove(3))
+	assert.Equal(t, map[int]int{1: 1, 2: 2}, m.ToMap())
+	assert.Equal(t, map[int]int{2: 2}, next.ToMap())
+	assert.True(t, next.Remove(2).IsEmpty())
+}
+
+func TestPersistentMap_ZeroValue(t *testing.T) {
+	var m PersistentMap[int, string]
+	assert.True(t, m.IsEmpty())
+	assert.Equal(t, "a", m.Set(1, "a").GetOr(1, ""))
+	assert.Same(t, &m, m.Remove(1))
+}
+
+func TestPersistentMap_Model(t *testing.T) {
+	hashes := map[string]func(int) uint64{
+		"Default": hashKey[int],
+		// the keys collide on their lower bits, or completely
+		"Partial": func(key int) uint64 {
+			return uint64(key%7) << 40
+		},
+		"Collision": func(key int) uint64 {
+			return 42
+		},
+	}
+	for name, hash := range hashes {
+		t.Run(name, func(t *testing.T) {
+			rand := rand.New(rand.NewSource(1))
+			m := NewPersistentMapFunc[int, int](hash)
+			model := map[int]int{}
+			type snapshot struct {
+				m     *PersistentMap[int, int]
+				items map[int]int
+			}
+			snapshots := []snapshot{}
+			for round := 0; round < 2000; round++ {
+				key := rand.Intn(200)
+				if rand.Intn(3) == 0 {
+					m = m.Remove(key)
+					delete(model, key)
+				} else {
+					m = m.Set(key, round)
+					model[key] = round
+				}
+				if round%100 == 0 {
+					snapshots = append(snapshots, snapshot{m, assertPersistentMap(t, m, model)})
+				}
+			}
+			assertPersistentMap(t, m, model)
+			for _, s := range snapshots {
+				assert.Equal(t, s.items, s.m.ToMap())
+			}
+		})
+	}
+
+	t.Run("PointerKey", func(t *testing.T) {
+		rand := rand.New(rand.NewSource(1))
+		keys := make([]*int, 100)
+		for i := range keys {
+			keys[i] = new(int)
+		}
+		m := NewPersistentMap[*int, int]()
+		model := map[*int]int{}
+		for round := 0; round < 2000; round++ {
+			key := keys[rand.Intn(len(keys))]
+			switch rand.Intn(3) {
+			case 0:
+				m = m.Remove(key)
+				delete(model, key)
+			case 1:
+				// the pointee changes, the key does not
+				*key = round
+			default:
+				m = m.Set(key, round)
+				model[key] = round
+			}
+		}
+		assert.Equal(t, int64(len(model)), m.Count())
+		assert.Equal(t, model, m.ToMap())
+		for key, value := range model {
+			assert.Equal(t, value, m.GetOr(key, -1))
+		}
+	})
+}
+
+// assertPersistentMap asserts the map has the entries of the model and returns a copy of the model
+func assertPersistentMap(t *testing.T, m *PersistentMap[int, int], model map[int]int) map[int]int {
+	assert.Equal(t, int64(len(model)), m.Count())
+	items := make(map[int]int, len(model))
+	for key, value := range model {
+		v, ok := m.Get(key)
+		assert.True(t, ok)
+		assert.Equal(t, value, v)
+		items[key] = value
+	}
+	assert.Equal(t, items, m.ToMap())
+	return items
+}
+
+func TestPersistentMap_JSON(t *testing.T) {
+	m := NewPersistentMap[string, int]().Set("a", 1).Set("b", 2)
+	jsonBytes, err := json.Marshal(m)
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{"a":1,"b":2}`, string(jsonBytes))
+
+	unmarshalled := NewPersistentMap[string, int]().Set("c", 3)
+	err = json.Unmarshal([]byte(`{"d":4}`), unmarshalled)
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]int{"d": 4}, unmarshalled.ToMap())
+}
+
+func TestPersistentMap_String(t *testing.T) {
+	m := NewPersistentMap[int, int]().Set(1, 1)
+	pattern := regexp.MustCompile(`PersistentMap\[int, int\]\(len=1\)\{\n\t1: 1,\n\}`)
+	assert.True(t, pattern.MatchString(m.String()))
+}
+
+type _intComparator struct{}
+
+func (c _intComparator) Compare(a, b int) int {
+	return cmp.Compare(a, b)
+}
+
+func TestPersistentSortedMap(t *testing.T) {
+	m := NewPersistentSortedMap[int, string](_intComparator{})
+	next := m.Set(3, "c").Set(1, "a").Set(2, "b").Set(1, "A")
+	assert.True(t, m.IsEmpty())
+	assert.Equal(t, []int{1, 2, 3}, next.Keys())
+	assert.Equal(t, []string{"A", "b", "c"}, next.Values())
+	key, value, ok := next.First()
+	assert.True(t, ok)
+	assert.Equal(t, 1, key)
+	assert.Equal(t, "A", value)
+	key, _, _ = next.Last()
+	assert.Equal(t, 3, key)
+
+	removed := next.Remove(2)
+	assert.Same(t, removed, removed.Remove(2))
+	assert.Equal(t, []int{1, 3}, removed.Keys())
+	assert.Equal(t, []int{1, 2, 3}, next.Keys())
+	assert.True(t, removed.ContainsKey(3))
+	assert.False(t, removed.ContainsKey(2))
+	assert.True(t, removed.Contains("c"))
+}
+
+func TestPersistentSortedMap_JSON(t *testing.T) {
+	m := NewPersistentSortedMap[int, int](_intComparator{}).Set(2, 2).Set(1, 1)
+	jsonBytes, err := json.Marshal(m)
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{"1":1,"2":2}`, string(jsonBytes))
+
+	unmarshalled := NewPersistentSortedMap[int, int](_intComparator{})
+	err = json.Unmarshal([]byte(`{"5":5,"4":4}`), unmarshalled)
+	assert.Nil(t, err)
+	assert.Equal(t, []int{4, 5}, unmarshalled.Keys())
+}
+
+func TestPersistentSortedMap_String(t *testing.T) {
+	m := NewPersistentSortedMap[int, int](_intComparator{}).Set(2, 2).Set(1, 1)
+	assert.Equal(t, "PersistentSortedMap[int, int](len=2){\n\t1: 1,\n\t2: 2,\n}", m.String())
+}
+
+func BenchmarkPersistentMap_Set(b *testing.B) {
+	m := NewPersistentMap[int, int]()
+	for i := 0; i < b.N; i++ {
+		m = m.Set(i, i)
+	}
+}
@@ -0,0 +1,202 @@
+package maps
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/gopi-frame/contract/support"
+	"github.com/gopi-frame/support/tree"
+)
+
+type sortedEntry[K, V any] struct {
+	key   K
+	value V
+}
+
+// sortedEntryComparator compares the entries by key
+type sortedEntryComparator[K, V any] struct {
+	comparator support.Comparator[K]
+}
+
+func (c sortedEntryComparator[K, V]) Compare(a, b sortedEntry[K, V]) int {
+	return c.comparator.Compare(a.key, b.key)
+}
+
+// NewPersistentSortedMap new persistent sorted map
+func NewPersistentSortedMap[K comparable, V any](comparator support.Comparator[K]) *PersistentSortedMap[K, V] {
+	return &PersistentSortedMap[K, V]{
+		tree: tree.NewPersistentRBTree[sortedEntry[K, V]](sortedEntryComparator[K, V]{comparator}),
+	}
+}
+
+// PersistentSortedMap immutable map sorted by key
+//
+// It is backed by a PersistentRBTree of the entries: Set and Remove return a new
+// map sharing all the nodes off the updated path with the previous one, in O(log n),
+// and the entries are visited in key order. A map never changes once built, so it
+// can be shared between goroutines without locking.
+type PersistentSortedMap[K comparable, V any] struct {
+	tree *tree.PersistentRBTree[sortedEntry[K, V]]
+}
+
+func (m *PersistentSortedMap[K, V]) Count() int64 {
+	return m.tree.Count()
+}
+
+func (m *PersistentSortedMap[K, V]) IsEmpty() bool {
+	return m.tree.IsEmpty()
+}
+
+func (m *PersistentSortedMap[K, V]) IsNotEmpty() bool {
+	return m.tree.IsNotEmpty()
+}
+
+func (m *PersistentSortedMap[K, V]) Get(key K) (V, bool) {
+	entry, ok := m.tree.Find(sortedEntry[K, V]{key: key})
+	return entry.value, ok
+}
+
+func (m *PersistentSortedMap[K, V]) GetOr(key K, value V) V {
+	if v, ok := m.Get(key); ok {
+		return v
+	}
+	return value
+}
+
+// Set returns a new map with the key set to the value
+func (m *PersistentSortedMap[K, V]) Set(key K, value V) *PersistentSortedMap[K, V] {
+	return &PersistentSortedMap[K, V]{tree: m.tree.Push(sortedEntry[K, V]{key, value})}
+}
+
+// Remove returns a new map without the key
+func (m *PersistentSortedMap[K, V]) Remove(key K) *PersistentSortedMap[K, V] {
+	next := m.tree.Remove(sortedEntry[K, V]{key: key})
+	if next == m.tree {
+		return m
+	}
+	return &PersistentSortedMap[K, V]{tree: next}
+}
+
+// Clear returns an empty map with the same comparator
+func (m *PersistentSortedMap[K, V]) Clear() *PersistentSortedMap[K, V] {
+	return &PersistentSortedMap[K, V]{tree: m.tree.Clear()}
+}
+
+// First returns the entry with the lowest key
+func (m *PersistentSortedMap[K, V]) First() (K, V, bool) {
+	entry, ok := m.tree.First()
+	return entry.key, entry.value, ok
+}
+
+// Last returns the entry with the highest key
+func (m *PersistentSortedMap[K, V]) Last() (K, V, bool) {
+	entry, ok := m.tree.Last()
+	return entry.key, entry.value, ok
+}
+
+// Keys returns the keys in order
+func (m *PersistentSortedMap[K, V]) Keys() []K {
+	keys := make([]K, 0, m.Count())
+	m.Each(func(key K, _ V) bool {
+		keys = append(keys, key)
+		return true
+	})
+	return keys
+}
+
+// Values returns the values in key order
+func (m *PersistentSortedMap[K, V]) Values() []V {
+	values := make([]V, 0, m.Count())
+	m.Each(func(_ K, value V) bool {
+		values = append(values, value)
+		return true
+	})
+	return values
+}
+
+func (m *PersistentSortedMap[K, V]) ContainsKey(key K) bool {
+	return m.tree.Contains(sortedEntry[K, V]{key: key})
+}
+
+func (m *PersistentSortedMap[K, V]) Contains(value V) bool {
+	return m.ContainsWhere(func(v V) bool {
+		return reflect.DeepEqual(v, value)
+	})
+}
+
+func (m *PersistentSortedMap[K, V]) ContainsWhere(callback func(value V) bool) bool {
+	found := false
+	m.Each(func(_ K, value V) bool {
+		found = callback(value)
+		return !found
+	})
+	return found
+}
+
+// Each calls the callback for the entries, in key order, until it returns false
+func (m *PersistentSortedMap[K, V]) Each(callback func(key K, value V) bool) {
+	m.tree.Each(func(entry sortedEntry[K, V]) bool {
+		return callback(entry.key, entry.value)
+	})
+}
+
+// ToMap returns a copy of the entries
+func (m *PersistentSortedMap[K, V]) ToMap() map[K]V {
+	items := make(map[K]V, m.Count())
+	m.Each(func(key K, value V) bool {
+		items[key] = value
+		return true
+	})
+	return items
+}
+
+func (m *PersistentSortedMap[K, V]) ToJSON() ([]byte, error) {
+	return json.Marshal(m.ToMap())
+}
+
+func (m *PersistentSortedMap[K, V]) MarshalJSON() ([]byte, error) {
+	return m.ToJSON()
+}
+
+// UnmarshalJSON replaces the entries of the map, it keeps the comparator of the map
+func (m *PersistentSortedMap[K, V]) UnmarshalJSON(data []byte) error {
+	values := map[K]V{}
+	if err := json.Unmarshal(data, &values); err != nil {
+		return err
+	}
+	next := m.tree.Clear()
+	for key, value := range values {
+		next = next.Push(sortedEntry[K, V]{key, value})
+	}
+	m.tree = next
+	return nil
+}
+
+func (m *PersistentSortedMap[K, V]) String() string {
+	str := new(strings.Builder)
+	str.WriteString(fmt.Sprintf("PersistentSortedMap[%T, %T](len=%d)", *new(K), *new(V), m.Count()))
+	str.WriteByte('{')
+	str.WriteByte('\n')
+	m.Each(func(k K, v V) bool {
+		str.WriteByte('\t')
+		if key, ok := any(k).(support.Stringable); ok {
+			str.WriteString(key.String())
+		} else {
+			str.WriteString(fmt.Sprintf("%v", k))
+		}
+		str.WriteByte(':')
+		str.WriteByte(' ')
+		if value, ok := any(v).(support.Stringable); ok {
+			str.WriteString(value.String())
+		} else {
+			str.WriteString(fmt.Sprintf("%v", v))
+		}
+		str.WriteByte(',')
+		str.WriteByte('\n')
+		return true
+	})
+	str.WriteByte('}')
+	return str.String()
+}
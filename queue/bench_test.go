@@ -0,0 +1,140 @@
+package queue
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+type benchQueue interface {
+	Enqueue(value int) bool
+	Dequeue() (int, bool)
+}
+
+// singleLockQueue bounded queue whose producers and consumers share one lock,
+// it is the reference the two-lock queues are measured against
+type singleLockQueue struct {
+	mu       sync.Mutex
+	notEmpty *sync.Cond
+	notFull  *sync.Cond
+	items    *LinkedQueue[int]
+	cap      int64
+}
+
+func newSingleLockQueue(cap int) *singleLockQueue {
+	queue := &singleLockQueue{items: NewLinkedQueue[int](), cap: int64(cap)}
+	queue.notEmpty = sync.NewCond(&queue.mu)
+	queue.notFull = sync.NewCond(&queue.mu)
+	return queue
+}
+
+func (q *singleLockQueue) Enqueue(value int) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for q.items.Count() == q.cap {
+		q.notFull.Wait()
+	}
+	q.items.Enqueue(value)
+	q.notEmpty.Signal()
+	return true
+}
+
+func (q *singleLockQueue) Dequeue() (int, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for q.items.IsEmpty() {
+		q.notEmpty.Wait()
+	}
+	value, ok := q.items.Dequeue()
+	q.notFull.Signal()
+	return value, ok
+}
+
+func benchmarkProducersConsumers(b *testing.B, newQueue func() benchQueue) {
+	for _, workers := range []int{1, 4, 16} {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			queue := newQueue()
+			var wg sync.WaitGroup
+			perWorker := b.N/workers + 1
+			b.ResetTimer()
+			for i := 0; i < workers; i++ {
+				wg.Add(2)
+				go func() {
+					defer wg.Done()
+					for j := 0; j < perWorker; j++ {
+						queue.Enqueue(j)
+					}
+				}()
+				go func() {
+					defer wg.Done()
+					for j := 0; j < perWorker; j++ {
+						queue.Dequeue()
+					}
+				}()
+			}
+			wg.Wait()
+		})
+	}
+}
+
+func BenchmarkSingleLockQueue(b *testing.B) {
+	benchmarkProducersConsumers(b, func() benchQueue {
+		return newSingleLockQueue(1024)
+	})
+}
+
+func BenchmarkBlockingQueue(b *testing.B) {
+	benchmarkProducersConsumers(b, func() benchQueue {
+		return NewBlockingQueue[int](1024)
+	})
+}
+
+func BenchmarkLinkedBlockingQueue(b *testing.B) {
+	benchmarkProducersConsumers(b, func() benchQueue {
+		return NewLinkedBlockingQueue[int](1024)
+	})
+}
+
+func BenchmarkConcurrentArrayQueue(b *testing.B) {
+	benchmarkProducersConsumers(b, func() benchQueue {
+		return NewBlocking[int](NewConcurrentArrayQueue[int](1024))
+	})
+}
+
+// mutexLinkedQueue linked queue guarded by an external mutex
+type mutexLinkedQueue struct {
+	mu    sync.Mutex
+	items *LinkedQueue[int]
+}
+
+func (q *mutexLinkedQueue) Enqueue(value int) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.items.Enqueue(value)
+}
+
+func (q *mutexLinkedQueue) Dequeue() (int, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.items.Dequeue()
+}
+
+func benchmarkUnbounded(b *testing.B, queue benchQueue) {
+	b.RunParallel(func(pb *testing.PB) {
+		for i := 0; pb.Next(); i++ {
+			if i%2 == 0 {
+				queue.Enqueue(i)
+			} else {
+				queue.Dequeue()
+			}
+		}
+	})
+}
+
+func BenchmarkMutexLinkedQueue(b *testing.B) {
+	benchmarkUnbounded(b, &mutexLinkedQueue{items: NewLinkedQueue[int]()})
+}
+
+func BenchmarkConcurrentLinkedQueue(b *testing.B) {
+	benchmarkUnbounded(b, NewConcurrentLinkedQueue[int]())
+}
@@ -0,0 +1,130 @@
+package queue
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gopi-frame/support/internal/syncutil"
+)
+
+// NonBlockingQueue the non-blocking half of support.BlockingQueue
+type NonBlockingQueue[E any] interface {
+	TryEnqueue(value E) bool
+	TryDequeue() (E, bool)
+}
+
+// NewBlocking wraps the non-blocking queue with the blocking operations
+func NewBlocking[E any](queue NonBlockingQueue[E]) *Blocking[E] {
+	blocking := new(Blocking[E])
+	blocking.queue = queue
+	blocking.notEmpty = sync.NewCond(&blocking.mu)
+	blocking.notFull = sync.NewCond(&blocking.mu)
+	return blocking
+}
+
+// Blocking adds the blocking operations of support.BlockingQueue to a non-blocking queue.
+//
+// The successful operations stay lock-free while nobody is waiting, the lock is
+// only taken to park the waiters and to wake them up.
+type Blocking[E any] struct {
+	queue          NonBlockingQueue[E]
+	mu             sync.Mutex
+	notEmpty       *sync.Cond
+	notFull        *sync.Cond
+	takersWaiting  atomic.Int64
+	puttersWaiting atomic.Int64
+}
+
+// Queue returns the wrapped queue
+func (q *Blocking[E]) Queue() NonBlockingQueue[E] {
+	return q.queue
+}
+
+func (q *Blocking[E]) signal(waiting *atomic.Int64, cond *sync.Cond) {
+	if waiting.Load() > 0 {
+		q.mu.Lock()
+		defer q.mu.Unlock()
+		cond.Broadcast()
+	}
+}
+
+func (q *Blocking[E]) TryEnqueue(value E) bool {
+	if !q.queue.TryEnqueue(value) {
+		return false
+	}
+	q.signal(&q.takersWaiting, q.notEmpty)
+	return true
+}
+
+func (q *Blocking[E]) TryDequeue() (E, bool) {
+	value, ok := q.queue.TryDequeue()
+	if ok {
+		q.signal(&q.puttersWaiting, q.notFull)
+	}
+	return value, ok
+}
+
+// enqueue retries to enqueue until it succeeds or the deadline passes, a zero deadline waits forever
+func (q *Blocking[E]) enqueue(value E, deadline time.Time) bool {
+	if q.TryEnqueue(value) {
+		return true
+	}
+	q.puttersWaiting.Add(1)
+	defer q.puttersWaiting.Add(-1)
+	q.mu.Lock()
+	for !q.queue.TryEnqueue(value) {
+		if !deadline.IsZero() && !time.Now().Before(deadline) {
+			q.mu.Unlock()
+			return false
+		}
+		q.notFull.Wait()
+	}
+	q.mu.Unlock()
+	q.signal(&q.takersWaiting, q.notEmpty)
+	return true
+}
+
+// dequeue retries to dequeue until it succeeds or the deadline passes, a zero deadline waits forever
+func (q *Blocking[E]) dequeue(deadline time.Time) (E, bool) {
+	if value, ok := q.TryDequeue(); ok {
+		return value, true
+	}
+	q.takersWaiting.Add(1)
+	defer q.takersWaiting.Add(-1)
+	q.mu.Lock()
+	for {
+		if value, ok := q.queue.TryDequeue(); ok {
+			q.mu.Unlock()
+			q.signal(&q.puttersWaiting, q.notFull)
+			return value, true
+		}
+		if !deadline.IsZero() && !time.Now().Before(deadline) {
+			q.mu.Unlock()
+			return *new(E), false
+		}
+		q.notEmpty.Wait()
+	}
+}
+
+func (q *Blocking[E]) Enqueue(value E) bool {
+	return q.enqueue(value, time.Time{})
+}
+
+func (q *Blocking[E]) Dequeue() (E, bool) {
+	return q.dequeue(time.Time{})
+}
+
+func (q *Blocking[E]) EnqueueTimeout(value E, duration time.Duration) bool {
+	deadline := time.Now().Add(duration)
+	timer := syncutil.BroadcastAfter(q.notFull, duration)
+	defer timer.Stop()
+	return q.enqueue(value, deadline)
+}
+
+func (q *Blocking[E]) DequeueTimeout(duration time.Duration) (E, bool) {
+	deadline := time.Now().Add(duration)
+	timer := syncutil.BroadcastAfter(q.notEmpty, duration)
+	defer timer.Stop()
+	return q.dequeue(deadline)
+}
@@ -5,33 +5,41 @@ import (
 	"fmt"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gopi-frame/contract/support"
-	"github.com/gopi-frame/exception"
-	"github.com/gopi-frame/future"
-	"github.com/gopi-frame/support/lists"
+	"github.com/gopi-frame/support/internal/syncutil"
 )
 
+var _ StatsProvider = (*BlockingQueue[any])(nil)
+
 // NewBlockingQueue new blocking queue
 func NewBlockingQueue[E any](cap int) *BlockingQueue[E] {
 	queue := new(BlockingQueue[E])
-	queue.items = lists.NewList(make([]E, cap, cap)...)
+	queue.items = make([]E, cap)
 	queue.cap = cap
-	queue.takeLock = sync.NewCond(queue.items)
-	queue.putLock = sync.NewCond(queue.items)
+	queue.notEmpty = sync.NewCond(&queue.takeLock)
+	queue.notFull = sync.NewCond(&queue.putLock)
 	return queue
 }
 
 // BlockingQueue blocking queue
+//
+// It is a bounded ring buffer with separate put and take locks: producers only
+// touch the enqueue index and consumers the dequeue index, the atomic count
+// guarantees they never access the same slot at the same time.
 type BlockingQueue[E any] struct {
-	items        *lists.List[E]
-	size         int
+	items        []E
 	cap          int
+	count        atomic.Int64
 	enqueueIndex int
 	dequeueIndex int
-	takeLock     *sync.Cond
-	putLock      *sync.Cond
+	takeLock     sync.Mutex
+	notEmpty     *sync.Cond
+	putLock      sync.Mutex
+	notFull      *sync.Cond
+	stats        stats
 }
 
 func (q *BlockingQueue[E]) moveIndex(index int) int {
@@ -42,11 +50,62 @@ func (q *BlockingQueue[E]) moveIndex(index int) int {
 	return index
 }
 
-func (q *BlockingQueue[E]) Count() int64 {
-	if q.items.TryRLock() {
-		defer q.items.RUnlock()
+func (q *BlockingQueue[E]) fullyLock() {
+	q.putLock.Lock()
+	q.takeLock.Lock()
+}
+
+func (q *BlockingQueue[E]) fullyUnlock() {
+	q.takeLock.Unlock()
+	q.putLock.Unlock()
+}
+
+func (q *BlockingQueue[E]) signalNotEmpty() {
+	q.takeLock.Lock()
+	defer q.takeLock.Unlock()
+	q.notEmpty.Signal()
+}
+
+func (q *BlockingQueue[E]) signalNotFull() {
+	q.putLock.Lock()
+	defer q.putLock.Unlock()
+	q.notFull.Signal()
+}
+
+// enqueue stores the value at the enqueue index, it must be called with the put lock held.
+// It returns the count before the value was added.
+func (q *BlockingQueue[E]) enqueue(value E) int64 {
+	q.items[q.enqueueIndex] = value
+	q.enqueueIndex = q.moveIndex(q.enqueueIndex)
+	q.stats.enqueued.Add(1)
+	count := q.count.Add(1)
+	if count < int64(q.cap) {
+		q.notFull.Signal()
 	}
-	return int64(q.size)
+	return count - 1
+}
+
+// dequeue takes the value at the dequeue index, it must be called with the take lock held.
+// It returns the value and the count before the value was removed.
+func (q *BlockingQueue[E]) dequeue() (E, int64) {
+	value := q.items[q.dequeueIndex]
+	q.items[q.dequeueIndex] = *new(E)
+	q.dequeueIndex = q.moveIndex(q.dequeueIndex)
+	q.stats.dequeued.Add(1)
+	count := q.count.Add(-1)
+	if count > 0 {
+		q.notEmpty.Signal()
+	}
+	return value, count + 1
+}
+
+// Stats returns the stats of the queue
+func (q *BlockingQueue[E]) Stats() Stats {
+	return q.stats.snapshot(q.count.Load(), int64(q.cap))
+}
+
+func (q *BlockingQueue[E]) Count() int64 {
+	return q.count.Load()
 }
 
 func (q *BlockingQueue[E]) IsEmpty() bool {
@@ -58,160 +117,152 @@ func (q *BlockingQueue[E]) IsNotEmpty() bool {
 }
 
 func (q *BlockingQueue[E]) Clear() {
-	if q.items.TryLock() {
-		defer q.items.Unlock()
-	}
-	q.items.Clear()
-	q.size = 0
+	q.fullyLock()
+	defer q.fullyUnlock()
+	clear(q.items)
+	q.stats.removed.Add(q.count.Swap(0))
 	q.dequeueIndex = 0
 	q.enqueueIndex = 0
+	q.notFull.Broadcast()
 }
 
 func (q *BlockingQueue[E]) Peek() (E, bool) {
-	if q.items.TryRLock() {
-		defer q.items.RUnlock()
-	}
-	if q.size == 0 {
+	q.takeLock.Lock()
+	defer q.takeLock.Unlock()
+	if q.count.Load() == 0 {
 		return *new(E), false
 	}
-	return q.items.Get(q.dequeueIndex), true
+	return q.items[q.dequeueIndex], true
 }
 
 func (q *BlockingQueue[E]) TryEnqueue(value E) bool {
-	if q.items.TryLock() {
-		defer q.items.Unlock()
-	}
-	if q.cap == q.size {
+	q.putLock.Lock()
+	if q.count.Load() == int64(q.cap) {
+		q.putLock.Unlock()
 		return false
 	}
-	q.items.Set(q.enqueueIndex, value)
-	q.size++
-	q.enqueueIndex = q.moveIndex(q.enqueueIndex)
-	q.takeLock.Broadcast()
+	count := q.enqueue(value)
+	q.putLock.Unlock()
+	if count == 0 {
+		q.signalNotEmpty()
+	}
 	return true
 }
 
 func (q *BlockingQueue[E]) TryDequeue() (E, bool) {
-	if q.items.TryLock() {
-		defer q.items.Unlock()
-	}
-	if q.size == 0 {
+	q.takeLock.Lock()
+	if q.count.Load() == 0 {
+		q.takeLock.Unlock()
 		return *new(E), false
 	}
-	value := q.items.Get(q.dequeueIndex)
-	q.items.Set(q.dequeueIndex, *new(E))
-	q.dequeueIndex = q.moveIndex(q.dequeueIndex)
-	q.size--
-	q.putLock.Broadcast()
+	value, count := q.dequeue()
+	q.takeLock.Unlock()
+	if count == int64(q.cap) {
+		q.signalNotFull()
+	}
 	return value, true
 }
 
 func (q *BlockingQueue[E]) Enqueue(value E) bool {
-	if q.items.TryLock() {
-		defer q.items.Unlock()
+	q.putLock.Lock()
+	if q.count.Load() == int64(q.cap) {
+		done := q.stats.blockProducer()
+		for q.count.Load() == int64(q.cap) {
+			q.notFull.Wait()
+		}
+		done()
 	}
-	for q.cap == q.size {
-		q.putLock.Wait()
+	count := q.enqueue(value)
+	q.putLock.Unlock()
+	if count == 0 {
+		q.signalNotEmpty()
 	}
-	q.items.Set(q.enqueueIndex, value)
-	q.size++
-	q.enqueueIndex = q.moveIndex(q.enqueueIndex)
-	q.takeLock.Broadcast()
 	return true
 }
 
 func (q *BlockingQueue[E]) Dequeue() (E, bool) {
-	if q.items.TryLock() {
-		defer q.items.Unlock()
+	q.takeLock.Lock()
+	if q.count.Load() == 0 {
+		done := q.stats.blockConsumer()
+		for q.count.Load() == 0 {
+			q.notEmpty.Wait()
+		}
+		done()
 	}
-	for q.size == 0 {
-		q.takeLock.Wait()
+	value, count := q.dequeue()
+	q.takeLock.Unlock()
+	if count == int64(q.cap) {
+		q.signalNotFull()
 	}
-	value := q.items.Get(q.dequeueIndex)
-	q.items.Set(q.dequeueIndex, *new(E))
-	q.size--
-	q.dequeueIndex = q.moveIndex(q.dequeueIndex)
-	q.putLock.Broadcast()
 	return value, true
 }
 
 func (q *BlockingQueue[E]) EnqueueTimeout(value E, duration time.Duration) bool {
-	var ok bool
-	exception.Try(func() {
-		done := make(chan struct{})
-		ok = future.Timeout(func() bool {
-			future.Void(func() {
-				q.items.Lock()
-				defer q.items.Unlock()
-				for q.cap == q.size {
-					q.putLock.Wait()
-				}
-				done <- struct{}{}
-			})
-			<-done
-			q.items.Set(q.enqueueIndex, value)
-			q.size++
-			q.enqueueIndex = q.moveIndex(q.enqueueIndex)
-			q.takeLock.Broadcast()
-			return true
-		}, duration).Complete(func() {
-			close(done)
-		}).Await()
-	}).Catch(new(exception.TimeoutException), func(err error) {
-		ok = false
-	}).Run()
-	return ok
+	deadline := time.Now().Add(duration)
+	timer := syncutil.BroadcastAfter(q.notFull, duration)
+	defer timer.Stop()
+	q.putLock.Lock()
+	if q.count.Load() == int64(q.cap) {
+		done := q.stats.blockProducer()
+		for q.count.Load() == int64(q.cap) {
+			if !time.Now().Before(deadline) {
+				done()
+				q.putLock.Unlock()
+				return false
+			}
+			q.notFull.Wait()
+		}
+		done()
+	}
+	count := q.enqueue(value)
+	q.putLock.Unlock()
+	if count == 0 {
+		q.signalNotEmpty()
+	}
+	return true
 }
 
 func (q *BlockingQueue[E]) DequeueTimeout(duration time.Duration) (E, bool) {
-	var value E
-	var ok bool
-	exception.Try(func() {
-		done := make(chan struct{})
-		ok = future.Timeout(func() bool {
-			future.Void(func() {
-				q.items.Lock()
-				defer q.items.Unlock()
-				for q.size == 0 {
-					q.takeLock.Wait()
-				}
-				done <- struct{}{}
-			})
-			<-done
-			value = q.items.Get(q.dequeueIndex)
-			q.items.Set(q.dequeueIndex, *new(E))
-			q.size--
-			q.dequeueIndex = q.moveIndex(q.dequeueIndex)
-			q.putLock.Broadcast()
-			return true
-		}, duration).Complete(func() {
-			close(done)
-		}).Await()
-	}).Catch(new(exception.TimeoutException), func(err error) {
-	}).Run()
-	return value, ok
+	deadline := time.Now().Add(duration)
+	timer := syncutil.BroadcastAfter(q.notEmpty, duration)
+	defer timer.Stop()
+	q.takeLock.Lock()
+	if q.count.Load() == 0 {
+		done := q.stats.blockConsumer()
+		for q.count.Load() == 0 {
+			if !time.Now().Before(deadline) {
+				done()
+				q.takeLock.Unlock()
+				return *new(E), false
+			}
+			q.notEmpty.Wait()
+		}
+		done()
+	}
+	value, count := q.dequeue()
+	q.takeLock.Unlock()
+	if count == int64(q.cap) {
+		q.signalNotFull()
+	}
+	return value, true
 }
 
-func (q *BlockingQueue[E]) ToArray() []E {
-	if q.items.TryRLock() {
-		defer q.items.RUnlock()
-	}
-	values := []E{}
-	if q.enqueueIndex > q.dequeueIndex {
-		for index := q.dequeueIndex; index < q.enqueueIndex; index++ {
-			values = append(values, q.items.Get(index))
-		}
-	} else {
-		for index := q.dequeueIndex; index < q.cap; index++ {
-			values = append(values, q.items.Get(index))
-		}
-		for index := 0; index < q.enqueueIndex; index++ {
-			values = append(values, q.items.Get(index))
-		}
+// values returns the values in queue order, it must be called with both locks held.
+func (q *BlockingQueue[E]) values() []E {
+	count := int(q.count.Load())
+	values := make([]E, 0, count)
+	for index, i := q.dequeueIndex, 0; i < count; index, i = q.moveIndex(index), i+1 {
+		values = append(values, q.items[index])
 	}
 	return values
 }
 
+func (q *BlockingQueue[E]) ToArray() []E {
+	q.fullyLock()
+	defer q.fullyUnlock()
+	return q.values()
+}
+
 func (q *BlockingQueue[E]) ToJSON() ([]byte, error) {
 	return json.Marshal(q.ToArray())
 }
@@ -221,34 +272,24 @@ func (q *BlockingQueue[E]) MarshalJSON() ([]byte, error) {
 }
 
 func (q *BlockingQueue[E]) UnmarshalJSON(data []byte) error {
-	if q.items.TryLock() {
-		defer q.items.Unlock()
-	}
 	values := make([]E, 0)
 	if err := json.Unmarshal(data, &values); err != nil {
 		return err
 	}
 	for _, value := range values {
-		for q.size == q.cap {
-			q.putLock.Wait()
-		}
-		q.items.Set(q.enqueueIndex, value)
-		q.size++
-		q.enqueueIndex = q.moveIndex(q.enqueueIndex)
-		q.takeLock.Broadcast()
+		q.Enqueue(value)
 	}
 	return nil
 }
 
 func (q *BlockingQueue[E]) String() string {
-	if q.items.TryRLock() {
-		defer q.items.RUnlock()
-	}
+	q.fullyLock()
+	defer q.fullyUnlock()
 	str := new(strings.Builder)
-	str.WriteString(fmt.Sprintf("BlockingQueue[%T](len=%d)", *new(E), q.size))
+	str.WriteString(fmt.Sprintf("BlockingQueue[%T](len=%d)", *new(E), q.count.Load()))
 	str.WriteByte('{')
 	str.WriteByte('\n')
-	q.items.Each(func(index int, value E) bool {
+	for index, value := range q.values() {
 		str.WriteByte('\t')
 		if v, ok := any(value).(support.Stringable); ok {
 			str.WriteString(v.String())
@@ -257,9 +298,11 @@ func (q *BlockingQueue[E]) String() string {
 		}
 		str.WriteByte(',')
 		str.WriteByte('\n')
-		return index < 4
-	})
-	if q.size > 5 {
+		if index >= 4 {
+			break
+		}
+	}
+	if q.count.Load() > 5 {
 		str.WriteString("\t...\n")
 	}
 	str.WriteByte('}')
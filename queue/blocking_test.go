@@ -0,0 +1,69 @@
+package queue
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBlocking_Enqueue(t *testing.T) {
+	queue := NewBlocking[int](NewConcurrentArrayQueue[int](2))
+	queue.Enqueue(0)
+	queue.Enqueue(1)
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		queue.Dequeue()
+	}()
+	start := time.Now()
+	assert.True(t, queue.Enqueue(2))
+	assert.GreaterOrEqual(t, time.Since(start), 100*time.Millisecond)
+}
+
+func TestBlocking_Dequeue(t *testing.T) {
+	queue := NewBlocking[int](NewConcurrentArrayQueue[int](1))
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		queue.Enqueue(1)
+	}()
+	v, ok := queue.Dequeue()
+	assert.True(t, ok)
+	assert.Equal(t, 1, v)
+}
+
+func TestBlocking_Timeout(t *testing.T) {
+	queue := NewBlocking[int](NewConcurrentArrayQueue[int](2))
+	start := time.Now()
+	_, ok := queue.DequeueTimeout(100 * time.Millisecond)
+	assert.False(t, ok)
+	assert.GreaterOrEqual(t, time.Since(start), 100*time.Millisecond)
+	assert.True(t, queue.EnqueueTimeout(0, 100*time.Millisecond))
+	assert.True(t, queue.EnqueueTimeout(1, 100*time.Millisecond))
+	assert.False(t, queue.EnqueueTimeout(2, 100*time.Millisecond))
+}
+
+func TestBlocking_Stress(t *testing.T) {
+	queue := NewBlocking[int](NewConcurrentArrayQueue[int](4))
+	var wg sync.WaitGroup
+	var sum atomic.Int64
+	for i := 0; i < 8; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := 1; j <= 2000; j++ {
+				queue.Enqueue(j)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 2000; j++ {
+				v, _ := queue.Dequeue()
+				sum.Add(int64(v))
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int64(8*2001000), sum.Load())
+}
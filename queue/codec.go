@@ -0,0 +1,22 @@
+package queue
+
+import "encoding/json"
+
+// Codec encodes the elements of a durable queue
+type Codec[E any] interface {
+	Encode(value E) ([]byte, error)
+	Decode(data []byte) (E, error)
+}
+
+// JSONCodec codec encoding the elements as JSON
+type JSONCodec[E any] struct{}
+
+func (JSONCodec[E]) Encode(value E) ([]byte, error) {
+	return json.Marshal(value)
+}
+
+func (JSONCodec[E]) Decode(data []byte) (E, error) {
+	var value E
+	err := json.Unmarshal(data, &value)
+	return value, err
+}
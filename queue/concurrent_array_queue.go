@@ -0,0 +1,125 @@
+package queue
+
+import (
+	"fmt"
+	"sync/atomic"
+)
+
+var _ NonBlockingQueue[any] = (*ConcurrentArrayQueue[any])(nil)
+
+type concurrentArrayCell[E any] struct {
+	sequence atomic.Uint64
+	value    E
+}
+
+// cacheLinePad keeps the hot atomic counters on separate cache lines
+type cacheLinePad [64]byte
+
+// NewConcurrentArrayQueue new concurrent array queue holding at most cap elements,
+// the ring buffer is rounded up to a power of two not less than 2
+func NewConcurrentArrayQueue[E any](cap int) *ConcurrentArrayQueue[E] {
+	if cap < 1 {
+		panic(fmt.Sprintf("queue: invalid concurrent array queue capacity %d", cap))
+	}
+	// a single cell could not tell a written cell from the next lap's free one
+	size := 2
+	for size < cap {
+		size <<= 1
+	}
+	queue := new(ConcurrentArrayQueue[E])
+	queue.cap = uint64(cap)
+	queue.cells = make([]concurrentArrayCell[E], size)
+	queue.mask = uint64(size - 1)
+	for index := range queue.cells {
+		queue.cells[index].sequence.Store(uint64(index))
+	}
+	return queue
+}
+
+// ConcurrentArrayQueue lock-free bounded multi-producer multi-consumer queue.
+//
+// Every cell of the ring buffer carries a sequence number telling whether it is
+// ready to be written at the enqueue position or read at the dequeue position,
+// producers and consumers claim a position with a single CAS. Wrap it with
+// NewBlocking to get the blocking operations.
+type ConcurrentArrayQueue[E any] struct {
+	_          cacheLinePad
+	enqueuePos atomic.Uint64
+	_          cacheLinePad
+	dequeuePos atomic.Uint64
+	_          cacheLinePad
+	cells      []concurrentArrayCell[E]
+	mask       uint64
+	cap        uint64
+}
+
+// Cap returns the capacity of the queue
+func (q *ConcurrentArrayQueue[E]) Cap() int {
+	return int(q.cap)
+}
+
+// Count returns the number of elements, it is only a snapshot while the queue is accessed concurrently
+func (q *ConcurrentArrayQueue[E]) Count() int64 {
+	for {
+		dequeuePos := q.dequeuePos.Load()
+		enqueuePos := q.enqueuePos.Load()
+		if dequeuePos == q.dequeuePos.Load() {
+			return int64(enqueuePos - dequeuePos)
+		}
+	}
+}
+
+func (q *ConcurrentArrayQueue[E]) IsEmpty() bool {
+	return q.Count() == 0
+}
+
+func (q *ConcurrentArrayQueue[E]) IsNotEmpty() bool {
+	return !q.IsEmpty()
+}
+
+func (q *ConcurrentArrayQueue[E]) TryEnqueue(value E) bool {
+	pos := q.enqueuePos.Load()
+	for {
+		cell := &q.cells[pos&q.mask]
+		diff := int64(cell.sequence.Load() - pos)
+		if diff == 0 {
+			// the ring may have free cells beyond the capacity, which must not be used
+			if int64(pos-q.dequeuePos.Load()) >= int64(q.cap) {
+				return false
+			}
+			if q.enqueuePos.CompareAndSwap(pos, pos+1) {
+				cell.value = value
+				cell.sequence.Store(pos + 1)
+				return true
+			}
+		} else if diff < 0 {
+			// the cell still holds the value of the previous lap: the queue is full
+			return false
+		}
+		pos = q.enqueuePos.Load()
+	}
+}
+
+func (q *ConcurrentArrayQueue[E]) TryDequeue() (E, bool) {
+	pos := q.dequeuePos.Load()
+	for {
+		cell := &q.cells[pos&q.mask]
+		diff := int64(cell.sequence.Load() - (pos + 1))
+		if diff == 0 {
+			if q.dequeuePos.CompareAndSwap(pos, pos+1) {
+				value := cell.value
+				cell.value = *new(E)
+				cell.sequence.Store(pos + q.mask + 1)
+				return value, true
+			}
+		} else if diff < 0 {
+			// the cell has not been written in this lap yet: the queue is empty
+			return *new(E), false
+		}
+		pos = q.dequeuePos.Load()
+	}
+}
+
+func (q *ConcurrentArrayQueue[E]) String() string {
+	return fmt.Sprintf("ConcurrentArrayQueue[%T](len=%d, cap=%d)", *new(E), q.Count(), q.Cap())
+}
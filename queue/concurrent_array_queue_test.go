@@ -0,0 +1,98 @@
+package queue
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConcurrentArrayQueue_Cap(t *testing.T) {
+	queue := NewConcurrentArrayQueue[int](5)
+	assert.Equal(t, 5, queue.Cap())
+	assert.Len(t, queue.cells, 8)
+	// the capacity is enforced, not the size of the ring
+	for lap := 0; lap < 3; lap++ {
+		for i := 0; i < 5; i++ {
+			assert.True(t, queue.TryEnqueue(i))
+		}
+		assert.False(t, queue.TryEnqueue(5))
+		for i := 0; i < 5; i++ {
+			v, _ := queue.TryDequeue()
+			assert.Equal(t, i, v)
+		}
+	}
+	single := NewConcurrentArrayQueue[int](1)
+	assert.Equal(t, 1, single.Cap())
+	assert.True(t, single.TryEnqueue(1))
+	assert.False(t, single.TryEnqueue(2))
+	assert.Panics(t, func() {
+		NewConcurrentArrayQueue[int](0)
+	})
+}
+
+func TestConcurrentArrayQueue_TryEnqueue(t *testing.T) {
+	queue := NewConcurrentArrayQueue[int](4)
+	for i := 0; i < 4; i++ {
+		assert.True(t, queue.TryEnqueue(i))
+	}
+	assert.False(t, queue.TryEnqueue(4))
+	assert.Equal(t, int64(4), queue.Count())
+}
+
+func TestConcurrentArrayQueue_TryDequeue(t *testing.T) {
+	queue := NewConcurrentArrayQueue[int](4)
+	_, ok := queue.TryDequeue()
+	assert.False(t, ok)
+	for lap := 0; lap < 3; lap++ {
+		for i := 0; i < 4; i++ {
+			queue.TryEnqueue(i)
+		}
+		for i := 0; i < 4; i++ {
+			v, ok := queue.TryDequeue()
+			assert.True(t, ok)
+			assert.Equal(t, i, v)
+		}
+	}
+	assert.True(t, queue.IsEmpty())
+}
+
+func TestConcurrentArrayQueue_Stress(t *testing.T) {
+	const producers, consumers, perProducer = 8, 8, 5000
+	queue := NewConcurrentArrayQueue[int](64)
+	seen := make([]atomic.Int32, producers*perProducer)
+	var consumed atomic.Int64
+	var wg sync.WaitGroup
+	for p := 0; p < producers; p++ {
+		wg.Add(1)
+		go func(p int) {
+			defer wg.Done()
+			for i := 0; i < perProducer; i++ {
+				for !queue.TryEnqueue(p*perProducer + i) {
+					runtime.Gosched()
+				}
+			}
+		}(p)
+	}
+	for c := 0; c < consumers; c++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for consumed.Load() < producers*perProducer {
+				if v, ok := queue.TryDequeue(); ok {
+					seen[v].Add(1)
+					consumed.Add(1)
+				} else {
+					runtime.Gosched()
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	for i := range seen {
+		assert.Equal(t, int32(1), seen[i].Load())
+	}
+	assert.True(t, queue.IsEmpty())
+}
@@ -0,0 +1,206 @@
+package queue
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync/atomic"
+
+	"github.com/gopi-frame/contract/support"
+)
+
+var _ NonBlockingQueue[any] = (*ConcurrentLinkedQueue[any])(nil)
+
+type concurrentLinkedNode[E any] struct {
+	// item is nil once the value has been dequeued or removed
+	item atomic.Pointer[E]
+	next atomic.Pointer[concurrentLinkedNode[E]]
+}
+
+// NewConcurrentLinkedQueue new concurrent linked queue
+func NewConcurrentLinkedQueue[E any](values ...E) *ConcurrentLinkedQueue[E] {
+	queue := new(ConcurrentLinkedQueue[E])
+	sentinel := new(concurrentLinkedNode[E])
+	queue.head.Store(sentinel)
+	queue.tail.Store(sentinel)
+	for _, value := range values {
+		queue.Enqueue(value)
+	}
+	return queue
+}
+
+// ConcurrentLinkedQueue lock-free unbounded queue.
+//
+// It is the Michael-Scott non-blocking queue: the head always points to a
+// sentinel node, producers link new nodes after the tail with a CAS and help
+// lagging tails forward. Removed values are only cleared from their node, which
+// is unlinked once it reaches the head. Count and the traversals are weakly
+// consistent while the queue is accessed concurrently.
+type ConcurrentLinkedQueue[E any] struct {
+	head  atomic.Pointer[concurrentLinkedNode[E]]
+	tail  atomic.Pointer[concurrentLinkedNode[E]]
+	count atomic.Int64
+}
+
+// each calls the callback with the live nodes from the head
+func (q *ConcurrentLinkedQueue[E]) each(callback func(node *concurrentLinkedNode[E], item *E) bool) {
+	for node := q.head.Load().next.Load(); node != nil; node = node.next.Load() {
+		if item := node.item.Load(); item != nil && !callback(node, item) {
+			return
+		}
+	}
+}
+
+func (q *ConcurrentLinkedQueue[E]) Count() int64 {
+	return q.count.Load()
+}
+
+func (q *ConcurrentLinkedQueue[E]) IsEmpty() bool {
+	_, ok := q.Peek()
+	return !ok
+}
+
+func (q *ConcurrentLinkedQueue[E]) IsNotEmpty() bool {
+	return !q.IsEmpty()
+}
+
+func (q *ConcurrentLinkedQueue[E]) Clear() {
+	for {
+		if _, ok := q.Dequeue(); !ok {
+			return
+		}
+	}
+}
+
+func (q *ConcurrentLinkedQueue[E]) Peek() (value E, ok bool) {
+	q.each(func(_ *concurrentLinkedNode[E], item *E) bool {
+		value, ok = *item, true
+		return false
+	})
+	return
+}
+
+func (q *ConcurrentLinkedQueue[E]) Enqueue(value E) bool {
+	node := new(concurrentLinkedNode[E])
+	node.item.Store(&value)
+	q.count.Add(1)
+	for {
+		tail := q.tail.Load()
+		next := tail.next.Load()
+		if tail != q.tail.Load() {
+			continue
+		}
+		if next != nil {
+			// the tail is lagging behind, help it forward
+			q.tail.CompareAndSwap(tail, next)
+			continue
+		}
+		if tail.next.CompareAndSwap(nil, node) {
+			q.tail.CompareAndSwap(tail, node)
+			return true
+		}
+	}
+}
+
+func (q *ConcurrentLinkedQueue[E]) Dequeue() (E, bool) {
+	for {
+		head := q.head.Load()
+		tail := q.tail.Load()
+		next := head.next.Load()
+		if head != q.head.Load() {
+			continue
+		}
+		if next == nil {
+			return *new(E), false
+		}
+		if head == tail {
+			q.tail.CompareAndSwap(tail, next)
+			continue
+		}
+		if q.head.CompareAndSwap(head, next) {
+			// next is the new sentinel, claim its value unless it has been removed
+			if item := next.item.Swap(nil); item != nil {
+				q.count.Add(-1)
+				return *item, true
+			}
+		}
+	}
+}
+
+func (q *ConcurrentLinkedQueue[E]) TryEnqueue(value E) bool {
+	return q.Enqueue(value)
+}
+
+func (q *ConcurrentLinkedQueue[E]) TryDequeue() (E, bool) {
+	return q.Dequeue()
+}
+
+func (q *ConcurrentLinkedQueue[E]) Remove(value E) {
+	q.RemoveWhere(func(item E) bool {
+		return reflect.DeepEqual(item, value)
+	})
+}
+
+func (q *ConcurrentLinkedQueue[E]) RemoveWhere(callback func(value E) bool) {
+	q.each(func(node *concurrentLinkedNode[E], item *E) bool {
+		if callback(*item) && node.item.CompareAndSwap(item, nil) {
+			q.count.Add(-1)
+		}
+		return true
+	})
+}
+
+func (q *ConcurrentLinkedQueue[E]) ToArray() []E {
+	values := []E{}
+	q.each(func(_ *concurrentLinkedNode[E], item *E) bool {
+		values = append(values, *item)
+		return true
+	})
+	return values
+}
+
+func (q *ConcurrentLinkedQueue[E]) ToJSON() ([]byte, error) {
+	return json.Marshal(q.ToArray())
+}
+
+func (q *ConcurrentLinkedQueue[E]) MarshalJSON() ([]byte, error) {
+	return q.ToJSON()
+}
+
+func (q *ConcurrentLinkedQueue[E]) UnmarshalJSON(data []byte) error {
+	values := []E{}
+	if err := json.Unmarshal(data, &values); err != nil {
+		return err
+	}
+	for _, value := range values {
+		q.Enqueue(value)
+	}
+	return nil
+}
+
+func (q *ConcurrentLinkedQueue[E]) String() string {
+	values := q.ToArray()
+	str := new(strings.Builder)
+	str.WriteString(fmt.Sprintf("ConcurrentLinkedQueue[%T](len=%d)", *new(E), len(values)))
+	str.WriteByte('{')
+	str.WriteByte('\n')
+	for index, value := range values {
+		str.WriteByte('\t')
+		if v, ok := any(value).(support.Stringable); ok {
+			str.WriteString(v.String())
+		} else {
+			str.WriteString(fmt.Sprintf("%v", value))
+		}
+		str.WriteByte(',')
+		str.WriteByte('\n')
+		if index >= 4 {
+			break
+		}
+	}
+	if len(values) > 5 {
+		str.WriteString("\t...\n")
+	}
+	str.WriteByte('}')
+	return str.String()
+}
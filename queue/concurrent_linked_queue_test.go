@@ -0,0 +1,116 @@
+package queue
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConcurrentLinkedQueue_Count(t *testing.T) {
+	queue := NewConcurrentLinkedQueue(1, 2, 3)
+	assert.Equal(t, int64(3), queue.Count())
+	assert.True(t, queue.IsNotEmpty())
+	queue.Clear()
+	assert.True(t, queue.IsEmpty())
+	assert.Equal(t, int64(0), queue.Count())
+}
+
+func TestConcurrentLinkedQueue_Peek(t *testing.T) {
+	queue := NewConcurrentLinkedQueue(1, 2, 3)
+	v, ok := queue.Peek()
+	assert.True(t, ok)
+	assert.Equal(t, 1, v)
+	assert.Equal(t, int64(3), queue.Count())
+	_, ok = NewConcurrentLinkedQueue[int]().Peek()
+	assert.False(t, ok)
+}
+
+func TestConcurrentLinkedQueue_Dequeue(t *testing.T) {
+	queue := NewConcurrentLinkedQueue(1, 2, 3)
+	assert.True(t, queue.Enqueue(4))
+	for i := 1; i <= 4; i++ {
+		v, ok := queue.Dequeue()
+		assert.True(t, ok)
+		assert.Equal(t, i, v)
+	}
+	_, ok := queue.TryDequeue()
+	assert.False(t, ok)
+}
+
+func TestConcurrentLinkedQueue_RemoveWhere(t *testing.T) {
+	queue := NewConcurrentLinkedQueue(1, 2, 3, 4, 5)
+	queue.RemoveWhere(func(value int) bool {
+		return value%2 == 0
+	})
+	queue.Remove(5)
+	assert.Equal(t, int64(2), queue.Count())
+	assert.Equal(t, []int{1, 3}, queue.ToArray())
+	v, ok := queue.Dequeue()
+	assert.True(t, ok)
+	assert.Equal(t, 1, v)
+	v, ok = queue.Dequeue()
+	assert.True(t, ok)
+	assert.Equal(t, 3, v)
+	assert.True(t, queue.IsEmpty())
+}
+
+func TestConcurrentLinkedQueue_JSON(t *testing.T) {
+	queue := NewConcurrentLinkedQueue(1, 2, 3)
+	jsonBytes, err := json.Marshal(queue)
+	assert.Nil(t, err)
+	assert.JSONEq(t, `[1,2,3]`, string(jsonBytes))
+	queue = NewConcurrentLinkedQueue[int]()
+	err = json.Unmarshal([]byte(`[1,2,3]`), queue)
+	assert.Nil(t, err)
+	assert.Equal(t, []int{1, 2, 3}, queue.ToArray())
+}
+
+func TestConcurrentLinkedQueue_String(t *testing.T) {
+	queue := NewConcurrentLinkedQueue(1, 2, 3, 4, 5, 6, 7)
+	str := queue.String()
+	pattern := regexp.MustCompile(fmt.Sprintf(`ConcurrentLinkedQueue\[int\]\(len=%d\)\{\n(\t\d+,\n){5}\t(\.){3}\n\}`, queue.Count()))
+	assert.True(t, pattern.Match([]byte(str)))
+}
+
+func TestConcurrentLinkedQueue_Stress(t *testing.T) {
+	const producers, consumers, perProducer = 8, 8, 5000
+	queue := NewConcurrentLinkedQueue[int]()
+	seen := make([]atomic.Int32, producers*perProducer)
+	var consumed atomic.Int64
+	var wg sync.WaitGroup
+	for p := 0; p < producers; p++ {
+		wg.Add(1)
+		go func(p int) {
+			defer wg.Done()
+			for i := 0; i < perProducer; i++ {
+				queue.Enqueue(p*perProducer + i)
+			}
+		}(p)
+	}
+	for c := 0; c < consumers; c++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for consumed.Load() < producers*perProducer {
+				if v, ok := queue.Dequeue(); ok {
+					seen[v].Add(1)
+					consumed.Add(1)
+				} else {
+					runtime.Gosched()
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	for i := range seen {
+		assert.Equal(t, int32(1), seen[i].Load())
+	}
+	assert.True(t, queue.IsEmpty())
+	assert.Equal(t, int64(0), queue.Count())
+}
@@ -3,72 +3,235 @@ package queue
 import (
 	"encoding/json"
 	"fmt"
+	"reflect"
 	"strings"
-	"sync"
 	"time"
 
 	"github.com/gopi-frame/contract/support"
+	"github.com/gopi-frame/support/clock"
 )
 
 var _ support.BlockingQueue[support.Delayable[any]] = (*DelayedQueue[support.Delayable[any], any])(nil)
 
+// DelayedHandle handle of an item scheduled in a delayed queue,
+// it is used to cancel or reschedule the item while it is still pending
+type DelayedHandle[Q any] struct {
+	value   Q
+	until   time.Time
+	pending bool
+	// index heap index of the pending handle
+	index int64
+}
+
+// Value returns the scheduled item
+func (h *DelayedHandle[Q]) Value() Q {
+	return h.value
+}
+
+type delayedHandleComparator[Q any] struct{}
+
+func (c delayedHandleComparator[Q]) Compare(a, b *DelayedHandle[Q]) int {
+	return a.until.Compare(b.until)
+}
+
 // NewDelayedQueue new delayed queue
-func NewDelayedQueue[Q support.Delayable[T], T any]() *DelayedQueue[Q, T] {
+func NewDelayedQueue[Q support.Delayable[T], T any](opts ...Option) *DelayedQueue[Q, T] {
 	queue := new(DelayedQueue[Q, T])
-	queue.items = NewPriorityQueue(queue)
-	queue.takeLock = sync.NewCond(queue.items)
+	queue.clock = newOptions(opts...).clock
+	queue.items = NewPriorityQueue[*DelayedHandle[Q]](delayedHandleComparator[Q]{})
+	queue.items.moved = func(handle *DelayedHandle[Q], index int64) {
+		handle.index = index
+	}
+	queue.available = make(chan struct{})
 	return queue
 }
 
 // DelayedQueue delayed queue
+//
+// Consumers follow the leader/follower pattern: only the leader waits on a timer
+// for the head to expire, the others wait until they are signalled. The lock is
+// released while waiting, and waiters are woken whenever an earlier item
+// becomes the head.
 type DelayedQueue[Q support.Delayable[T], T any] struct {
-	items    *PriorityQueue[Q]
-	takeLock *sync.Cond
+	items     *PriorityQueue[*DelayedHandle[Q]]
+	clock     clock.Clock
+	available chan struct{}
+	leader    clock.Timer
+}
+
+// signal wakes up all the waiting consumers, it must be called with the lock held.
+func (q *DelayedQueue[Q, T]) signal() {
+	close(q.available)
+	q.available = make(chan struct{})
 }
 
-func (q *DelayedQueue[Q, T]) Compare(a, b Q) int {
-	if a.Until().Before(b.Until()) {
-		return -1
-	} else if a.Until().After(b.Until()) {
-		return 1
-	} else {
-		return 0
+// wait releases the lock until signalled or timeout fires, a nil timeout waits
+// until signalled. It must be called with the lock held.
+func (q *DelayedQueue[Q, T]) wait(timeout <-chan time.Time) {
+	available := q.available
+	q.items.Unlock()
+	select {
+	case <-available:
+	case <-timeout:
 	}
+	q.items.Lock()
 }
 
-func (q *DelayedQueue[Q, T]) Count() int64 {
-	if q.items.TryRLock() {
-		defer q.items.RUnlock()
+// lead waits as the leader for the given delay, it must be called with the lock held.
+func (q *DelayedQueue[Q, T]) lead(delay time.Duration) {
+	timer := q.clock.NewTimer(delay)
+	defer timer.Stop()
+	q.leader = timer
+	q.wait(timer.C())
+	if q.leader == timer {
+		q.leader = nil
+	}
+}
+
+// push pushes the handle and wakes the consumers up if it becomes the head.
+func (q *DelayedQueue[Q, T]) push(handle *DelayedHandle[Q]) {
+	q.items.Enqueue(handle)
+	handle.pending = true
+	if head, _ := q.items.Peek(); head == handle {
+		q.leader = nil
+		q.signal()
 	}
+}
+
+func (q *DelayedQueue[Q, T]) enqueue(value Q) *DelayedHandle[Q] {
+	handle := &DelayedHandle[Q]{value: value, until: value.Until()}
+	q.push(handle)
+	return handle
+}
+
+func (q *DelayedQueue[Q, T]) dequeue() (Q, bool) {
+	handle, ok := q.items.Dequeue()
+	if !ok {
+		return *new(Q), false
+	}
+	handle.pending = false
+	if q.leader == nil && q.items.IsNotEmpty() {
+		q.signal()
+	}
+	return handle.value, true
+}
+
+// remove removes the pending handle from the heap in O(log n), it must be called with the lock held.
+func (q *DelayedQueue[Q, T]) remove(handle *DelayedHandle[Q]) bool {
+	// the handle may come from another queue
+	if !handle.pending || handle.index >= q.items.size || q.items.items[handle.index] != handle {
+		return false
+	}
+	q.items.removeAt(handle.index)
+	handle.pending = false
+	return true
+}
+
+func (q *DelayedQueue[Q, T]) Count() int64 {
+	q.items.RLock()
+	defer q.items.RUnlock()
 	return q.items.Count()
 }
 
 func (q *DelayedQueue[Q, T]) IsEmpty() bool {
-	if q.items.TryLock() {
-		defer q.items.Unlock()
-	}
+	q.items.RLock()
+	defer q.items.RUnlock()
 	return q.items.IsEmpty()
 }
 
 func (q *DelayedQueue[Q, T]) IsNotEmpty() bool {
-	if q.items.TryLock() {
-		defer q.items.Unlock()
-	}
+	q.items.RLock()
+	defer q.items.RUnlock()
 	return q.items.IsNotEmpty()
 }
 
 func (q *DelayedQueue[Q, T]) Clear() {
-	if q.items.TryLock() {
-		defer q.items.Unlock()
+	q.items.Lock()
+	defer q.items.Unlock()
+	for _, handle := range q.items.items {
+		handle.pending = false
 	}
 	q.items.Clear()
 }
 
 func (q *DelayedQueue[Q, T]) Peek() (Q, bool) {
-	if q.items.TryLock() {
-		defer q.items.Unlock()
+	q.items.RLock()
+	defer q.items.RUnlock()
+	if handle, ok := q.items.Peek(); ok {
+		return handle.value, true
+	}
+	return *new(Q), false
+}
+
+// Schedule enqueues the value and returns its handle
+func (q *DelayedQueue[Q, T]) Schedule(value Q) *DelayedHandle[Q] {
+	q.items.Lock()
+	defer q.items.Unlock()
+	return q.enqueue(value)
+}
+
+// Cancel removes the pending item of the handle, it returns false when the item
+// has already been dequeued or cancelled
+func (q *DelayedQueue[Q, T]) Cancel(handle *DelayedHandle[Q]) bool {
+	q.items.Lock()
+	defer q.items.Unlock()
+	return q.remove(handle)
+}
+
+// Reschedule changes the expiration time of the pending item of the handle,
+// it returns false when the item has already been dequeued or cancelled
+func (q *DelayedQueue[Q, T]) Reschedule(handle *DelayedHandle[Q], until time.Time) bool {
+	q.items.Lock()
+	defer q.items.Unlock()
+	if !q.remove(handle) {
+		return false
+	}
+	handle.until = until
+	q.push(handle)
+	return true
+}
+
+// DelayOf returns the remaining delay of the pending item of the handle,
+// it returns false when the item has already been dequeued or cancelled
+func (q *DelayedQueue[Q, T]) DelayOf(handle *DelayedHandle[Q]) (time.Duration, bool) {
+	q.items.RLock()
+	defer q.items.RUnlock()
+	if !handle.pending {
+		return 0, false
+	}
+	return handle.until.Sub(q.clock.Now()), true
+}
+
+// ExpiredCount returns the number of items whose delay has expired
+func (q *DelayedQueue[Q, T]) ExpiredCount() int64 {
+	q.items.RLock()
+	defer q.items.RUnlock()
+	now := q.clock.Now()
+	var count func(index int64) int64
+	count = func(index int64) int64 {
+		if index >= q.items.size || q.items.items[index].until.After(now) {
+			return 0
+		}
+		return 1 + count(index*2+1) + count(index*2+2)
+	}
+	return count(0)
+}
+
+// DrainExpired dequeues all the items whose delay has expired, in expiration order
+func (q *DelayedQueue[Q, T]) DrainExpired() []Q {
+	q.items.Lock()
+	defer q.items.Unlock()
+	now := q.clock.Now()
+	values := []Q{}
+	for {
+		head, ok := q.items.Peek()
+		if !ok || head.until.After(now) {
+			break
+		}
+		value, _ := q.dequeue()
+		values = append(values, value)
 	}
-	return q.items.Peek()
+	return values
 }
 
 func (q *DelayedQueue[Q, T]) TryEnqueue(value Q) bool {
@@ -76,12 +239,10 @@ func (q *DelayedQueue[Q, T]) TryEnqueue(value Q) bool {
 }
 
 func (q *DelayedQueue[Q, T]) Enqueue(value Q) bool {
-	if q.items.TryLock() {
-		defer q.items.Unlock()
-	}
-	ok := q.items.Enqueue(value)
-	q.takeLock.Broadcast()
-	return ok
+	q.items.Lock()
+	defer q.items.Unlock()
+	q.enqueue(value)
+	return true
 }
 
 func (q *DelayedQueue[Q, T]) EnqueueTimeout(value Q, duration time.Duration) bool {
@@ -89,80 +250,100 @@ func (q *DelayedQueue[Q, T]) EnqueueTimeout(value Q, duration time.Duration) boo
 }
 
 func (q *DelayedQueue[Q, T]) TryDequeue() (Q, bool) {
-	if q.items.TryLock() {
-		defer q.items.Unlock()
-	}
-	if v, ok := q.items.Peek(); ok && v.Until().Before(time.Now()) {
-		return q.items.Dequeue()
+	q.items.Lock()
+	defer q.items.Unlock()
+	if v, ok := q.items.Peek(); ok && !v.until.After(q.clock.Now()) {
+		return q.dequeue()
 	}
 	return *new(Q), false
 }
 
 func (q *DelayedQueue[Q, T]) Dequeue() (Q, bool) {
-	if q.items.TryLock() {
-		defer q.items.Unlock()
-	}
-	for q.items.IsEmpty() {
-		q.takeLock.Wait()
+	q.items.Lock()
+	defer q.items.Unlock()
+	for {
+		head, ok := q.items.Peek()
+		if !ok {
+			q.wait(nil)
+			continue
+		}
+		delay := head.until.Sub(q.clock.Now())
+		if delay <= 0 {
+			return q.dequeue()
+		}
+		if q.leader != nil {
+			q.wait(nil)
+		} else {
+			q.lead(delay)
+		}
 	}
-	v, _ := q.items.Peek()
-	timer := time.NewTimer(time.Until(v.Until()))
-	defer timer.Stop()
-	<-timer.C
-	return q.items.Dequeue()
 }
 
 func (q *DelayedQueue[Q, T]) DequeueTimeout(duration time.Duration) (Q, bool) {
-	timeout := time.After(duration)
-	done := make(chan struct{})
-	go func() {
-		q.items.Lock()
-		defer q.items.Unlock()
-		for q.items.IsEmpty() {
-			q.takeLock.Wait()
+	deadline := q.clock.Now().Add(duration)
+	q.items.Lock()
+	defer q.items.Unlock()
+	for {
+		head, ok := q.items.Peek()
+		delay := time.Duration(0)
+		if ok {
+			delay = head.until.Sub(q.clock.Now())
+			if delay <= 0 {
+				return q.dequeue()
+			}
 		}
-		if v, ok := q.items.Peek(); ok {
-			timer := time.NewTimer(time.Until(v.Until()))
-			defer timer.Stop()
-			<-timer.C
-			close(done)
+		remaining := deadline.Sub(q.clock.Now())
+		if remaining <= 0 {
+			return *new(Q), false
+		}
+		if !ok || q.leader != nil || delay > remaining {
+			timer := q.clock.NewTimer(remaining)
+			q.wait(timer.C())
+			timer.Stop()
+		} else {
+			q.lead(delay)
 		}
-
-	}()
-	select {
-	case <-timeout:
-		return *new(Q), false
-	case <-done:
-		return q.items.Dequeue()
 	}
 }
 
 func (q *DelayedQueue[Q, T]) Remove(value Q) {
-	if q.items.TryLock() {
-		defer q.items.Unlock()
-	}
-	q.items.Remove(value)
+	q.RemoveWhere(func(item Q) bool {
+		return reflect.DeepEqual(item, value)
+	})
 }
 
 func (q *DelayedQueue[Q, T]) RemoveWhere(callback func(value Q) bool) {
-	if q.items.TryLock() {
-		defer q.items.Unlock()
-	}
-	q.items.RemoveWhere(callback)
+	q.items.Lock()
+	defer q.items.Unlock()
+	q.items.RemoveWhere(func(handle *DelayedHandle[Q]) bool {
+		if callback(handle.value) {
+			handle.pending = false
+			return true
+		}
+		return false
+	})
+}
+
+// values returns the values by expiration time
+func (q *DelayedQueue[Q, T]) values() []Q {
+	values := make([]Q, 0, q.items.size)
+	q.items.Each(func(_ int, handle *DelayedHandle[Q]) bool {
+		values = append(values, handle.value)
+		return true
+	})
+	return values
 }
 
 func (q *DelayedQueue[Q, T]) ToArray() []Q {
-	if q.items.TryLock() {
-		defer q.items.Unlock()
-	}
-	return q.items.ToArray()
+	q.items.RLock()
+	defer q.items.RUnlock()
+	return q.values()
 }
 
 func (q *DelayedQueue[Q, T]) ToJSON() ([]byte, error) {
-	if q.items.TryLock() {
-		defer q.items.Unlock()
-	}
-	return json.Marshal(q.items.ToArray())
+	q.items.RLock()
+	defer q.items.RUnlock()
+	return json.Marshal(q.values())
 }
 
 func (q *DelayedQueue[Q, T]) MarshalJSON() ([]byte, error) {
@@ -170,41 +351,38 @@ func (q *DelayedQueue[Q, T]) MarshalJSON() ([]byte, error) {
 }
 
 func (q *DelayedQueue[Q, T]) UnmarshalJSON(data []byte) error {
-	if q.items.TryLock() {
-		defer q.items.Unlock()
-	}
+	q.items.Lock()
+	defer q.items.Unlock()
 	items := []Q{}
 	err := json.Unmarshal(data, &items)
 	if err != nil {
 		return err
 	}
 	for _, item := range items {
-		q.Enqueue(item)
+		q.enqueue(item)
 	}
 	return nil
 }
 
 func (q *DelayedQueue[Q, T]) String() string {
-	if q.items.TryLock() {
-		defer q.items.Unlock()
-	}
+	q.items.RLock()
+	defer q.items.RUnlock()
 	str := new(strings.Builder)
 	str.WriteString(fmt.Sprintf("DelayedQueue[%T](len=%d)", *new(T), q.items.Count()))
 	str.WriteByte('{')
 	str.WriteByte('\n')
-	items := q.ToArray()
-	for index, item := range items {
+	q.items.Each(func(index int, handle *DelayedHandle[Q]) bool {
 		str.WriteByte('\t')
-		if v, ok := any(item).(support.Stringable); ok {
+		if v, ok := any(handle.value).(support.Stringable); ok {
 			str.WriteString(v.String())
 		} else {
-			str.WriteString(fmt.Sprintf("value: %v, unitl: %v", item.Value(), item.Until().Format("2006-01-02 15:04:05")))
-		}
-		if index >= 4 {
-			break
+			str.WriteString(fmt.Sprintf("value: %v, until: %v", handle.value.Value(), handle.until.Format("2006-01-02 15:04:05")))
 		}
-	}
-	if q.Count() > 5 {
+		str.WriteByte(',')
+		str.WriteByte('\n')
+		return index < 4
+	})
+	if q.items.Count() > 5 {
 		str.WriteString("\t...\n")
 	}
 	str.WriteByte('}')
@@ -4,6 +4,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/gopi-frame/support/clock"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -121,3 +122,157 @@ func TestDelayedQueue_DequeueTimeout(t *testing.T) {
 	assert.True(t, ok)
 	assert.Equal(t, 1, v.Value())
 }
+
+func TestDelayedQueue_DequeueEarlierEnqueued(t *testing.T) {
+	queue := NewDelayedQueue[_delay]()
+	queue.Enqueue(_delay{value: 1, until: time.Now().Add(3 * time.Second)})
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		start := time.Now()
+		queue.Enqueue(_delay{value: 2, until: time.Now().Add(500 * time.Millisecond)})
+		assert.Less(t, time.Since(start), 100*time.Millisecond)
+	}()
+	start := time.Now()
+	v, ok := queue.Dequeue()
+	assert.True(t, ok)
+	assert.Equal(t, 2, v.Value())
+	assert.Less(t, time.Since(start), time.Second)
+	assert.Equal(t, int64(1), queue.Count())
+}
+
+func TestDelayedQueue_DequeueConcurrently(t *testing.T) {
+	queue := NewDelayedQueue[_delay]()
+	results := make(chan int, 3)
+	for i := 0; i < 3; i++ {
+		go func() {
+			v, _ := queue.Dequeue()
+			results <- v.Value()
+		}()
+	}
+	now := time.Now()
+	for i := 3; i > 0; i-- {
+		queue.Enqueue(_delay{value: i, until: now.Add(time.Duration(i) * 200 * time.Millisecond)})
+	}
+	for i := 1; i <= 3; i++ {
+		assert.Equal(t, i, <-results)
+	}
+}
+
+func TestDelayedQueue_Cancel(t *testing.T) {
+	queue := NewDelayedQueue[_delay]()
+	handle := queue.Schedule(_delay{value: 1, until: time.Now().Add(time.Second)})
+	queue.Enqueue(_delay{value: 2, until: time.Now().Add(2 * time.Second)})
+	assert.True(t, queue.Cancel(handle))
+	assert.False(t, queue.Cancel(handle))
+	assert.Equal(t, int64(1), queue.Count())
+	v, ok := queue.Peek()
+	assert.True(t, ok)
+	assert.Equal(t, 2, v.Value())
+}
+
+func TestDelayedQueue_Reschedule(t *testing.T) {
+	queue := NewDelayedQueue[_delay]()
+	handle := queue.Schedule(_delay{value: 1, until: time.Now().Add(time.Hour)})
+	start := time.Now()
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		assert.True(t, queue.Reschedule(handle, time.Now().Add(200*time.Millisecond)))
+	}()
+	v, ok := queue.Dequeue()
+	assert.True(t, ok)
+	assert.Equal(t, 1, v.Value())
+	assert.Less(t, time.Since(start), time.Second)
+	assert.False(t, queue.Reschedule(handle, time.Now()))
+}
+
+func TestDelayedQueue_DelayOf(t *testing.T) {
+	queue := NewDelayedQueue[_delay]()
+	handle := queue.Schedule(_delay{value: 1, until: time.Now().Add(time.Minute)})
+	delay, ok := queue.DelayOf(handle)
+	assert.True(t, ok)
+	assert.InDelta(t, time.Minute, delay, float64(time.Second))
+	queue.Cancel(handle)
+	_, ok = queue.DelayOf(handle)
+	assert.False(t, ok)
+}
+
+func TestDelayedQueue_ExpiredCount(t *testing.T) {
+	queue := NewDelayedQueue[_delay]()
+	for i := 0; i < 5; i++ {
+		queue.Enqueue(_delay{i, time.Now().Add(-time.Duration(i+1) * time.Second)})
+		queue.Enqueue(_delay{i, time.Now().Add(time.Duration(i+1) * time.Second)})
+	}
+	assert.Equal(t, int64(5), queue.ExpiredCount())
+}
+
+func TestDelayedQueue_DrainExpired(t *testing.T) {
+	queue := NewDelayedQueue[_delay]()
+	for i := 0; i < 5; i++ {
+		queue.Enqueue(_delay{i, time.Now().Add(-time.Duration(i+1) * time.Second)})
+		queue.Enqueue(_delay{i + 5, time.Now().Add(time.Duration(i+1) * time.Second)})
+	}
+	values := queue.DrainExpired()
+	assert.Len(t, values, 5)
+	for i, v := range values {
+		assert.Equal(t, 4-i, v.Value())
+	}
+	assert.Equal(t, int64(5), queue.Count())
+	assert.Equal(t, int64(0), queue.ExpiredCount())
+}
+
+func TestDelayedQueue_WithClock(t *testing.T) {
+	fake := clock.NewFakeClock(time.Now())
+	queue := NewDelayedQueue[_delay](WithClock(fake))
+	queue.Enqueue(_delay{value: 1, until: fake.Now().Add(time.Hour)})
+	_, ok := queue.TryDequeue()
+	assert.False(t, ok)
+	done := make(chan int)
+	go func() {
+		v, _ := queue.Dequeue()
+		done <- v.Value()
+	}()
+	fake.BlockUntil(1)
+	fake.Advance(time.Hour)
+	select {
+	case v := <-done:
+		assert.Equal(t, 1, v)
+	case <-time.After(time.Second):
+		assert.Fail(t, "dequeue did not return")
+	}
+}
+
+func TestDelayedQueue_CancelMany(t *testing.T) {
+	fake := clock.NewFakeClock(time.Unix(0, 0))
+	queue := NewDelayedQueue[_delay](WithClock(fake))
+	handles := make([]*DelayedHandle[_delay], 100)
+	for i := range handles {
+		handles[i] = queue.Schedule(_delay{value: i, until: fake.Now().Add(time.Duration(100-i) * time.Second)})
+	}
+	// the handles of the even values are cancelled, a third of the others move to the front
+	for i, handle := range handles {
+		if i%2 == 0 {
+			assert.True(t, queue.Cancel(handle))
+		} else if i%3 == 0 {
+			assert.True(t, queue.Reschedule(handle, fake.Now().Add(time.Duration(i)*time.Millisecond)))
+		}
+	}
+	assert.Equal(t, int64(50), queue.Count())
+	fake.Advance(time.Hour)
+	values := []int{}
+	for _, v := range queue.DrainExpired() {
+		values = append(values, v.Value())
+	}
+	expected := []int{}
+	for i := 3; i < 100; i += 6 {
+		expected = append(expected, i)
+	}
+	for i := 99; i > 0; i -= 2 {
+		if i%3 != 0 {
+			expected = append(expected, i)
+		}
+	}
+	assert.Equal(t, expected, values)
+	for _, handle := range handles {
+		assert.False(t, queue.Cancel(handle))
+	}
+}
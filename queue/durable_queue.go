@@ -0,0 +1,454 @@
+package queue
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/gopi-frame/contract/support"
+)
+
+// ErrClosed is returned when using a closed durable queue
+var ErrClosed = errors.New("queue: durable queue closed")
+
+// ErrNotInFlight is returned when acking or nacking a message which is not in flight
+var ErrNotInFlight = errors.New("queue: message not in flight")
+
+// DefaultSegmentSize size from which a durable queue starts a new log segment
+const DefaultSegmentSize = 16 << 20
+
+// DurableOption option of the durable queue
+type DurableOption[E any] func(q *DurableQueue[E])
+
+// WithCodec sets the codec of the elements, JSONCodec by default
+func WithCodec[E any](codec Codec[E]) DurableOption[E] {
+	return func(q *DurableQueue[E]) {
+		q.codec = codec
+	}
+}
+
+// WithSegmentSize sets the size from which a new log segment is started
+func WithSegmentSize[E any](size int64) DurableOption[E] {
+	return func(q *DurableQueue[E]) {
+		q.segmentSize = size
+	}
+}
+
+// WithSyncWrites makes every write flushed to the disk before it returns,
+// otherwise the log survives the crash of the process but not of the machine
+func WithSyncWrites[E any]() DurableOption[E] {
+	return func(q *DurableQueue[E]) {
+		q.syncWrites = true
+	}
+}
+
+// Message element dequeued from a durable queue, it must be acked once processed
+type Message[E any] struct {
+	ID    uint64
+	Value E
+}
+
+// OpenDurableQueue opens the durable queue stored in the directory, it is created when
+// missing. The elements which were enqueued and not acked are replayed, including the
+// ones in flight when the queue was closed, which are delivered again.
+func OpenDurableQueue[E any](dir string, opts ...DurableOption[E]) (*DurableQueue[E], error) {
+	queue := new(DurableQueue[E])
+	queue.dir = dir
+	queue.codec = JSONCodec[E]{}
+	queue.segmentSize = DefaultSegmentSize
+	queue.items = make(map[uint64]E)
+	queue.locations = make(map[uint64]*walSegment)
+	queue.inflight = make(map[uint64]struct{})
+	queue.nextID = 1
+	for _, opt := range opts {
+		opt(queue)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	if err := queue.replay(); err != nil {
+		return nil, err
+	}
+	queue.removeAcked()
+	return queue, nil
+}
+
+// DurableQueue disk-backed queue with at-least-once delivery.
+//
+// Enqueues and acks are appended to a write-ahead log split into segments.
+// Dequeue hands out a message which stays in flight until it is acked, or nacked
+// to put it back at the head of the queue. The segments whose elements have
+// all been acked are removed, Compact moves the remaining elements of the old
+// segments to the active one so that they can be removed as well.
+type DurableQueue[E any] struct {
+	mu          sync.Mutex
+	dir         string
+	codec       Codec[E]
+	segmentSize int64
+	syncWrites  bool
+	segments    []*walSegment
+	active      *os.File
+	activeSize  int64
+	nextID      uint64
+	items       map[uint64]E
+	locations   map[uint64]*walSegment
+	ready       []uint64
+	inflight    map[uint64]struct{}
+	closed      bool
+}
+
+func (q *DurableQueue[E]) replay() error {
+	segments, err := listWALSegments(q.dir)
+	if err != nil {
+		return err
+	}
+	// the acks are collected first so that the acked elements are never decoded
+	acked := make(map[uint64]struct{})
+	for index, segment := range segments {
+		size, err := segment.read(func(record walRecord) error {
+			q.nextID = max(q.nextID, record.id+1)
+			if record.kind == walAck {
+				acked[record.id] = struct{}{}
+			}
+			return nil
+		})
+		if errors.Is(err, errTornRecord) && index == len(segments)-1 {
+			// the last write was interrupted, drop it
+			err = os.Truncate(segment.path, size)
+		}
+		if err != nil {
+			return fmt.Errorf("queue: replay %s: %w", segment.path, err)
+		}
+		if index == len(segments)-1 {
+			q.activeSize = size
+		}
+	}
+	for _, segment := range segments {
+		_, err := segment.read(func(record walRecord) error {
+			if _, ok := acked[record.id]; ok || record.kind != walEnqueue {
+				return nil
+			}
+			value, err := q.codec.Decode(record.payload)
+			if err != nil {
+				return fmt.Errorf("queue: decode element %d of %s: %w", record.id, segment.path, err)
+			}
+			// a compacted element is found again in a later segment
+			if location, ok := q.locations[record.id]; ok {
+				location.live--
+			}
+			q.items[record.id] = value
+			q.locations[record.id] = segment
+			segment.live++
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("queue: replay %s: %w", segment.path, err)
+		}
+	}
+	for id := range q.items {
+		q.ready = append(q.ready, id)
+	}
+	slices.Sort(q.ready)
+	if len(segments) == 0 {
+		segments = append(segments, newWALSegment(q.dir, 1))
+	}
+	q.segments = segments
+	active, err := os.OpenFile(segments[len(segments)-1].path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	q.active = active
+	return nil
+}
+
+// write appends the record to the active segment, calls the applied callback with the segment
+// once the record is in it, and starts a new segment when it is full. It must be called with the
+// lock held. An error returned after the callback ran means the record is in the log but may not
+// be on the disk yet, or that the next segment could not be started.
+func (q *DurableQueue[E]) write(record walRecord, applied func(segment *walSegment)) error {
+	if q.closed {
+		return ErrClosed
+	}
+	if _, err := q.active.Write(record.encode()); err != nil {
+		// drop what may have been written so that the next records are not appended after a torn one
+		q.active.Truncate(q.activeSize)
+		return err
+	}
+	q.activeSize += int64(walHeaderSize + len(record.payload))
+	applied(q.segments[len(q.segments)-1])
+	var err error
+	if q.syncWrites {
+		err = q.active.Sync()
+	}
+	if q.activeSize >= q.segmentSize {
+		if rollErr := q.roll(); rollErr != nil {
+			// the active segment stays open and the roll is tried again on the next write
+			err = errors.Join(err, fmt.Errorf("queue: start a new segment: %w", rollErr))
+		}
+	}
+	return err
+}
+
+// roll starts a new segment and closes the active one, it must be called with the lock held.
+// The active segment is kept when the new one cannot be created.
+func (q *DurableQueue[E]) roll() error {
+	if err := q.active.Sync(); err != nil {
+		return err
+	}
+	segment := newWALSegment(q.dir, q.segments[len(q.segments)-1].seq+1)
+	active, err := os.OpenFile(segment.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	previous := q.active
+	q.segments = append(q.segments, segment)
+	q.active = active
+	q.activeSize = 0
+	return previous.Close()
+}
+
+// removeAcked removes the oldest segments as long as all their elements have been acked,
+// it must be called with the lock held. Only a prefix of the log is removed so that no ack
+// of a remaining element is lost.
+func (q *DurableQueue[E]) removeAcked() {
+	for len(q.segments) > 1 && q.segments[0].live == 0 {
+		if err := os.Remove(q.segments[0].path); err != nil && !os.IsNotExist(err) {
+			return
+		}
+		q.segments = q.segments[1:]
+	}
+}
+
+// Count returns the number of elements ready to be dequeued
+func (q *DurableQueue[E]) Count() int64 {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return int64(len(q.ready))
+}
+
+// InFlight returns the number of dequeued messages not acked yet
+func (q *DurableQueue[E]) InFlight() int64 {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return int64(len(q.inflight))
+}
+
+func (q *DurableQueue[E]) IsEmpty() bool {
+	return q.Count() == 0
+}
+
+func (q *DurableQueue[E]) IsNotEmpty() bool {
+	return !q.IsEmpty()
+}
+
+func (q *DurableQueue[E]) Peek() (E, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(q.ready) == 0 {
+		return *new(E), false
+	}
+	return q.items[q.ready[0]], true
+}
+
+// Enqueue appends the element to the log and to the queue. The element is queued even when
+// the error comes from flushing the log or from starting a new segment once it was written.
+func (q *DurableQueue[E]) Enqueue(value E) error {
+	payload, err := q.codec.Encode(value)
+	if err != nil {
+		return err
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	id := q.nextID
+	return q.write(walRecord{kind: walEnqueue, id: id, payload: payload}, func(segment *walSegment) {
+		q.nextID++
+		q.items[id] = value
+		q.locations[id] = segment
+		segment.live++
+		q.ready = append(q.ready, id)
+	})
+}
+
+// Dequeue takes the element at the head of the queue, it stays in flight until it is acked or nacked
+func (q *DurableQueue[E]) Dequeue() (Message[E], bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closed || len(q.ready) == 0 {
+		return Message[E]{}, false
+	}
+	id := q.ready[0]
+	q.ready = q.ready[1:]
+	q.inflight[id] = struct{}{}
+	return Message[E]{ID: id, Value: q.items[id]}, true
+}
+
+// Ack marks the message as processed, it is removed from the log
+func (q *DurableQueue[E]) Ack(id uint64) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if _, ok := q.inflight[id]; !ok {
+		return ErrNotInFlight
+	}
+	err := q.write(walRecord{kind: walAck, id: id}, func(*walSegment) {
+		delete(q.inflight, id)
+		delete(q.items, id)
+		q.locations[id].live--
+		delete(q.locations, id)
+	})
+	q.removeAcked()
+	return err
+}
+
+// Nack puts the message back at the head of the queue to deliver it again
+func (q *DurableQueue[E]) Nack(id uint64) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closed {
+		return ErrClosed
+	}
+	if _, ok := q.inflight[id]; !ok {
+		return ErrNotInFlight
+	}
+	delete(q.inflight, id)
+	q.ready = slices.Insert(q.ready, 0, id)
+	return nil
+}
+
+// Compact rewrites the elements not acked yet of the old segments into the active one
+// and removes the old segments
+func (q *DurableQueue[E]) Compact() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closed {
+		return ErrClosed
+	}
+	old := slices.Clone(q.segments[:len(q.segments)-1])
+	ids := []uint64{}
+	for id, segment := range q.locations {
+		if slices.Contains(old, segment) {
+			ids = append(ids, id)
+		}
+	}
+	slices.Sort(ids)
+	for _, id := range ids {
+		payload, err := q.codec.Encode(q.items[id])
+		if err != nil {
+			return err
+		}
+		err = q.write(walRecord{kind: walEnqueue, id: id, payload: payload}, func(segment *walSegment) {
+			q.locations[id].live--
+			q.locations[id] = segment
+			segment.live++
+		})
+		if err != nil {
+			return err
+		}
+	}
+	// the copies must be on the disk before the originals are removed
+	if err := q.active.Sync(); err != nil {
+		return err
+	}
+	q.removeAcked()
+	return nil
+}
+
+// Segments returns the number of segments of the log
+func (q *DurableQueue[E]) Segments() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.segments)
+}
+
+// Sync flushes the log to the disk
+func (q *DurableQueue[E]) Sync() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closed {
+		return ErrClosed
+	}
+	return q.active.Sync()
+}
+
+// Close flushes and closes the log, the messages in flight are delivered again once reopened
+func (q *DurableQueue[E]) Close() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closed {
+		return nil
+	}
+	q.closed = true
+	if err := q.active.Sync(); err != nil {
+		q.active.Close()
+		return err
+	}
+	return q.active.Close()
+}
+
+// values returns the elements ready to be dequeued, it must be called with the lock held
+func (q *DurableQueue[E]) values() []E {
+	values := make([]E, 0, len(q.ready))
+	for _, id := range q.ready {
+		values = append(values, q.items[id])
+	}
+	return values
+}
+
+// ToArray returns the elements ready to be dequeued
+func (q *DurableQueue[E]) ToArray() []E {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.values()
+}
+
+func (q *DurableQueue[E]) ToJSON() ([]byte, error) {
+	return json.Marshal(q.ToArray())
+}
+
+func (q *DurableQueue[E]) MarshalJSON() ([]byte, error) {
+	return q.ToJSON()
+}
+
+// UnmarshalJSON enqueues the elements
+func (q *DurableQueue[E]) UnmarshalJSON(data []byte) error {
+	values := make([]E, 0)
+	if err := json.Unmarshal(data, &values); err != nil {
+		return err
+	}
+	for _, value := range values {
+		if err := q.Enqueue(value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (q *DurableQueue[E]) String() string {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	str := new(strings.Builder)
+	str.WriteString(fmt.Sprintf("DurableQueue[%T](len=%d)", *new(E), len(q.ready)))
+	str.WriteByte('{')
+	str.WriteByte('\n')
+	for index, value := range q.values() {
+		str.WriteByte('\t')
+		if v, ok := any(value).(support.Stringable); ok {
+			str.WriteString(v.String())
+		} else {
+			str.WriteString(fmt.Sprintf("%v", value))
+		}
+		str.WriteByte(',')
+		str.WriteByte('\n')
+		if index >= 4 {
+			break
+		}
+	}
+	if len(q.ready) > 5 {
+		str.WriteString("\t...\n")
+	}
+	str.WriteByte('}')
+	return str.String()
+}
@@ -0,0 +1,263 @@
+package queue
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDurableQueue_Enqueue(t *testing.T) {
+	queue, err := OpenDurableQueue[string](t.TempDir())
+	assert.Nil(t, err)
+	defer queue.Close()
+	assert.True(t, queue.IsEmpty())
+	for _, v := range []string{"a", "b", "c"} {
+		assert.Nil(t, queue.Enqueue(v))
+	}
+	assert.Equal(t, int64(3), queue.Count())
+	v, ok := queue.Peek()
+	assert.True(t, ok)
+	assert.Equal(t, "a", v)
+	message, ok := queue.Dequeue()
+	assert.True(t, ok)
+	assert.Equal(t, "a", message.Value)
+	assert.Equal(t, int64(2), queue.Count())
+	assert.Equal(t, int64(1), queue.InFlight())
+	assert.Nil(t, queue.Ack(message.ID))
+	assert.ErrorIs(t, queue.Ack(message.ID), ErrNotInFlight)
+	assert.Equal(t, int64(0), queue.InFlight())
+}
+
+func TestDurableQueue_Nack(t *testing.T) {
+	queue, err := OpenDurableQueue[int](t.TempDir())
+	assert.Nil(t, err)
+	defer queue.Close()
+	queue.Enqueue(1)
+	queue.Enqueue(2)
+	message, _ := queue.Dequeue()
+	assert.Nil(t, queue.Nack(message.ID))
+	assert.ErrorIs(t, queue.Nack(message.ID), ErrNotInFlight)
+	again, ok := queue.Dequeue()
+	assert.True(t, ok)
+	assert.Equal(t, message, again)
+}
+
+func TestDurableQueue_Replay(t *testing.T) {
+	dir := t.TempDir()
+	queue, err := OpenDurableQueue[int](dir)
+	assert.Nil(t, err)
+	for i := 1; i <= 5; i++ {
+		queue.Enqueue(i)
+	}
+	acked, _ := queue.Dequeue()
+	queue.Ack(acked.ID)
+	// in flight when closed, it is delivered again
+	queue.Dequeue()
+	assert.Nil(t, queue.Close())
+	assert.ErrorIs(t, queue.Enqueue(6), ErrClosed)
+
+	queue, err = OpenDurableQueue[int](dir)
+	assert.Nil(t, err)
+	defer queue.Close()
+	assert.Equal(t, []int{2, 3, 4, 5}, queue.ToArray())
+	queue.Enqueue(6)
+	message, _ := queue.Dequeue()
+	assert.Equal(t, 2, message.Value)
+	assert.Equal(t, []int{3, 4, 5, 6}, queue.ToArray())
+}
+
+func TestDurableQueue_TornRecord(t *testing.T) {
+	dir := t.TempDir()
+	queue, err := OpenDurableQueue[int](dir)
+	assert.Nil(t, err)
+	queue.Enqueue(1)
+	queue.Enqueue(2)
+	queue.Close()
+	segments, _ := listWALSegments(dir)
+	info, _ := os.Stat(segments[0].path)
+	// simulate a crash in the middle of the last write
+	assert.Nil(t, os.Truncate(segments[0].path, info.Size()-3))
+
+	queue, err = OpenDurableQueue[int](dir)
+	assert.Nil(t, err)
+	assert.Equal(t, []int{1}, queue.ToArray())
+	queue.Enqueue(3)
+	queue.Close()
+	queue, err = OpenDurableQueue[int](dir)
+	assert.Nil(t, err)
+	defer queue.Close()
+	assert.Equal(t, []int{1, 3}, queue.ToArray())
+}
+
+func TestDurableQueue_Segments(t *testing.T) {
+	dir := t.TempDir()
+	queue, err := OpenDurableQueue[int](dir, WithSegmentSize[int](64), WithSyncWrites[int]())
+	assert.Nil(t, err)
+	for i := 0; i < 20; i++ {
+		queue.Enqueue(i)
+	}
+	assert.Greater(t, queue.Segments(), 5)
+	// the fully acked segments at the head of the log are removed
+	for i := 0; i < 19; i++ {
+		message, _ := queue.Dequeue()
+		assert.Nil(t, queue.Ack(message.ID))
+	}
+	assert.Greater(t, mustSegments(t, dir)[0].seq, uint64(4))
+	assert.Nil(t, queue.Compact())
+	assert.LessOrEqual(t, queue.Segments(), 2)
+	queue.Close()
+	queue, err = OpenDurableQueue[int](dir)
+	assert.Nil(t, err)
+	defer queue.Close()
+	assert.Equal(t, []int{19}, queue.ToArray())
+}
+
+func TestDurableQueue_RollFailure(t *testing.T) {
+	dir := t.TempDir()
+	queue, err := OpenDurableQueue[int](dir, WithSegmentSize[int](64))
+	assert.Nil(t, err)
+	// the next segment cannot be created while a directory has its name
+	next := newWALSegment(dir, 2).path
+	assert.Nil(t, os.Mkdir(next, 0o755))
+	failed := 0
+	for i := 1; i <= 5; i++ {
+		if queue.Enqueue(i) != nil {
+			failed++
+		}
+	}
+	assert.Greater(t, failed, 0)
+	// the elements were written all the same, to the segment which stayed active
+	assert.Equal(t, []int{1, 2, 3, 4, 5}, queue.ToArray())
+	assert.Equal(t, 1, queue.Segments())
+	assert.Nil(t, os.Remove(next))
+	assert.Nil(t, queue.Enqueue(6))
+	assert.Equal(t, 2, queue.Segments())
+	assert.Nil(t, queue.Close())
+
+	queue, err = OpenDurableQueue[int](dir)
+	assert.Nil(t, err)
+	defer queue.Close()
+	ids := map[uint64]bool{}
+	for i := 1; i <= 6; i++ {
+		message, ok := queue.Dequeue()
+		assert.True(t, ok)
+		assert.Equal(t, i, message.Value)
+		assert.False(t, ids[message.ID])
+		ids[message.ID] = true
+	}
+}
+
+func TestDurableQueue_Compact(t *testing.T) {
+	dir := t.TempDir()
+	queue, err := OpenDurableQueue[int](dir, WithSegmentSize[int](64))
+	assert.Nil(t, err)
+	for i := 0; i < 20; i++ {
+		queue.Enqueue(i)
+	}
+	// the first element is never acked, which keeps all the segments
+	queue.Dequeue()
+	messages := []Message[int]{}
+	for i := 1; i < 20; i++ {
+		message, _ := queue.Dequeue()
+		messages = append(messages, message)
+	}
+	for _, message := range messages {
+		if message.Value%2 == 0 {
+			assert.Nil(t, queue.Ack(message.ID))
+		} else {
+			assert.Nil(t, queue.Nack(message.ID))
+		}
+	}
+	segments := queue.Segments()
+	assert.Nil(t, queue.Compact())
+	assert.Less(t, queue.Segments(), segments)
+	queue.Close()
+	files, _ := filepath.Glob(filepath.Join(dir, "*.wal"))
+	assert.Len(t, files, len(mustSegments(t, dir)))
+
+	queue, err = OpenDurableQueue[int](dir)
+	assert.Nil(t, err)
+	defer queue.Close()
+	expected := []int{0}
+	for i := 1; i < 20; i += 2 {
+		expected = append(expected, i)
+	}
+	assert.Equal(t, expected, queue.ToArray())
+}
+
+func mustSegments(t *testing.T, dir string) []*walSegment {
+	segments, err := listWALSegments(dir)
+	assert.Nil(t, err)
+	return segments
+}
+
+type upperCodec struct{}
+
+func (upperCodec) Encode(value int) ([]byte, error) {
+	return []byte(strconv.Itoa(value)), nil
+}
+
+func (upperCodec) Decode(data []byte) (int, error) {
+	return strconv.Atoi(string(data))
+}
+
+func TestDurableQueue_Codec(t *testing.T) {
+	dir := t.TempDir()
+	queue, err := OpenDurableQueue[int](dir, WithCodec[int](upperCodec{}))
+	assert.Nil(t, err)
+	queue.Enqueue(42)
+	queue.Close()
+	queue, err = OpenDurableQueue[int](dir, WithCodec[int](upperCodec{}))
+	assert.Nil(t, err)
+	defer queue.Close()
+	assert.Equal(t, []int{42}, queue.ToArray())
+}
+
+// poisonCodec encodes the negative values as a payload which cannot be decoded
+type poisonCodec struct {
+	upperCodec
+}
+
+func (c poisonCodec) Encode(value int) ([]byte, error) {
+	if value < 0 {
+		return []byte("poison"), nil
+	}
+	return c.upperCodec.Encode(value)
+}
+
+func TestDurableQueue_AckedPoison(t *testing.T) {
+	dir := t.TempDir()
+	queue, err := OpenDurableQueue[int](dir, WithCodec[int](poisonCodec{}))
+	assert.Nil(t, err)
+	queue.Enqueue(-1)
+	queue.Enqueue(1)
+	message, _ := queue.Dequeue()
+	assert.Nil(t, queue.Ack(message.ID))
+	queue.Close()
+	// the acked element is not decoded again
+	queue, err = OpenDurableQueue[int](dir, WithCodec[int](poisonCodec{}))
+	assert.Nil(t, err)
+	assert.Equal(t, []int{1}, queue.ToArray())
+	queue.Enqueue(-1)
+	queue.Close()
+	_, err = OpenDurableQueue[int](dir, WithCodec[int](poisonCodec{}))
+	assert.ErrorContains(t, err, "decode element")
+}
+
+func TestDurableQueue_JSON(t *testing.T) {
+	queue, err := OpenDurableQueue[int](t.TempDir())
+	assert.Nil(t, err)
+	defer queue.Close()
+	assert.Nil(t, json.Unmarshal([]byte(`[1,2,3,4,5,6]`), queue))
+	jsonBytes, err := json.Marshal(queue)
+	assert.Nil(t, err)
+	assert.JSONEq(t, `[1,2,3,4,5,6]`, string(jsonBytes))
+	pattern := regexp.MustCompile(fmt.Sprintf(`DurableQueue\[int\]\(len=%d\)\{\n(\t\d+,\n){5}\t(\.){3}\n\}`, queue.Count()))
+	assert.True(t, pattern.Match([]byte(queue.String())))
+}
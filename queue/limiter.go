@@ -0,0 +1,180 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gopi-frame/support/clock"
+)
+
+// ErrLimitExceeded is returned when a leaky bucket is full
+var ErrLimitExceeded = errors.New("queue: rate limit exceeded")
+
+// Limiter limits the rate of events
+type Limiter interface {
+	// Allow reports whether an event may happen now, the event is counted when it does
+	Allow() bool
+	// Wait blocks until an event may happen or the context is done
+	Wait(ctx context.Context) error
+}
+
+// sleep waits on a timer of the clock until the duration elapses or the context is done
+func sleep(ctx context.Context, c clock.Clock, duration time.Duration) error {
+	if duration <= 0 {
+		return nil
+	}
+	timer := c.NewTimer(duration)
+	defer timer.Stop()
+	select {
+	case <-timer.C():
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// NewTokenBucket new token bucket refilled with rate tokens per second and holding at most burst tokens,
+// it starts full
+func NewTokenBucket(rate float64, burst int, opts ...Option) *TokenBucket {
+	if rate <= 0 || burst <= 0 {
+		panic(fmt.Sprintf("queue: invalid token bucket rate %v or burst %d", rate, burst))
+	}
+	options := newOptions(opts...)
+	bucket := new(TokenBucket)
+	bucket.clock = options.clock
+	bucket.rate = rate
+	bucket.burst = float64(burst)
+	bucket.tokens = bucket.burst
+	bucket.last = bucket.clock.Now()
+	return bucket
+}
+
+// TokenBucket token bucket limiter, it allows bursts of up to burst events and rate events per second on average.
+//
+// A waiter reserves its token right away, the count of tokens goes negative, so the
+// waiters are served in order; a waiter whose context is done gives its token back.
+type TokenBucket struct {
+	mu     sync.Mutex
+	clock  clock.Clock
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// refill adds the tokens produced since the last refill, it must be called with the lock held
+func (b *TokenBucket) refill(now time.Time) {
+	if elapsed := now.Sub(b.last); elapsed > 0 {
+		b.tokens = min(b.burst, b.tokens+elapsed.Seconds()*b.rate)
+		b.last = now
+	}
+}
+
+// Tokens returns the number of available tokens, it is negative while there are waiters
+func (b *TokenBucket) Tokens() float64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.refill(b.clock.Now())
+	return b.tokens
+}
+
+func (b *TokenBucket) Allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.refill(b.clock.Now())
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+func (b *TokenBucket) Wait(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	b.mu.Lock()
+	b.refill(b.clock.Now())
+	b.tokens--
+	delay := time.Duration(0)
+	if b.tokens < 0 {
+		delay = time.Duration(-b.tokens / b.rate * float64(time.Second))
+	}
+	b.mu.Unlock()
+	if err := sleep(ctx, b.clock, delay); err != nil {
+		b.mu.Lock()
+		b.refill(b.clock.Now())
+		b.tokens = min(b.burst, b.tokens+1)
+		b.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// NewLeakyBucket new leaky bucket letting rate events per second through at an even pace,
+// at most capacity events may wait for their turn, zero means no limit
+func NewLeakyBucket(rate float64, capacity int, opts ...Option) *LeakyBucket {
+	if rate <= 0 || capacity < 0 {
+		panic(fmt.Sprintf("queue: invalid leaky bucket rate %v or capacity %d", rate, capacity))
+	}
+	options := newOptions(opts...)
+	bucket := new(LeakyBucket)
+	bucket.clock = options.clock
+	bucket.interval = time.Duration(float64(time.Second) / rate)
+	bucket.capacity = capacity
+	return bucket
+}
+
+// LeakyBucket leaky bucket limiter, it spaces the events by a fixed interval so that
+// bursts are smoothed out instead of being let through
+type LeakyBucket struct {
+	mu       sync.Mutex
+	clock    clock.Clock
+	interval time.Duration
+	capacity int
+	next     time.Time
+}
+
+func (b *LeakyBucket) Allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	now := b.clock.Now()
+	if b.next.After(now) {
+		return false
+	}
+	b.next = now.Add(b.interval)
+	return true
+}
+
+// Wait blocks until the turn of the event comes or the context is done,
+// it returns ErrLimitExceeded without waiting when the bucket is full
+func (b *LeakyBucket) Wait(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	b.mu.Lock()
+	now := b.clock.Now()
+	at := now
+	if b.next.After(now) {
+		at = b.next
+	}
+	if b.capacity > 0 && at.Sub(now) > time.Duration(b.capacity)*b.interval {
+		b.mu.Unlock()
+		return ErrLimitExceeded
+	}
+	b.next = at.Add(b.interval)
+	b.mu.Unlock()
+	if err := sleep(ctx, b.clock, at.Sub(now)); err != nil {
+		b.mu.Lock()
+		// the turn can only be given back while no later event took the following one
+		if b.next.Equal(at.Add(b.interval)) {
+			b.next = at
+		}
+		b.mu.Unlock()
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package queue
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gopi-frame/support/clock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenBucket_Allow(t *testing.T) {
+	fake := clock.NewFakeClock(time.Now())
+	bucket := NewTokenBucket(2, 3, WithClock(fake))
+	for i := 0; i < 3; i++ {
+		assert.True(t, bucket.Allow())
+	}
+	assert.False(t, bucket.Allow())
+	fake.Advance(500 * time.Millisecond)
+	assert.True(t, bucket.Allow())
+	assert.False(t, bucket.Allow())
+	fake.Advance(time.Hour)
+	assert.Equal(t, float64(3), bucket.Tokens())
+}
+
+func TestTokenBucket_Wait(t *testing.T) {
+	fake := clock.NewFakeClock(time.Now())
+	bucket := NewTokenBucket(1, 1, WithClock(fake))
+	assert.Nil(t, bucket.Wait(context.Background()))
+	done := make(chan error)
+	go func() {
+		done <- bucket.Wait(context.Background())
+	}()
+	fake.BlockUntil(1)
+	fake.Advance(999 * time.Millisecond)
+	select {
+	case <-done:
+		t.Fatal("the token was given before it was produced")
+	default:
+	}
+	fake.Advance(time.Millisecond)
+	assert.Nil(t, <-done)
+}
+
+func TestTokenBucket_WaitCancel(t *testing.T) {
+	fake := clock.NewFakeClock(time.Now())
+	bucket := NewTokenBucket(1, 1, WithClock(fake))
+	bucket.Allow()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- bucket.Wait(ctx)
+	}()
+	fake.BlockUntil(1)
+	assert.Equal(t, float64(-1), bucket.Tokens())
+	cancel()
+	assert.ErrorIs(t, <-done, context.Canceled)
+	// the reserved token is given back
+	assert.Equal(t, float64(0), bucket.Tokens())
+	assert.ErrorIs(t, bucket.Wait(ctx), context.Canceled)
+}
+
+func TestLeakyBucket_Allow(t *testing.T) {
+	fake := clock.NewFakeClock(time.Now())
+	bucket := NewLeakyBucket(10, 0, WithClock(fake))
+	assert.True(t, bucket.Allow())
+	assert.False(t, bucket.Allow())
+	fake.Advance(100 * time.Millisecond)
+	assert.True(t, bucket.Allow())
+}
+
+func TestLeakyBucket_Wait(t *testing.T) {
+	fake := clock.NewFakeClock(time.Now())
+	bucket := NewLeakyBucket(10, 2, WithClock(fake))
+	assert.Nil(t, bucket.Wait(context.Background()))
+	done := make(chan error, 2)
+	for i := 0; i < 2; i++ {
+		go func() {
+			done <- bucket.Wait(context.Background())
+		}()
+	}
+	fake.BlockUntil(2)
+	// the bucket holds two waiting events
+	assert.ErrorIs(t, bucket.Wait(context.Background()), ErrLimitExceeded)
+	fake.Advance(100 * time.Millisecond)
+	assert.Nil(t, <-done)
+	select {
+	case <-done:
+		t.Fatal("the burst was not smoothed")
+	default:
+	}
+	fake.Advance(100 * time.Millisecond)
+	assert.Nil(t, <-done)
+}
+
+func TestLeakyBucket_WaitCancel(t *testing.T) {
+	fake := clock.NewFakeClock(time.Now())
+	bucket := NewLeakyBucket(10, 0, WithClock(fake))
+	bucket.Allow()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- bucket.Wait(ctx)
+	}()
+	fake.BlockUntil(1)
+	cancel()
+	assert.ErrorIs(t, <-done, context.Canceled)
+	fake.Advance(100 * time.Millisecond)
+	assert.True(t, bucket.Allow())
+}
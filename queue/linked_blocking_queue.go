@@ -3,197 +3,297 @@ package queue
 import (
 	"encoding/json"
 	"fmt"
+	"reflect"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gopi-frame/contract/support"
-	"github.com/gopi-frame/exception"
-	"github.com/gopi-frame/future"
-	"github.com/gopi-frame/support/lists"
+	"github.com/gopi-frame/support/internal/syncutil"
 )
 
+var _ support.BlockingQueue[any] = (*LinkedBlockingQueue[any])(nil)
+var _ StatsProvider = (*LinkedBlockingQueue[any])(nil)
+
+type linkedBlockingNode[E any] struct {
+	value E
+	next  *linkedBlockingNode[E]
+}
+
 // NewLinkedBlockingQueue new linked blocking queue
 func NewLinkedBlockingQueue[E any](cap int) *LinkedBlockingQueue[E] {
 	queue := new(LinkedBlockingQueue[E])
-	queue.items = lists.NewLinkedList[E]()
-	queue.takeLock = sync.NewCond(queue.items)
-	queue.putLock = sync.NewCond(queue.items)
-	queue.cap = cap
+	queue.cap = int64(cap)
+	queue.head = new(linkedBlockingNode[E])
+	queue.last = queue.head
+	queue.notEmpty = sync.NewCond(&queue.takeLock)
+	queue.notFull = sync.NewCond(&queue.putLock)
 	return queue
 }
 
 // LinkedBlockingQueue linked blocking queue
+//
+// It is a two-lock queue: producers only hold the put lock to append after the
+// last node, consumers only hold the take lock to unlink the node after the
+// head sentinel, and the count is shared atomically, so producers and consumers
+// do not contend with each other. Operations on the whole queue hold both locks.
 type LinkedBlockingQueue[E any] struct {
-	items    *lists.LinkedList[E]
-	cap      int
-	takeLock *sync.Cond
-	putLock  *sync.Cond
+	cap      int64
+	count    atomic.Int64
+	head     *linkedBlockingNode[E]
+	last     *linkedBlockingNode[E]
+	takeLock sync.Mutex
+	notEmpty *sync.Cond
+	putLock  sync.Mutex
+	notFull  *sync.Cond
+	stats    stats
 }
 
-func (q *LinkedBlockingQueue[E]) Count() int64 {
-	if q.items.TryRLock() {
-		defer q.items.RUnlock()
+func (q *LinkedBlockingQueue[E]) fullyLock() {
+	q.putLock.Lock()
+	q.takeLock.Lock()
+}
+
+func (q *LinkedBlockingQueue[E]) fullyUnlock() {
+	q.takeLock.Unlock()
+	q.putLock.Unlock()
+}
+
+func (q *LinkedBlockingQueue[E]) signalNotEmpty() {
+	q.takeLock.Lock()
+	defer q.takeLock.Unlock()
+	q.notEmpty.Signal()
+}
+
+func (q *LinkedBlockingQueue[E]) signalNotFull() {
+	q.putLock.Lock()
+	defer q.putLock.Unlock()
+	q.notFull.Signal()
+}
+
+// enqueue links the node at the end of the queue, it must be called with the put lock held.
+// It returns the count before the node was added.
+func (q *LinkedBlockingQueue[E]) enqueue(value E) int64 {
+	node := &linkedBlockingNode[E]{value: value}
+	q.last.next = node
+	q.last = node
+	q.stats.enqueued.Add(1)
+	count := q.count.Add(1)
+	if count < q.cap {
+		q.notFull.Signal()
 	}
-	return q.items.Count()
+	return count - 1
 }
 
-func (q *LinkedBlockingQueue[E]) IsEmpty() bool {
-	if q.items.TryRLock() {
-		defer q.items.RUnlock()
+// dequeue unlinks the first node of the queue, it must be called with the take lock held.
+// It returns the value and the count before the node was removed.
+func (q *LinkedBlockingQueue[E]) dequeue() (E, int64) {
+	first := q.head.next
+	q.head.next = nil
+	q.head = first
+	value := first.value
+	first.value = *new(E)
+	q.stats.dequeued.Add(1)
+	count := q.count.Add(-1)
+	if count > 0 {
+		q.notEmpty.Signal()
 	}
-	return q.items.IsEmpty()
+	return value, count + 1
+}
+
+// Stats returns the stats of the queue
+func (q *LinkedBlockingQueue[E]) Stats() Stats {
+	return q.stats.snapshot(q.count.Load(), int64(q.cap))
+}
+
+func (q *LinkedBlockingQueue[E]) Count() int64 {
+	return q.count.Load()
+}
+
+func (q *LinkedBlockingQueue[E]) IsEmpty() bool {
+	return q.Count() == 0
 }
 
 func (q *LinkedBlockingQueue[E]) IsNotEmpty() bool {
-	if q.items.TryRLock() {
-		defer q.items.RUnlock()
-	}
-	return q.items.IsNotEmpty()
+	return !q.IsEmpty()
 }
 
 func (q *LinkedBlockingQueue[E]) Clear() {
-	if q.items.TryLock() {
-		defer q.items.Unlock()
-	}
-	q.items.Clear()
+	q.fullyLock()
+	defer q.fullyUnlock()
+	q.head.next = nil
+	q.last = q.head
+	q.stats.removed.Add(q.count.Swap(0))
+	q.notFull.Broadcast()
 }
 
 func (q *LinkedBlockingQueue[E]) Peek() (E, bool) {
-	if q.items.TryRLock() {
-		defer q.items.RUnlock()
-	}
-	if q.items.IsEmpty() {
+	q.takeLock.Lock()
+	defer q.takeLock.Unlock()
+	if q.count.Load() == 0 {
 		return *new(E), false
 	}
-	return q.items.First()
+	return q.head.next.value, true
 }
 
 func (q *LinkedBlockingQueue[E]) TryEnqueue(value E) bool {
-	if q.items.TryLock() {
-		defer q.items.Unlock()
-	}
-	if int64(q.cap) == q.items.Count() {
+	q.putLock.Lock()
+	if q.count.Load() == q.cap {
+		q.putLock.Unlock()
 		return false
 	}
-	q.items.Push(value)
-	q.takeLock.Broadcast()
+	count := q.enqueue(value)
+	q.putLock.Unlock()
+	if count == 0 {
+		q.signalNotEmpty()
+	}
 	return true
 }
 
 func (q *LinkedBlockingQueue[E]) TryDequeue() (E, bool) {
-	if q.items.TryLock() {
-		defer q.items.Unlock()
-	}
-	if q.items.IsEmpty() {
+	q.takeLock.Lock()
+	if q.count.Load() == 0 {
+		q.takeLock.Unlock()
 		return *new(E), false
 	}
-	value, ok := q.items.Shift()
-	q.putLock.Broadcast()
-	return value, ok
+	value, count := q.dequeue()
+	q.takeLock.Unlock()
+	if count == q.cap {
+		q.signalNotFull()
+	}
+	return value, true
 }
 
 func (q *LinkedBlockingQueue[E]) Enqueue(value E) bool {
-	if q.items.TryLock() {
-		defer q.items.Unlock()
+	q.putLock.Lock()
+	if q.count.Load() == q.cap {
+		done := q.stats.blockProducer()
+		for q.count.Load() == q.cap {
+			q.notFull.Wait()
+		}
+		done()
 	}
-	for int64(q.cap) == q.items.Count() {
-		q.putLock.Wait()
+	count := q.enqueue(value)
+	q.putLock.Unlock()
+	if count == 0 {
+		q.signalNotEmpty()
 	}
-	q.items.Push(value)
-	q.takeLock.Broadcast()
 	return true
 }
 
 func (q *LinkedBlockingQueue[E]) Dequeue() (E, bool) {
-	if q.items.TryLock() {
-		defer q.items.Unlock()
+	q.takeLock.Lock()
+	if q.count.Load() == 0 {
+		done := q.stats.blockConsumer()
+		for q.count.Load() == 0 {
+			q.notEmpty.Wait()
+		}
+		done()
 	}
-	for q.items.IsEmpty() {
-		q.takeLock.Wait()
+	value, count := q.dequeue()
+	q.takeLock.Unlock()
+	if count == q.cap {
+		q.signalNotFull()
 	}
-	value, ok := q.items.Shift()
-	q.putLock.Broadcast()
-	return value, ok
+	return value, true
 }
 
 func (q *LinkedBlockingQueue[E]) EnqueueTimeout(value E, duration time.Duration) bool {
-	var ok bool
-	exception.Try(func() {
-		done := make(chan struct{})
-		ok = future.Timeout(func() bool {
-			future.Void(func() {
-				q.items.Lock()
-				defer q.items.Unlock()
-				for int64(q.cap) == q.items.Count() {
-					q.putLock.Wait()
-				}
-				done <- struct{}{}
-			})
-			<-done
-			q.items.Push(value)
-			q.takeLock.Broadcast()
-			return true
-		}, duration).Complete(func() {
-			close(done)
-		}).Await()
-	}).Catch(new(exception.TimeoutException), func(err error) {
-	}).Run()
-	return ok
+	deadline := time.Now().Add(duration)
+	timer := syncutil.BroadcastAfter(q.notFull, duration)
+	defer timer.Stop()
+	q.putLock.Lock()
+	if q.count.Load() == q.cap {
+		done := q.stats.blockProducer()
+		for q.count.Load() == q.cap {
+			if !time.Now().Before(deadline) {
+				done()
+				q.putLock.Unlock()
+				return false
+			}
+			q.notFull.Wait()
+		}
+		done()
+	}
+	count := q.enqueue(value)
+	q.putLock.Unlock()
+	if count == 0 {
+		q.signalNotEmpty()
+	}
+	return true
 }
 
 func (q *LinkedBlockingQueue[E]) DequeueTimeout(duration time.Duration) (E, bool) {
-	var value E
-	var ok bool
-	exception.Try(func() {
-		done := make(chan struct{})
-		future.Timeout(func() bool {
-			future.Void(func() {
-				q.items.Lock()
-				defer q.items.Unlock()
-				for q.items.IsEmpty() {
-					q.takeLock.Wait()
-				}
-				done <- struct{}{}
-			})
-			<-done
-			value, ok = q.items.Shift()
-			q.putLock.Broadcast()
-			return ok
-		}, duration).Complete(func() {
-			close(done)
-		}).Await()
-	}).Catch(new(exception.TimeoutException), func(err error) {
-	}).Run()
-	return value, ok
+	deadline := time.Now().Add(duration)
+	timer := syncutil.BroadcastAfter(q.notEmpty, duration)
+	defer timer.Stop()
+	q.takeLock.Lock()
+	if q.count.Load() == 0 {
+		done := q.stats.blockConsumer()
+		for q.count.Load() == 0 {
+			if !time.Now().Before(deadline) {
+				done()
+				q.takeLock.Unlock()
+				return *new(E), false
+			}
+			q.notEmpty.Wait()
+		}
+		done()
+	}
+	value, count := q.dequeue()
+	q.takeLock.Unlock()
+	if count == q.cap {
+		q.signalNotFull()
+	}
+	return value, true
 }
 
 func (q *LinkedBlockingQueue[E]) Remove(value E) {
-	if q.items.TryLock() {
-		defer q.items.Unlock()
-	}
-	q.items.Remove(value)
+	q.RemoveWhere(func(item E) bool {
+		return reflect.DeepEqual(item, value)
+	})
 }
 
 func (q *LinkedBlockingQueue[E]) RemoveWhere(callback func(E) bool) {
-	if q.items.TryLock() {
-		defer q.items.Unlock()
+	q.fullyLock()
+	defer q.fullyUnlock()
+	removed := false
+	for trail, node := q.head, q.head.next; node != nil; node = trail.next {
+		if !callback(node.value) {
+			trail = node
+			continue
+		}
+		trail.next = node.next
+		if q.last == node {
+			q.last = trail
+		}
+		q.count.Add(-1)
+		q.stats.removed.Add(1)
+		removed = true
+	}
+	if removed {
+		q.notFull.Broadcast()
 	}
-	q.items.RemoveWhere(callback)
 }
 
-func (q *LinkedBlockingQueue[E]) ToArray() []E {
-	if q.items.TryRLock() {
-		defer q.items.RUnlock()
+// values returns the values in queue order, it must be called with both locks held.
+func (q *LinkedBlockingQueue[E]) values() []E {
+	values := make([]E, 0, q.count.Load())
+	for node := q.head.next; node != nil; node = node.next {
+		values = append(values, node.value)
 	}
-	return q.items.ToArray()
+	return values
+}
+
+func (q *LinkedBlockingQueue[E]) ToArray() []E {
+	q.fullyLock()
+	defer q.fullyUnlock()
+	return q.values()
 }
 
 func (q *LinkedBlockingQueue[E]) ToJSON() ([]byte, error) {
-	if q.items.TryRLock() {
-		defer q.items.RUnlock()
-	}
-	return q.items.MarshalJSON()
+	return json.Marshal(q.ToArray())
 }
 
 func (q *LinkedBlockingQueue[E]) MarshalJSON() ([]byte, error) {
@@ -201,43 +301,34 @@ func (q *LinkedBlockingQueue[E]) MarshalJSON() ([]byte, error) {
 }
 
 func (q *LinkedBlockingQueue[E]) UnmarshalJSON(data []byte) error {
-	if q.items.TryLock() {
-		defer q.items.Unlock()
-	}
 	values := make([]E, 0)
 	if err := json.Unmarshal(data, &values); err != nil {
 		return err
 	}
 	for _, value := range values {
-		for q.items.Count() == int64(q.cap) {
-			q.putLock.Wait()
-		}
-		q.items.Push(value)
-		q.takeLock.Broadcast()
+		q.Enqueue(value)
 	}
 	return nil
 }
 
 func (q *LinkedBlockingQueue[E]) String() string {
-	if q.items.TryRLock() {
-		defer q.items.RUnlock()
-	}
+	q.fullyLock()
+	defer q.fullyUnlock()
 	str := new(strings.Builder)
-	str.WriteString(fmt.Sprintf("LinkedBlockingQueue[%T](len=%d)", *new(E), q.items.Count()))
+	str.WriteString(fmt.Sprintf("LinkedBlockingQueue[%T](len=%d)", *new(E), q.count.Load()))
 	str.WriteByte('{')
 	str.WriteByte('\n')
-	q.items.Each(func(index int, value E) bool {
+	for index, node := 0, q.head.next; node != nil && index < 5; index, node = index+1, node.next {
 		str.WriteByte('\t')
-		if v, ok := any(value).(support.Stringable); ok {
+		if v, ok := any(node.value).(support.Stringable); ok {
 			str.WriteString(v.String())
 		} else {
-			str.WriteString(fmt.Sprintf("%v", value))
+			str.WriteString(fmt.Sprintf("%v", node.value))
 		}
 		str.WriteByte(',')
 		str.WriteByte('\n')
-		return index < 4
-	})
-	if q.items.Count() > 5 {
+	}
+	if q.count.Load() > 5 {
 		str.WriteString("\t...\n")
 	}
 	str.WriteByte('}')
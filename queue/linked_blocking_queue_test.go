@@ -4,6 +4,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"regexp"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -161,3 +163,54 @@ func TestLinkedBlockingQueue_String(t *testing.T) {
 	pattern := regexp.MustCompile(fmt.Sprintf(`LinkedBlockingQueue\[int\]\(len=%d\)\{\n(\t\d+,\n){5}\}`, queue.Count()))
 	assert.True(t, pattern.Match([]byte(str)))
 }
+
+func TestLinkedBlockingQueue_ProducersConsumers(t *testing.T) {
+	queue := NewLinkedBlockingQueue[int](8)
+	var wg sync.WaitGroup
+	var sum atomic.Int64
+	for i := 0; i < 8; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := 1; j <= 1000; j++ {
+				queue.Enqueue(j)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				v, _ := queue.Dequeue()
+				sum.Add(int64(v))
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int64(8*500500), sum.Load())
+	assert.True(t, queue.IsEmpty())
+}
+
+func TestLinkedBlockingQueue_DequeueTimeoutWakeUp(t *testing.T) {
+	queue := NewLinkedBlockingQueue[int](1)
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		queue.Enqueue(1)
+	}()
+	v, ok := queue.DequeueTimeout(time.Second)
+	assert.True(t, ok)
+	assert.Equal(t, 1, v)
+	assert.True(t, queue.EnqueueTimeout(2, time.Second))
+	assert.False(t, queue.EnqueueTimeout(3, 100*time.Millisecond))
+}
+
+func TestLinkedBlockingQueue_RemoveWhere(t *testing.T) {
+	queue := NewLinkedBlockingQueue[int](5)
+	for i := 0; i < 5; i++ {
+		queue.Enqueue(i)
+	}
+	queue.RemoveWhere(func(value int) bool {
+		return value%2 == 0
+	})
+	assert.Equal(t, []int{1, 3}, queue.ToArray())
+	assert.True(t, queue.TryEnqueue(5))
+	assert.Equal(t, []int{1, 3, 5}, queue.ToArray())
+}
@@ -0,0 +1,25 @@
+package queue
+
+import "github.com/gopi-frame/support/clock"
+
+// Option option of the time-based queues
+type Option func(o *options)
+
+type options struct {
+	clock clock.Clock
+}
+
+func newOptions(opts ...Option) *options {
+	o := &options{clock: clock.NewRealClock()}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// WithClock sets the clock used to read the current time and create timers
+func WithClock(c clock.Clock) Option {
+	return func(o *options) {
+		o.clock = c
+	}
+}
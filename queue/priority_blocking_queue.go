@@ -3,182 +3,357 @@ package queue
 import (
 	"encoding/json"
 	"fmt"
+	"slices"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/gopi-frame/contract/support"
+	"github.com/gopi-frame/support/internal/syncutil"
 )
 
+// PriorityOption option of the priority blocking queue
+type PriorityOption[E any] func(q *PriorityBlockingQueue[E])
+
+// WithUnbounded removes the capacity, enqueue never blocks
+func WithUnbounded[E any]() PriorityOption[E] {
+	return func(q *PriorityBlockingQueue[E]) {
+		q.cap = -1
+	}
+}
+
+// WithEvictLowest makes enqueue on a full queue evict the lowest-priority element instead of blocking.
+// When the enqueued value is itself the lowest it is the one dropped and enqueue returns false.
+// The callback, which may be nil, is called with the dropped value.
+func WithEvictLowest[E any](onEvict func(value E)) PriorityOption[E] {
+	return func(q *PriorityBlockingQueue[E]) {
+		q.evict = true
+		q.onEvict = onEvict
+	}
+}
+
+// WithFairness splits the elements into priority classes, dequeue takes the elements of the
+// non-empty classes in weighted round-robin, and by priority within a class, so that the low
+// classes are not starved. A class missing from the weights has a weight of 1.
+func WithFairness[E any](classify func(value E) int, weights map[int]int) PriorityOption[E] {
+	return func(q *PriorityBlockingQueue[E]) {
+		q.classify = classify
+		q.weights = weights
+	}
+}
+
+// priorityClass elements of the same class and the round-robin state of the class
+type priorityClass[E any] struct {
+	items   *PriorityQueue[E]
+	weight  int
+	current int
+}
+
 // NewPriorityBlockingQueue new priority blocking queue
-func NewPriorityBlockingQueue[E any](comparator support.Comparator[E], cap int) *PriorityBlockingQueue[E] {
+func NewPriorityBlockingQueue[E any](comparator support.Comparator[E], cap int, opts ...PriorityOption[E]) *PriorityBlockingQueue[E] {
 	queue := new(PriorityBlockingQueue[E])
-	queue.items = NewPriorityQueue(comparator)
-	queue.takeLock = sync.NewCond(queue.items)
-	queue.putLock = sync.NewCond(queue.items)
+	queue.comparator = comparator
 	queue.cap = cap
+	queue.classes = make(map[int]*priorityClass[E])
+	queue.notEmpty = sync.NewCond(&queue.mu)
+	queue.notFull = sync.NewCond(&queue.mu)
+	for _, opt := range opts {
+		opt(queue)
+	}
 	return queue
 }
 
 // PriorityBlockingQueue priority blocking queue
+//
+// By default it is bounded and the element with the highest priority is dequeued first.
+// The options make it unbounded, evict the lowest-priority element when full, or share
+// the dequeues between priority classes.
 type PriorityBlockingQueue[E any] struct {
-	items    *PriorityQueue[E]
-	cap      int
-	takeLock *sync.Cond
-	putLock  *sync.Cond
+	mu         sync.Mutex
+	comparator support.Comparator[E]
+	cap        int
+	count      int
+	evict      bool
+	onEvict    func(value E)
+	classify   func(value E) int
+	weights    map[int]int
+	classes    map[int]*priorityClass[E]
+	order      []int
+	notEmpty   *sync.Cond
+	notFull    *sync.Cond
 }
 
-func (q *PriorityBlockingQueue[E]) Count() int64 {
-	if q.items.TryRLock() {
-		defer q.items.RUnlock()
+func (q *PriorityBlockingQueue[E]) full() bool {
+	return q.cap >= 0 && q.count >= q.cap
+}
+
+// class returns the class of the value, it must be called with the lock held
+func (q *PriorityBlockingQueue[E]) class(value E) *priorityClass[E] {
+	key := 0
+	if q.classify != nil {
+		key = q.classify(value)
 	}
-	return q.items.Count()
+	class, ok := q.classes[key]
+	if !ok {
+		weight, ok := q.weights[key]
+		if !ok || weight <= 0 {
+			weight = 1
+		}
+		class = &priorityClass[E]{items: NewPriorityQueue(q.comparator), weight: weight}
+		q.classes[key] = class
+		index, _ := slices.BinarySearch(q.order, key)
+		q.order = slices.Insert(q.order, index, key)
+	}
+	return class
 }
 
-func (q *PriorityBlockingQueue[E]) IsEmpty() bool {
-	if q.items.TryRLock() {
-		defer q.items.RUnlock()
+// next returns the class to dequeue from with the smooth weighted round-robin,
+// when advance is false the round-robin state is left as is.
+// It must be called with the lock held and the queue not empty.
+func (q *PriorityBlockingQueue[E]) next(advance bool) *priorityClass[E] {
+	var selected *priorityClass[E]
+	total := 0
+	for _, key := range q.order {
+		class := q.classes[key]
+		if class.items.IsEmpty() {
+			continue
+		}
+		total += class.weight
+		if selected == nil || class.current+class.weight > selected.current+selected.weight {
+			selected = class
+		}
+	}
+	if advance {
+		for _, key := range q.order {
+			if class := q.classes[key]; class.items.IsNotEmpty() {
+				class.current += class.weight
+			}
+		}
+		selected.current -= total
 	}
-	return q.items.IsEmpty()
+	return selected
 }
 
-func (q *PriorityBlockingQueue[E]) IsNotEmpty() bool {
-	if q.items.TryRLock() {
-		defer q.items.RUnlock()
+// put adds the value, it must be called with the lock held and the queue not full
+func (q *PriorityBlockingQueue[E]) put(value E) {
+	q.class(value).items.Enqueue(value)
+	q.count++
+	q.notEmpty.Signal()
+}
+
+// take removes the next value, it must be called with the lock held and the queue not empty
+func (q *PriorityBlockingQueue[E]) take() E {
+	class := q.next(true)
+	value, _ := class.items.Dequeue()
+	if class.items.IsEmpty() {
+		class.current = 0
+	}
+	q.count--
+	q.notFull.Signal()
+	return value
+}
+
+// replaceLowest makes room for the value by removing the lowest-priority element,
+// it returns the dropped value and whether it is the enqueued one.
+// It must be called with the lock held and the queue full.
+func (q *PriorityBlockingQueue[E]) replaceLowest(value E) (E, bool) {
+	var lowest *priorityClass[E]
+	lowestIndex := int64(-1)
+	for _, key := range q.order {
+		class := q.classes[key]
+		// the lowest element of a heap is one of its leaves
+		for index := class.items.size / 2; index < class.items.size; index++ {
+			if lowest == nil || q.comparator.Compare(class.items.items[index], lowest.items.items[lowestIndex]) > 0 {
+				lowest, lowestIndex = class, index
+			}
+		}
+	}
+	if lowest == nil || q.comparator.Compare(value, lowest.items.items[lowestIndex]) >= 0 {
+		return value, true
+	}
+	dropped := lowest.items.removeAt(lowestIndex)
+	if lowest.items.IsEmpty() {
+		lowest.current = 0
 	}
-	return q.items.IsNotEmpty()
+	q.count--
+	q.put(value)
+	return dropped, false
+}
+
+// offer adds the value when the queue is full and evicts, it unlocks the queue
+func (q *PriorityBlockingQueue[E]) offer(value E) bool {
+	dropped, self := q.replaceLowest(value)
+	q.mu.Unlock()
+	if q.onEvict != nil {
+		q.onEvict(dropped)
+	}
+	return !self
+}
+
+func (q *PriorityBlockingQueue[E]) Count() int64 {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return int64(q.count)
+}
+
+func (q *PriorityBlockingQueue[E]) IsEmpty() bool {
+	return q.Count() == 0
+}
+
+func (q *PriorityBlockingQueue[E]) IsNotEmpty() bool {
+	return !q.IsEmpty()
 }
 
 func (q *PriorityBlockingQueue[E]) Clear() {
-	if q.items.TryLock() {
-		defer q.items.Unlock()
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for _, class := range q.classes {
+		class.items.Clear()
+		class.current = 0
 	}
-	q.items.Clear()
+	q.count = 0
+	q.notFull.Broadcast()
 }
 
 func (q *PriorityBlockingQueue[E]) Peek() (E, bool) {
-	if q.items.TryRLock() {
-		defer q.items.RUnlock()
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.count == 0 {
+		return *new(E), false
 	}
-	return q.items.Peek()
+	return q.next(false).items.Peek()
 }
 
 func (q *PriorityBlockingQueue[E]) TryEnqueue(value E) bool {
-	if q.items.TryLock() {
-		defer q.items.Unlock()
-	}
-	if q.cap == int(q.items.Count()) {
+	q.mu.Lock()
+	if q.full() {
+		if q.evict {
+			return q.offer(value)
+		}
+		q.mu.Unlock()
 		return false
 	}
-	ok := q.items.Enqueue(value)
-	q.takeLock.Broadcast()
-	return ok
+	q.put(value)
+	q.mu.Unlock()
+	return true
 }
 
 func (q *PriorityBlockingQueue[E]) TryDequeue() (E, bool) {
-	if q.items.TryLock() {
-		defer q.items.Unlock()
-	}
-	if q.items.Count() == 0 {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.count == 0 {
 		return *new(E), false
 	}
-	value, ok := q.items.Dequeue()
-	q.putLock.Broadcast()
-	return value, ok
+	return q.take(), true
 }
 
 func (q *PriorityBlockingQueue[E]) Enqueue(value E) bool {
-	if q.items.TryLock() {
-		defer q.items.Unlock()
+	q.mu.Lock()
+	if q.full() && q.evict {
+		return q.offer(value)
 	}
-	for q.cap == int(q.items.Count()) {
-		q.putLock.Wait()
+	for q.full() {
+		q.notFull.Wait()
 	}
-	ok := q.items.Enqueue(value)
-	q.takeLock.Broadcast()
-	return ok
+	q.put(value)
+	q.mu.Unlock()
+	return true
 }
 
 func (q *PriorityBlockingQueue[E]) Dequeue() (E, bool) {
-	if q.items.TryLock() {
-		defer q.items.Unlock()
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for q.count == 0 {
+		q.notEmpty.Wait()
 	}
-	for q.items.IsEmpty() {
-		q.takeLock.Wait()
-	}
-	value, ok := q.items.Dequeue()
-	q.putLock.Broadcast()
-	return value, ok
+	return q.take(), true
 }
 
 func (q *PriorityBlockingQueue[E]) EnqueueTimeout(value E, duration time.Duration) bool {
-	timeout := time.After(duration)
-	done := make(chan struct{})
-	go func() {
-		q.items.Lock()
-		defer q.items.Unlock()
-		for int64(q.cap) == q.items.Count() {
-			q.putLock.Wait()
+	deadline := time.Now().Add(duration)
+	timer := syncutil.BroadcastAfter(q.notFull, duration)
+	defer timer.Stop()
+	q.mu.Lock()
+	if q.full() && q.evict {
+		return q.offer(value)
+	}
+	for q.full() {
+		if !time.Now().Before(deadline) {
+			q.mu.Unlock()
+			return false
 		}
-		close(done)
-	}()
-	select {
-	case <-done:
-		ok := q.items.Enqueue(value)
-		q.takeLock.Broadcast()
-		return ok
-	case <-timeout:
-		return false
+		q.notFull.Wait()
 	}
+	q.put(value)
+	q.mu.Unlock()
+	return true
 }
 
-func (q *PriorityBlockingQueue[E]) DequeueTimeout(duration time.Duration) (value E, ok bool) {
-	timeout := time.After(duration)
-	done := make(chan struct{})
-	go func() {
-		q.items.Lock()
-		defer q.items.Unlock()
-		for q.items.IsEmpty() {
-			q.takeLock.Wait()
+func (q *PriorityBlockingQueue[E]) DequeueTimeout(duration time.Duration) (E, bool) {
+	deadline := time.Now().Add(duration)
+	timer := syncutil.BroadcastAfter(q.notEmpty, duration)
+	defer timer.Stop()
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for q.count == 0 {
+		if !time.Now().Before(deadline) {
+			return *new(E), false
 		}
-		close(done)
-	}()
-	select {
-	case <-done:
-		value, ok = q.items.Dequeue()
-		q.putLock.Broadcast()
-		return
-	case <-timeout:
-		return
+		q.notEmpty.Wait()
 	}
+	return q.take(), true
 }
 
 func (q *PriorityBlockingQueue[E]) Remove(value E) {
-	if q.items.TryLock() {
-		defer q.items.Unlock()
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for _, class := range q.classes {
+		class.items.Remove(value)
 	}
-	q.items.Remove(value)
+	q.recount()
 }
 
 func (q *PriorityBlockingQueue[E]) RemoveWhere(callback func(E) bool) {
-	if q.items.TryLock() {
-		defer q.items.Unlock()
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for _, class := range q.classes {
+		class.items.RemoveWhere(callback)
 	}
-	q.items.RemoveWhere(callback)
+	q.recount()
 }
 
-func (q *PriorityBlockingQueue[E]) ToArray() []E {
-	if q.items.TryRLock() {
-		defer q.items.RUnlock()
+// recount updates the count after a removal, it must be called with the lock held
+func (q *PriorityBlockingQueue[E]) recount() {
+	count := 0
+	for _, class := range q.classes {
+		count += int(class.items.Count())
+		if class.items.IsEmpty() {
+			class.current = 0
+		}
+	}
+	if count < q.count {
+		q.notFull.Broadcast()
 	}
-	return q.items.ToArray()
+	q.count = count
 }
 
-func (q *PriorityBlockingQueue[E]) ToJSON() ([]byte, error) {
-	if q.items.TryLock() {
-		defer q.items.Unlock()
+// values returns the values of the classes in class order, it must be called with the lock held
+func (q *PriorityBlockingQueue[E]) values() []E {
+	values := make([]E, 0, q.count)
+	for _, key := range q.order {
+		values = append(values, q.classes[key].items.ToArray()...)
 	}
-	return q.items.ToJSON()
+	return values
+}
+
+func (q *PriorityBlockingQueue[E]) ToArray() []E {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.values()
+}
+
+func (q *PriorityBlockingQueue[E]) ToJSON() ([]byte, error) {
+	return json.Marshal(q.ToArray())
 }
 
 func (q *PriorityBlockingQueue[E]) MarshalJSON() ([]byte, error) {
@@ -186,33 +361,25 @@ func (q *PriorityBlockingQueue[E]) MarshalJSON() ([]byte, error) {
 }
 
 func (q *PriorityBlockingQueue[E]) UnmarshalJSON(data []byte) error {
-	if q.items.TryLock() {
-		defer q.items.Unlock()
-	}
 	values := make([]E, 0)
 	if err := json.Unmarshal(data, &values); err != nil {
 		return err
 	}
-	q.items.Clear()
+	q.Clear()
 	for _, value := range values {
-		for q.cap == int(q.items.Count()) {
-			q.putLock.Wait()
-		}
-		q.items.Enqueue(value)
-		q.takeLock.Broadcast()
+		q.Enqueue(value)
 	}
 	return nil
 }
 
 func (q *PriorityBlockingQueue[E]) String() string {
-	if q.items.TryLock() {
-		defer q.items.Unlock()
-	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
 	str := new(strings.Builder)
-	str.WriteString(fmt.Sprintf("PriorityBlockingQueue[%T](len=%d)", *new(E), q.items.Count()))
+	str.WriteString(fmt.Sprintf("PriorityBlockingQueue[%T](len=%d)", *new(E), q.count))
 	str.WriteByte('{')
 	str.WriteByte('\n')
-	for index, value := range q.items.items {
+	for index, value := range q.values() {
 		str.WriteByte('\t')
 		if v, ok := any(value).(support.Stringable); ok {
 			str.WriteString(v.String())
@@ -225,7 +392,7 @@ func (q *PriorityBlockingQueue[E]) String() string {
 			break
 		}
 	}
-	if q.items.Count() > 5 {
+	if q.count > 5 {
 		str.WriteString("\t...\n")
 	}
 	str.WriteByte('}')
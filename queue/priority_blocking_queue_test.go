@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"regexp"
+	"runtime"
 	"testing"
 	"time"
 
@@ -161,3 +162,77 @@ func TestPriorityBlockingQueue_String(t *testing.T) {
 	pattern := regexp.MustCompile(fmt.Sprintf(`PriorityBlockingQueue\[int\]\(len=%d\)\{\n(\t\d+,\n){5}\}`, queue.Count()))
 	assert.True(t, pattern.Match([]byte(str)))
 }
+
+func TestPriorityBlockingQueue_Unbounded(t *testing.T) {
+	queue := NewPriorityBlockingQueue(_comparator{}, 0, WithUnbounded[int]())
+	for i := 100; i > 0; i-- {
+		assert.True(t, queue.TryEnqueue(i))
+	}
+	assert.Equal(t, int64(100), queue.Count())
+	for i := 1; i <= 100; i++ {
+		v, ok := queue.Dequeue()
+		assert.True(t, ok)
+		assert.Equal(t, i, v)
+	}
+}
+
+func TestPriorityBlockingQueue_EvictLowest(t *testing.T) {
+	evicted := []int{}
+	queue := NewPriorityBlockingQueue(_comparator{}, 3, WithEvictLowest(func(value int) {
+		evicted = append(evicted, value)
+	}))
+	for _, v := range []int{5, 3, 8} {
+		queue.Enqueue(v)
+	}
+	assert.True(t, queue.Enqueue(1))
+	assert.False(t, queue.TryEnqueue(9))
+	assert.True(t, queue.EnqueueTimeout(4, time.Millisecond))
+	assert.Equal(t, []int{8, 9, 5}, evicted)
+	assert.Equal(t, int64(3), queue.Count())
+	for _, expected := range []int{1, 3, 4} {
+		v, _ := queue.Dequeue()
+		assert.Equal(t, expected, v)
+	}
+}
+
+func TestPriorityBlockingQueue_Fairness(t *testing.T) {
+	class := func(value int) int {
+		return value / 100
+	}
+	queue := NewPriorityBlockingQueue(_comparator{}, 0, WithUnbounded[int](), WithFairness(class, map[int]int{0: 3}))
+	for i := 0; i < 10; i++ {
+		queue.Enqueue(100 + i)
+		queue.Enqueue(i)
+	}
+	peeked, _ := queue.Peek()
+	first, _ := queue.Dequeue()
+	assert.Equal(t, peeked, first)
+	values := []int{first}
+	for i := 1; i < 8; i++ {
+		v, _ := queue.Dequeue()
+		values = append(values, v)
+	}
+	// the high class gets three dequeues for each one of the low class, in priority order within a class
+	assert.Equal(t, []int{0, 1, 100, 2, 3, 4, 101, 5}, values)
+	for i := 8; i < 20; i++ {
+		queue.Dequeue()
+	}
+	assert.True(t, queue.IsEmpty())
+}
+
+func TestPriorityBlockingQueue_TimeoutLeak(t *testing.T) {
+	queue := NewPriorityBlockingQueue(_comparator{}, 1)
+	before := runtime.NumGoroutine()
+	for i := 0; i < 20; i++ {
+		queue.DequeueTimeout(time.Millisecond)
+	}
+	queue.Enqueue(1)
+	for i := 0; i < 20; i++ {
+		queue.EnqueueTimeout(2, time.Millisecond)
+	}
+	time.Sleep(10 * time.Millisecond)
+	assert.LessOrEqual(t, runtime.NumGoroutine(), before)
+	v, ok := queue.TryDequeue()
+	assert.True(t, ok)
+	assert.Equal(t, 1, v)
+}
@@ -15,9 +15,9 @@ import (
 func NewPriorityQueue[E any](comparator support.Comparator[E], values ...E) *PriorityQueue[E] {
 	queue := new(PriorityQueue[E])
 	queue.comparator = comparator
-	for _, value := range values {
-		queue.Enqueue(value)
-	}
+	queue.items = append(queue.items, values...)
+	queue.size = int64(len(queue.items))
+	queue.heapify()
 	return queue
 }
 
@@ -27,6 +27,17 @@ type PriorityQueue[E any] struct {
 	size       int64
 	items      []E
 	comparator support.Comparator[E]
+	// moved is called with an element and its new heap index whenever it moves, if set
+	moved func(value E, index int64)
+}
+
+// indexComparator compares the heap indexes of a priority queue by the elements they point to
+type indexComparator[E any] struct {
+	queue *PriorityQueue[E]
+}
+
+func (c indexComparator[E]) Compare(a, b int64) int {
+	return c.queue.comparator.Compare(c.queue.items[a], c.queue.items[b])
 }
 
 func (q *PriorityQueue[E]) less(i, j int64) bool {
@@ -35,6 +46,10 @@ func (q *PriorityQueue[E]) less(i, j int64) bool {
 
 func (q *PriorityQueue[E]) swap(i, j int64) {
 	q.items[i], q.items[j] = q.items[j], q.items[i]
+	if q.moved != nil {
+		q.moved(q.items[i], i)
+		q.moved(q.items[j], j)
+	}
 }
 
 func (q *PriorityQueue[E]) Count() int64 {
@@ -61,25 +76,14 @@ func (q *PriorityQueue[E]) Peek() (E, bool) {
 	return q.items[0], true
 }
 
-func (q *PriorityQueue[E]) Enqueue(value E) bool {
-	q.items = append(q.items, value)
-	q.size++
-	for index := q.size - 1; q.less(index, (index-1)/2); index = (index - 1) / 2 {
+func (q *PriorityQueue[E]) up(index int64) {
+	for index > 0 && q.less(index, (index-1)/2) {
 		q.swap(index, (index-1)/2)
+		index = (index - 1) / 2
 	}
-	return true
 }
 
-func (q *PriorityQueue[E]) Dequeue() (value E, ok bool) {
-	if q.size == 0 {
-		return *new(E), false
-	}
-	value = q.items[0]
-	ok = true
-	q.swap(0, q.size-1)
-	q.items = q.items[:q.size-1]
-	q.size--
-	index := int64(0)
+func (q *PriorityQueue[E]) down(index int64) {
 	lastIndex := q.size - 1
 	for {
 		leftIndex := index*2 + 1
@@ -96,7 +100,62 @@ func (q *PriorityQueue[E]) Dequeue() (value E, ok bool) {
 		q.swap(swapIndex, index)
 		index = swapIndex
 	}
-	return
+}
+
+// fix restores the heap order after the element at index changed its priority
+func (q *PriorityQueue[E]) fix(index int64) {
+	q.up(index)
+	q.down(index)
+}
+
+// reindex reports the index of every element before the heap order is restored
+func (q *PriorityQueue[E]) reindex() {
+	if q.moved == nil {
+		return
+	}
+	for index, value := range q.items {
+		q.moved(value, int64(index))
+	}
+}
+
+// heapify restores the heap order of the whole array in O(n)
+func (q *PriorityQueue[E]) heapify() {
+	for index := q.size/2 - 1; index >= 0; index-- {
+		q.down(index)
+	}
+}
+
+// removeAt removes the element at index and restores the heap order
+func (q *PriorityQueue[E]) removeAt(index int64) E {
+	value := q.items[index]
+	lastIndex := q.size - 1
+	if index != lastIndex {
+		q.swap(index, lastIndex)
+	}
+	q.items[lastIndex] = *new(E)
+	q.items = q.items[:lastIndex]
+	q.size--
+	if index != lastIndex {
+		q.fix(index)
+	}
+	return value
+}
+
+func (q *PriorityQueue[E]) Enqueue(value E) bool {
+	q.items = append(q.items, value)
+	q.size++
+	if q.moved != nil {
+		q.moved(value, q.size-1)
+	}
+	q.up(q.size - 1)
+	return true
+}
+
+func (q *PriorityQueue[E]) Dequeue() (value E, ok bool) {
+	if q.size == 0 {
+		return *new(E), false
+	}
+	return q.removeAt(0), true
 }
 
 func (q *PriorityQueue[E]) Remove(value E) {
@@ -107,11 +166,45 @@ func (q *PriorityQueue[E]) Remove(value E) {
 
 func (q *PriorityQueue[E]) RemoveWhere(callback func(E) bool) {
 	q.items = slices.DeleteFunc(q.items, callback)
-	q.size = int64(len(q.items))
+	if size := int64(len(q.items)); size != q.size {
+		clear(q.items[size:q.size])
+		q.size = size
+		q.reindex()
+		q.heapify()
+	}
 }
 
+// Each calls the callback with the elements in priority order until it returns false,
+// the queue is left untouched. The first k elements are visited in O(k log k).
+func (q *PriorityQueue[E]) Each(callback func(index int, value E) bool) {
+	if q.size == 0 {
+		return
+	}
+	// the candidates are the heap indexes whose parent has been visited,
+	// kept in a heap of their own ordered by the element they point to
+	candidates := NewPriorityQueue[int64](indexComparator[E]{q})
+	candidates.Enqueue(0)
+	for position := 0; candidates.IsNotEmpty(); position++ {
+		index, _ := candidates.Dequeue()
+		if !callback(position, q.items[index]) {
+			return
+		}
+		for child := index*2 + 1; child <= index*2+2 && child < q.size; child++ {
+			candidates.Enqueue(child)
+		}
+	}
+}
+
+// Sorted returns the elements in priority order, the queue is left untouched
+func (q *PriorityQueue[E]) Sorted() []E {
+	values := slices.Clone(q.items)
+	slices.SortStableFunc(values, q.comparator.Compare)
+	return values
+}
+
+// ToArray returns the elements in priority order
 func (q *PriorityQueue[E]) ToArray() []E {
-	return q.items
+	return q.Sorted()
 }
 
 func (q *PriorityQueue[E]) ToJSON() ([]byte, error) {
@@ -126,12 +219,12 @@ func (q *PriorityQueue[E]) UnmarshalJSON(data []byte) error {
 	items := []E{}
 	err := json.Unmarshal(data, &items)
 	if err != nil {
-		return nil
-	}
-	q.Clear()
-	for _, item := range items {
-		q.Enqueue(item)
+		return err
 	}
+	q.items = items
+	q.size = int64(len(items))
+	q.reindex()
+	q.heapify()
 	return nil
 }
 
@@ -140,7 +233,7 @@ func (q *PriorityQueue[E]) String() string {
 	str.WriteString(fmt.Sprintf("PriorityQueue[%T](len=%d)", *new(E), q.Count()))
 	str.WriteByte('{')
 	str.WriteByte('\n')
-	for index, value := range q.items {
+	q.Each(func(index int, value E) bool {
 		str.WriteByte('\t')
 		if v, ok := any(value).(support.Stringable); ok {
 			str.WriteString(v.String())
@@ -149,10 +242,8 @@ func (q *PriorityQueue[E]) String() string {
 		}
 		str.WriteByte(',')
 		str.WriteByte('\n')
-		if index >= 4 {
-			break
-		}
-	}
+		return index < 4
+	})
 	if q.Count() > 5 {
 		str.WriteString("\t...\n")
 	}
@@ -3,7 +3,9 @@ package queue
 import (
 	"encoding/json"
 	"fmt"
+	"math/rand"
 	"regexp"
+	"slices"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -92,3 +94,55 @@ func TestPriorityQueue_String(t *testing.T) {
 	pattern := regexp.MustCompile(fmt.Sprintf(`PriorityQueue\[int\]\(len=%d\)\{\n(\t\d+,\n){5}\t(\.){3}\n\}`, queue.Count()))
 	assert.True(t, pattern.Match([]byte(str)))
 }
+
+func TestPriorityQueue_Sorted(t *testing.T) {
+	queue := NewPriorityQueue(_comparator{}, 5, 3, 9, 1, 7, 2)
+	heap := slices.Clone(queue.items)
+	assert.Equal(t, []int{1, 2, 3, 5, 7, 9}, queue.Sorted())
+	assert.Equal(t, heap, queue.items)
+	values := []int{}
+	queue.Each(func(index int, value int) bool {
+		values = append(values, value)
+		return index < 2
+	})
+	assert.Equal(t, []int{1, 2, 3}, values)
+	assert.Equal(t, int64(6), queue.Count())
+}
+
+func TestPriorityQueue_RemoveWhere(t *testing.T) {
+	rand := rand.New(rand.NewSource(1))
+	for round := 0; round < 50; round++ {
+		values := rand.Perm(50)
+		queue := NewPriorityQueue(_comparator{}, values...)
+		queue.RemoveWhere(func(value int) bool {
+			return value%3 == 0
+		})
+		queue.Remove(49)
+		expected := []int{}
+		for i := 0; i < 49; i++ {
+			if i%3 != 0 {
+				expected = append(expected, i)
+			}
+		}
+		assert.Equal(t, int64(len(expected)), queue.Count())
+		for _, e := range expected {
+			v, ok := queue.Dequeue()
+			assert.True(t, ok)
+			assert.Equal(t, e, v)
+		}
+		assert.True(t, queue.IsEmpty())
+	}
+}
+
+func TestPriorityQueue_ToArrayOrder(t *testing.T) {
+	queue := NewPriorityQueue(_comparator{}, 4, 8, 1, 6, 2)
+	assert.Equal(t, []int{1, 2, 4, 6, 8}, queue.ToArray())
+	jsonBytes, err := queue.ToJSON()
+	assert.Nil(t, err)
+	assert.Equal(t, `[1,2,4,6,8]`, string(jsonBytes))
+	queue = NewPriorityQueue(_comparator{})
+	assert.Nil(t, json.Unmarshal([]byte(`[9,3,5]`), queue))
+	v, _ := queue.Dequeue()
+	assert.Equal(t, 3, v)
+	assert.NotNil(t, json.Unmarshal([]byte(`{}`), queue))
+}
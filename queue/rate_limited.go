@@ -0,0 +1,140 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/gopi-frame/contract/support"
+)
+
+// ErrEmpty is returned when dequeuing from an empty queue
+var ErrEmpty = errors.New("queue: queue is empty")
+
+var _ support.Queue[any] = (*RateLimited[any])(nil)
+
+// rateLimitedPoll interval at which the context is checked while waiting for an element of a blocking queue
+const rateLimitedPoll = 10 * time.Millisecond
+
+// NewRateLimited wraps the queue so that its elements are dequeued at the pace of the limiter
+func NewRateLimited[E any](queue support.Queue[E], limiter Limiter) *RateLimited[E] {
+	return &RateLimited[E]{queue: queue, limiter: limiter}
+}
+
+// RateLimited throttles the consumption of a queue, enqueuing is not limited.
+//
+// Dequeue waits for the limiter only when the queue has an element; an element
+// taken by another consumer in the meantime costs the event without being returned.
+// A blocking queue emptied that way is waited on until the context is done.
+type RateLimited[E any] struct {
+	queue   support.Queue[E]
+	limiter Limiter
+}
+
+// Queue returns the wrapped queue
+func (q *RateLimited[E]) Queue() support.Queue[E] {
+	return q.queue
+}
+
+// Limiter returns the limiter
+func (q *RateLimited[E]) Limiter() Limiter {
+	return q.limiter
+}
+
+func (q *RateLimited[E]) Count() int64 {
+	return q.queue.Count()
+}
+
+func (q *RateLimited[E]) IsEmpty() bool {
+	return q.queue.IsEmpty()
+}
+
+func (q *RateLimited[E]) IsNotEmpty() bool {
+	return q.queue.IsNotEmpty()
+}
+
+func (q *RateLimited[E]) Clear() {
+	q.queue.Clear()
+}
+
+func (q *RateLimited[E]) Peek() (E, bool) {
+	return q.queue.Peek()
+}
+
+func (q *RateLimited[E]) Enqueue(value E) bool {
+	return q.queue.Enqueue(value)
+}
+
+// Dequeue dequeues an element, it blocks until the limiter allows it
+func (q *RateLimited[E]) Dequeue() (E, bool) {
+	value, err := q.DequeueContext(context.Background())
+	return value, err == nil
+}
+
+// DequeueContext dequeues an element, it blocks until the limiter allows it or the context is done.
+// It returns ErrEmpty when the queue is empty.
+func (q *RateLimited[E]) DequeueContext(ctx context.Context) (E, error) {
+	if q.queue.IsEmpty() {
+		return *new(E), ErrEmpty
+	}
+	if err := q.limiter.Wait(ctx); err != nil {
+		return *new(E), err
+	}
+	if blocking, ok := q.queue.(support.BlockingQueue[E]); ok {
+		// the blocking dequeue would ignore the context
+		for {
+			if value, ok := blocking.TryDequeue(); ok {
+				return value, nil
+			}
+			select {
+			case <-ctx.Done():
+				return *new(E), ctx.Err()
+			default:
+			}
+			if value, ok := blocking.DequeueTimeout(rateLimitedPoll); ok {
+				return value, nil
+			}
+		}
+	}
+	value, ok := q.queue.Dequeue()
+	if !ok {
+		return *new(E), ErrEmpty
+	}
+	return value, nil
+}
+
+// TryDequeue dequeues an element if the limiter allows it right now
+func (q *RateLimited[E]) TryDequeue() (E, bool) {
+	if q.queue.IsEmpty() || !q.limiter.Allow() {
+		return *new(E), false
+	}
+	return q.queue.Dequeue()
+}
+
+func (q *RateLimited[E]) Remove(value E) {
+	q.queue.Remove(value)
+}
+
+func (q *RateLimited[E]) RemoveWhere(callback func(E) bool) {
+	q.queue.RemoveWhere(callback)
+}
+
+func (q *RateLimited[E]) ToArray() []E {
+	return q.queue.ToArray()
+}
+
+func (q *RateLimited[E]) ToJSON() ([]byte, error) {
+	return q.queue.ToJSON()
+}
+
+func (q *RateLimited[E]) MarshalJSON() ([]byte, error) {
+	return q.ToJSON()
+}
+
+func (q *RateLimited[E]) UnmarshalJSON(data []byte) error {
+	return q.queue.UnmarshalJSON(data)
+}
+
+func (q *RateLimited[E]) String() string {
+	return q.queue.String()
+}
@@ -0,0 +1,103 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/gopi-frame/support/clock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimited_Dequeue(t *testing.T) {
+	fake := clock.NewFakeClock(time.Now())
+	queue := NewRateLimited[int](NewQueue(1, 2, 3), NewTokenBucket(1, 2, WithClock(fake)))
+	for i := 1; i <= 2; i++ {
+		v, ok := queue.Dequeue()
+		assert.True(t, ok)
+		assert.Equal(t, i, v)
+	}
+	_, ok := queue.TryDequeue()
+	assert.False(t, ok)
+	done := make(chan int)
+	go func() {
+		v, _ := queue.Dequeue()
+		done <- v
+	}()
+	fake.BlockUntil(1)
+	fake.Advance(time.Second)
+	assert.Equal(t, 3, <-done)
+	_, err := queue.DequeueContext(context.Background())
+	assert.ErrorIs(t, err, ErrEmpty)
+}
+
+func TestRateLimited_DequeueContext(t *testing.T) {
+	fake := clock.NewFakeClock(time.Now())
+	queue := NewRateLimited[int](NewQueue(1, 2), NewLeakyBucket(1, 0, WithClock(fake)))
+	v, err := queue.DequeueContext(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, 1, v)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		_, err := queue.DequeueContext(ctx)
+		done <- err
+	}()
+	fake.BlockUntil(1)
+	cancel()
+	assert.ErrorIs(t, <-done, context.Canceled)
+	assert.Equal(t, int64(1), queue.Count())
+}
+
+func TestRateLimited_DequeueContextBlocking(t *testing.T) {
+	fake := clock.NewFakeClock(time.Now())
+	inner := NewLinkedBlockingQueue[int](4)
+	inner.Enqueue(1)
+	inner.Enqueue(2)
+	queue := NewRateLimited[int](inner, NewLeakyBucket(1, 0, WithClock(fake)))
+	v, err := queue.DequeueContext(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, 1, v)
+
+	// another consumer takes the element while the limiter is waited for
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan error)
+	go func() {
+		_, err := queue.DequeueContext(ctx)
+		done <- err
+	}()
+	fake.BlockUntil(1)
+	v, _ = inner.Dequeue()
+	assert.Equal(t, 2, v)
+	fake.Advance(time.Second)
+	cancel()
+	select {
+	case err := <-done:
+		assert.ErrorIs(t, err, context.Canceled)
+	case <-time.After(time.Second):
+		assert.Fail(t, "the dequeue ignored the context")
+	}
+
+	inner.Enqueue(3)
+	values := make(chan int)
+	go func() {
+		v, _ := queue.DequeueContext(context.Background())
+		values <- v
+	}()
+	fake.BlockUntil(1)
+	inner.Dequeue()
+	fake.Advance(time.Second)
+	inner.Enqueue(4)
+	assert.Equal(t, 4, <-values)
+}
+
+func TestRateLimited_JSON(t *testing.T) {
+	queue := NewRateLimited[int](NewQueue[int](), NewTokenBucket(1, 1))
+	assert.Nil(t, json.Unmarshal([]byte(`[1,2,3]`), queue))
+	queue.Enqueue(4)
+	jsonBytes, err := json.Marshal(queue)
+	assert.Nil(t, err)
+	assert.JSONEq(t, `[1,2,3,4]`, string(jsonBytes))
+}
@@ -0,0 +1,93 @@
+package queue
+
+import (
+	"expvar"
+	"sync/atomic"
+	"time"
+)
+
+// Stats snapshot of the activity of a blocking queue
+type Stats struct {
+	// Depth number of elements in the queue
+	Depth int64 `json:"depth"`
+	// Capacity maximum number of elements
+	Capacity int64 `json:"capacity"`
+	// Enqueued total number of enqueued elements
+	Enqueued int64 `json:"enqueued"`
+	// Dequeued total number of dequeued elements
+	Dequeued int64 `json:"dequeued"`
+	// Removed total number of elements removed without being dequeued, by Clear or RemoveWhere;
+	// Enqueued - Dequeued - Removed is the depth of the queue
+	Removed int64 `json:"removed"`
+	// BlockedProducers number of producers currently waiting for room
+	BlockedProducers int64 `json:"blocked_producers"`
+	// BlockedConsumers number of consumers currently waiting for an element
+	BlockedConsumers int64 `json:"blocked_consumers"`
+	// ProducerWait total time spent by the producers waiting for room
+	ProducerWait time.Duration `json:"producer_wait"`
+	// ConsumerWait total time spent by the consumers waiting for an element
+	ConsumerWait time.Duration `json:"consumer_wait"`
+}
+
+// StatsProvider queue reporting its stats
+type StatsProvider interface {
+	Stats() Stats
+}
+
+// StatsVar returns an expvar variable reporting the stats of the queue, it can be added to an expvar.Map
+func StatsVar(provider StatsProvider) expvar.Var {
+	return expvar.Func(func() any {
+		return provider.Stats()
+	})
+}
+
+// PublishStats publishes the stats of the queue as an expvar variable with the given name,
+// like expvar.Publish it panics when the name is already registered
+func PublishStats(name string, provider StatsProvider) {
+	expvar.Publish(name, StatsVar(provider))
+}
+
+// stats counters of a blocking queue, they are only updated atomically
+type stats struct {
+	enqueued         atomic.Int64
+	dequeued         atomic.Int64
+	removed          atomic.Int64
+	blockedProducers atomic.Int64
+	blockedConsumers atomic.Int64
+	producerWait     atomic.Int64
+	consumerWait     atomic.Int64
+}
+
+// blockProducer records a producer starting to wait, the returned function records the end of the wait
+func (s *stats) blockProducer() func() {
+	s.blockedProducers.Add(1)
+	start := time.Now()
+	return func() {
+		s.producerWait.Add(int64(time.Since(start)))
+		s.blockedProducers.Add(-1)
+	}
+}
+
+// blockConsumer records a consumer starting to wait, the returned function records the end of the wait
+func (s *stats) blockConsumer() func() {
+	s.blockedConsumers.Add(1)
+	start := time.Now()
+	return func() {
+		s.consumerWait.Add(int64(time.Since(start)))
+		s.blockedConsumers.Add(-1)
+	}
+}
+
+func (s *stats) snapshot(depth, capacity int64) Stats {
+	return Stats{
+		Depth:            depth,
+		Capacity:         capacity,
+		Enqueued:         s.enqueued.Load(),
+		Dequeued:         s.dequeued.Load(),
+		Removed:          s.removed.Load(),
+		BlockedProducers: s.blockedProducers.Load(),
+		BlockedConsumers: s.blockedConsumers.Load(),
+		ProducerWait:     time.Duration(s.producerWait.Load()),
+		ConsumerWait:     time.Duration(s.consumerWait.Load()),
+	}
+}
@@ -0,0 +1,101 @@
+package queue
+
+import (
+	"encoding/json"
+	"expvar"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testStats(t *testing.T, queue interface {
+	StatsProvider
+	Enqueue(value int) bool
+	Dequeue() (int, bool)
+	EnqueueTimeout(value int, duration time.Duration) bool
+	DequeueTimeout(duration time.Duration) (int, bool)
+	Clear()
+}) {
+	queue.Enqueue(1)
+	queue.Enqueue(2)
+	assert.False(t, queue.EnqueueTimeout(3, 10*time.Millisecond))
+	stats := queue.Stats()
+	assert.Equal(t, int64(2), stats.Depth)
+	assert.Equal(t, int64(2), stats.Capacity)
+	assert.Equal(t, int64(2), stats.Enqueued)
+	assert.GreaterOrEqual(t, stats.ProducerWait, 10*time.Millisecond)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		queue.Enqueue(3)
+	}()
+	assert.Eventually(t, func() bool {
+		return queue.Stats().BlockedProducers == 1
+	}, time.Second, time.Millisecond)
+	queue.Dequeue()
+	<-done
+	queue.Dequeue()
+	queue.Dequeue()
+	assert.Equal(t, int64(0), queue.Stats().BlockedProducers)
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		queue.Enqueue(4)
+	}()
+	v, ok := queue.DequeueTimeout(time.Second)
+	assert.True(t, ok)
+	assert.Equal(t, 4, v)
+	stats = queue.Stats()
+	assert.Equal(t, int64(0), stats.Depth)
+	assert.Equal(t, int64(4), stats.Enqueued)
+	assert.Equal(t, int64(4), stats.Dequeued)
+	assert.Equal(t, int64(0), stats.BlockedConsumers)
+	assert.Greater(t, stats.ConsumerWait, time.Duration(0))
+
+	queue.Enqueue(5)
+	queue.Enqueue(6)
+	queue.Clear()
+	stats = queue.Stats()
+	assert.Equal(t, int64(2), stats.Removed)
+	assert.Equal(t, stats.Depth, stats.Enqueued-stats.Dequeued-stats.Removed)
+}
+
+func TestBlockingQueue_Stats(t *testing.T) {
+	testStats(t, NewBlockingQueue[int](2))
+}
+
+func TestLinkedBlockingQueue_Stats(t *testing.T) {
+	testStats(t, NewLinkedBlockingQueue[int](2))
+
+	queue := NewLinkedBlockingQueue[int](4)
+	for i := 1; i <= 4; i++ {
+		queue.Enqueue(i)
+	}
+	queue.RemoveWhere(func(value int) bool {
+		return value%2 == 0
+	})
+	queue.Dequeue()
+	stats := queue.Stats()
+	assert.Equal(t, int64(2), stats.Removed)
+	assert.Equal(t, int64(1), stats.Depth)
+	assert.Equal(t, stats.Depth, stats.Enqueued-stats.Dequeued-stats.Removed)
+}
+
+func TestPublishStats(t *testing.T) {
+	queue := NewBlockingQueue[int](4)
+	queue.Enqueue(1)
+	name := fmt.Sprintf("queue_stats_%d", time.Now().UnixNano())
+	PublishStats(name, queue)
+	stats := Stats{}
+	err := json.Unmarshal([]byte(expvar.Get(name).String()), &stats)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), stats.Depth)
+	assert.Equal(t, int64(4), stats.Capacity)
+	assert.Equal(t, int64(1), stats.Enqueued)
+	assert.Panics(t, func() {
+		PublishStats(name, queue)
+	})
+}
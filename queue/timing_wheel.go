@@ -0,0 +1,325 @@
+package queue
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gopi-frame/contract/support"
+	"github.com/gopi-frame/support/clock"
+)
+
+// WheelHandle handle of an item added to a timing wheel,
+// it is used to cancel the item while it is still pending
+type WheelHandle[Q any] struct {
+	value      Q
+	expiration int64
+	callback   func(value Q)
+	bucket     *wheelBucket[Q]
+	prev       *WheelHandle[Q]
+	next       *WheelHandle[Q]
+}
+
+// Value returns the added item
+func (h *WheelHandle[Q]) Value() Q {
+	return h.value
+}
+
+// wheelBucket doubly linked list of the handles expiring in the same tick
+type wheelBucket[Q any] struct {
+	root       WheelHandle[Q]
+	expiration int64
+}
+
+func newWheelBucket[Q any]() *wheelBucket[Q] {
+	bucket := new(wheelBucket[Q])
+	bucket.root.next = &bucket.root
+	bucket.root.prev = &bucket.root
+	bucket.expiration = -1
+	return bucket
+}
+
+func (b *wheelBucket[Q]) Until() time.Time {
+	return time.Unix(0, b.expiration)
+}
+
+func (b *wheelBucket[Q]) Value() *wheelBucket[Q] {
+	return b
+}
+
+func (b *wheelBucket[Q]) add(handle *WheelHandle[Q]) {
+	handle.bucket = b
+	handle.prev = b.root.prev
+	handle.next = &b.root
+	handle.prev.next = handle
+	b.root.prev = handle
+}
+
+func (b *wheelBucket[Q]) remove(handle *WheelHandle[Q]) {
+	handle.prev.next = handle.next
+	handle.next.prev = handle.prev
+	handle.prev = nil
+	handle.next = nil
+	handle.bucket = nil
+}
+
+// flush removes all the handles of the bucket and resets its expiration
+func (b *wheelBucket[Q]) flush() []*WheelHandle[Q] {
+	handles := []*WheelHandle[Q]{}
+	for handle := b.root.next; handle != &b.root; {
+		next := handle.next
+		b.remove(handle)
+		handles = append(handles, handle)
+		handle = next
+	}
+	b.expiration = -1
+	return handles
+}
+
+// wheelLevel one level of the hierarchical timing wheel
+type wheelLevel[Q any] struct {
+	tick        int64
+	interval    int64
+	currentTime int64
+	buckets     []*wheelBucket[Q]
+	overflow    *wheelLevel[Q]
+}
+
+func newWheelLevel[Q any](tick int64, wheelSize int, startTime int64) *wheelLevel[Q] {
+	level := new(wheelLevel[Q])
+	level.tick = tick
+	level.interval = tick * int64(wheelSize)
+	if level.interval/int64(wheelSize) != tick {
+		level.interval = math.MaxInt64
+	}
+	level.currentTime = startTime - startTime%tick
+	level.buckets = make([]*wheelBucket[Q], wheelSize)
+	for index := range level.buckets {
+		level.buckets[index] = newWheelBucket[Q]()
+	}
+	return level
+}
+
+// add adds the handle to the level or its overflow levels, it returns the
+// bucket when its expiration changed, and false when the handle has expired
+func (l *wheelLevel[Q]) add(handle *WheelHandle[Q]) (*wheelBucket[Q], bool) {
+	if handle.expiration < l.currentTime+l.tick {
+		return nil, false
+	}
+	if handle.expiration-l.currentTime < l.interval {
+		virtualID := handle.expiration / l.tick
+		bucket := l.buckets[virtualID%int64(len(l.buckets))]
+		bucket.add(handle)
+		if expiration := virtualID * l.tick; bucket.expiration != expiration {
+			bucket.expiration = expiration
+			return bucket, true
+		}
+		return nil, true
+	}
+	if l.overflow == nil {
+		l.overflow = newWheelLevel[Q](l.interval, len(l.buckets), l.currentTime)
+	}
+	return l.overflow.add(handle)
+}
+
+func (l *wheelLevel[Q]) advance(now int64) {
+	if now >= l.currentTime+l.tick {
+		l.currentTime = now - now%l.tick
+		if l.overflow != nil {
+			l.overflow.advance(l.currentTime)
+		}
+	}
+}
+
+// NewTimingWheel new timing wheel with the given tick duration and number of buckets per level
+func NewTimingWheel[Q support.Delayable[T], T any](tick time.Duration, wheelSize int, opts ...Option) *TimingWheel[Q, T] {
+	if tick <= 0 || wheelSize <= 0 {
+		panic(fmt.Sprintf("queue: invalid timing wheel tick %s or size %d", tick, wheelSize))
+	}
+	options := newOptions(opts...)
+	wheel := new(TimingWheel[Q, T])
+	wheel.clock = options.clock
+	wheel.wheel = newWheelLevel[Q](int64(tick), wheelSize, wheel.clock.Now().UnixNano())
+	wheel.buckets = NewDelayedQueue[*wheelBucket[Q]](opts...)
+	wheel.expired = NewDelayedQueue[Q](opts...)
+	wheel.done = make(chan struct{})
+	return wheel
+}
+
+// TimingWheel hierarchical timing wheel.
+//
+// Items are hashed into the bucket of their expiration tick in O(1), items
+// expiring beyond the range of a level go to a lazily created overflow level
+// whose tick is the whole range of the previous one. Only the non-empty buckets
+// are kept in a delayed queue, so the wheel does not tick while idle.
+//
+// Expired items are delivered to their callback when added with AddFunc,
+// and to Dequeue, TryDequeue and DequeueTimeout otherwise, which hand them out
+// once their exact expiration time is reached.
+type TimingWheel[Q support.Delayable[T], T any] struct {
+	mu      sync.Mutex
+	clock   clock.Clock
+	wheel   *wheelLevel[Q]
+	buckets *DelayedQueue[*wheelBucket[Q], *wheelBucket[Q]]
+	expired *DelayedQueue[Q, T]
+	count   int64
+	started bool
+	stopped bool
+	done    chan struct{}
+}
+
+// Start starts advancing the wheel
+func (w *TimingWheel[Q, T]) Start() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.started || w.stopped {
+		return
+	}
+	w.started = true
+	go w.run()
+}
+
+// Stop stops advancing the wheel, the pending items are kept but never expire
+func (w *TimingWheel[Q, T]) Stop() {
+	w.mu.Lock()
+	if w.stopped {
+		w.mu.Unlock()
+		return
+	}
+	w.stopped = true
+	started := w.started
+	w.mu.Unlock()
+	if !started {
+		close(w.done)
+		return
+	}
+	// wake the loop up with an expired empty bucket
+	stop := newWheelBucket[Q]()
+	stop.expiration = 0
+	w.buckets.Enqueue(stop)
+	<-w.done
+}
+
+func (w *TimingWheel[Q, T]) run() {
+	defer close(w.done)
+	for {
+		bucket, _ := w.buckets.Dequeue()
+		w.mu.Lock()
+		if w.stopped {
+			w.mu.Unlock()
+			return
+		}
+		w.wheel.advance(bucket.expiration)
+		expired := []*WheelHandle[Q]{}
+		for _, handle := range bucket.flush() {
+			if !w.add(handle) {
+				expired = append(expired, handle)
+			}
+		}
+		w.count -= int64(len(expired))
+		w.mu.Unlock()
+		for _, handle := range expired {
+			w.deliver(handle)
+		}
+	}
+}
+
+// add adds the handle to the wheel, it must be called with the lock held
+func (w *TimingWheel[Q, T]) add(handle *WheelHandle[Q]) bool {
+	bucket, ok := w.wheel.add(handle)
+	if bucket != nil {
+		w.buckets.Enqueue(bucket)
+	}
+	return ok
+}
+
+func (w *TimingWheel[Q, T]) deliver(handle *WheelHandle[Q]) {
+	if handle.callback != nil {
+		go handle.callback(handle.value)
+	} else {
+		w.expired.Enqueue(handle.value)
+	}
+}
+
+func (w *TimingWheel[Q, T]) schedule(value Q, callback func(value Q)) *WheelHandle[Q] {
+	handle := &WheelHandle[Q]{
+		value:      value,
+		expiration: value.Until().UnixNano(),
+		callback:   callback,
+	}
+	w.mu.Lock()
+	ok := w.add(handle)
+	if ok {
+		w.count++
+	}
+	w.mu.Unlock()
+	if !ok {
+		w.deliver(handle)
+	}
+	return handle
+}
+
+// Add adds the item, it is delivered to Dequeue once expired
+func (w *TimingWheel[Q, T]) Add(value Q) *WheelHandle[Q] {
+	return w.schedule(value, nil)
+}
+
+// AddFunc adds the item, the callback is called in its own goroutine once expired
+func (w *TimingWheel[Q, T]) AddFunc(value Q, callback func(value Q)) *WheelHandle[Q] {
+	return w.schedule(value, callback)
+}
+
+// Cancel removes the pending item of the handle in O(1), it returns false when
+// the item has already expired or been cancelled
+func (w *TimingWheel[Q, T]) Cancel(handle *WheelHandle[Q]) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if handle.bucket == nil {
+		return false
+	}
+	handle.bucket.remove(handle)
+	w.count--
+	return true
+}
+
+// Count returns the number of pending items
+func (w *TimingWheel[Q, T]) Count() int64 {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.count
+}
+
+// IsEmpty returns whether there is no pending item
+func (w *TimingWheel[Q, T]) IsEmpty() bool {
+	return w.Count() == 0
+}
+
+// IsNotEmpty returns whether there are pending items
+func (w *TimingWheel[Q, T]) IsNotEmpty() bool {
+	return !w.IsEmpty()
+}
+
+// TryDequeue takes an expired item without blocking
+func (w *TimingWheel[Q, T]) TryDequeue() (Q, bool) {
+	return w.expired.TryDequeue()
+}
+
+// Dequeue takes an expired item, it blocks until an item expires
+func (w *TimingWheel[Q, T]) Dequeue() (Q, bool) {
+	return w.expired.Dequeue()
+}
+
+// DequeueTimeout takes an expired item, it blocks until an item expires or the duration elapses
+func (w *TimingWheel[Q, T]) DequeueTimeout(duration time.Duration) (Q, bool) {
+	return w.expired.DequeueTimeout(duration)
+}
+
+func (w *TimingWheel[Q, T]) String() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	str := new(strings.Builder)
+	str.WriteString(fmt.Sprintf("TimingWheel[%T](len=%d, tick=%s, size=%d)", *new(T), w.count, time.Duration(w.wheel.tick), len(w.wheel.buckets)))
+	return str.String()
+}
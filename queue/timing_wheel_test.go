@@ -0,0 +1,148 @@
+package queue
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gopi-frame/support/clock"
+	"github.com/stretchr/testify/assert"
+)
+
+// dequeueFake dequeues from a wheel driven by the fake clock, which never times out on its own:
+// it gives up after a second of real time and advances the fake clock to release the dequeue
+func dequeueFake(wheel *TimingWheel[_delay, int], fake *clock.FakeClock) (_delay, bool) {
+	type result struct {
+		value _delay
+		ok    bool
+	}
+	done := make(chan result, 1)
+	go func() {
+		v, ok := wheel.DequeueTimeout(time.Second)
+		done <- result{v, ok}
+	}()
+	select {
+	case r := <-done:
+		return r.value, r.ok
+	case <-time.After(time.Second):
+		fake.Advance(2 * time.Second)
+		<-done
+		return _delay{}, false
+	}
+}
+
+func TestTimingWheel_Add(t *testing.T) {
+	fake := clock.NewFakeClock(time.Now())
+	wheel := NewTimingWheel[_delay](time.Millisecond, 20, WithClock(fake))
+	wheel.Start()
+	defer wheel.Stop()
+	for i := 1; i <= 5; i++ {
+		wheel.Add(_delay{i, fake.Now().Add(time.Duration(i) * 10 * time.Millisecond)})
+	}
+	assert.Equal(t, int64(5), wheel.Count())
+	for i := 1; i <= 5; i++ {
+		fake.BlockUntil(1)
+		fake.Advance(10 * time.Millisecond)
+		v, ok := dequeueFake(wheel, fake)
+		assert.True(t, ok)
+		assert.Equal(t, i, v.Value())
+	}
+	assert.True(t, wheel.IsEmpty())
+}
+
+func TestTimingWheel_AddExpired(t *testing.T) {
+	wheel := NewTimingWheel[_delay](time.Millisecond, 20)
+	wheel.Add(_delay{1, time.Now().Add(-time.Second)})
+	v, ok := wheel.TryDequeue()
+	assert.True(t, ok)
+	assert.Equal(t, 1, v.Value())
+	assert.True(t, wheel.IsEmpty())
+}
+
+func TestTimingWheel_AddFunc(t *testing.T) {
+	wheel := NewTimingWheel[_delay](time.Millisecond, 8)
+	wheel.Start()
+	defer wheel.Stop()
+	fired := make(chan _delay, 1)
+	start := time.Now()
+	wheel.AddFunc(_delay{1, start.Add(50 * time.Millisecond)}, func(value _delay) {
+		fired <- value
+	})
+	select {
+	case v := <-fired:
+		assert.Equal(t, 1, v.Value())
+		assert.GreaterOrEqual(t, time.Since(start), 49*time.Millisecond)
+	case <-time.After(time.Second):
+		assert.Fail(t, "callback was not called")
+	}
+}
+
+func TestTimingWheel_Overflow(t *testing.T) {
+	fake := clock.NewFakeClock(time.Now())
+	wheel := NewTimingWheel[_delay](time.Millisecond, 4, WithClock(fake))
+	wheel.Start()
+	defer wheel.Stop()
+	// beyond the 4ms range of the first level and the 16ms range of the second one
+	wheel.Add(_delay{1, fake.Now().Add(50 * time.Millisecond)})
+	for i := 0; i < 50; i++ {
+		_, ok := wheel.TryDequeue()
+		assert.False(t, ok)
+		fake.BlockUntil(1)
+		fake.Advance(time.Millisecond)
+	}
+	v, ok := dequeueFake(wheel, fake)
+	assert.True(t, ok)
+	assert.Equal(t, 1, v.Value())
+}
+
+func TestTimingWheel_Cancel(t *testing.T) {
+	fake := clock.NewFakeClock(time.Now())
+	wheel := NewTimingWheel[_delay](time.Millisecond, 20, WithClock(fake))
+	wheel.Start()
+	defer wheel.Stop()
+	handle := wheel.Add(_delay{1, fake.Now().Add(5 * time.Millisecond)})
+	wheel.Add(_delay{2, fake.Now().Add(10 * time.Millisecond)})
+	assert.True(t, wheel.Cancel(handle))
+	assert.False(t, wheel.Cancel(handle))
+	assert.Equal(t, int64(1), wheel.Count())
+	fake.BlockUntil(1)
+	fake.Advance(10 * time.Millisecond)
+	v, ok := dequeueFake(wheel, fake)
+	assert.True(t, ok)
+	assert.Equal(t, 2, v.Value())
+}
+
+func TestTimingWheel_Many(t *testing.T) {
+	wheel := NewTimingWheel[_delay](time.Millisecond, 64)
+	wheel.Start()
+	defer wheel.Stop()
+	now := time.Now()
+	for i := 0; i < 10000; i++ {
+		wheel.Add(_delay{i, now.Add(time.Duration(i%100) * time.Millisecond)})
+	}
+	for i := 0; i < 10000; i++ {
+		_, ok := wheel.DequeueTimeout(time.Second)
+		assert.True(t, ok)
+	}
+	assert.True(t, wheel.IsEmpty())
+}
+
+func BenchmarkTimingWheel_AddCancel(b *testing.B) {
+	wheel := NewTimingWheel[_delay](time.Millisecond, 512)
+	now := time.Now()
+	for i := 0; i < b.N; i++ {
+		handle := wheel.Add(_delay{i, now.Add(time.Duration(i%10000+1) * time.Millisecond)})
+		wheel.Cancel(handle)
+	}
+}
+
+func BenchmarkDelayedQueue_ScheduleCancel(b *testing.B) {
+	queue := NewDelayedQueue[_delay]()
+	now := time.Now()
+	for i := 0; i < 1000; i++ {
+		queue.Enqueue(_delay{i, now.Add(time.Hour)})
+	}
+	for i := 0; i < b.N; i++ {
+		handle := queue.Schedule(_delay{i, now.Add(time.Duration(i%10000+1) * time.Millisecond)})
+		queue.Cancel(handle)
+	}
+}
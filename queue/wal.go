@@ -0,0 +1,114 @@
+package queue
+
+import (
+	"cmp"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// record kinds of the write-ahead log
+const (
+	walEnqueue byte = 1
+	walAck     byte = 2
+)
+
+// walHeaderSize length of the payload, checksum, kind and id of a record
+const walHeaderSize = 4 + 4 + 1 + 8
+
+const walExt = ".wal"
+
+// errTornRecord the log ends with an incomplete or corrupted record
+var errTornRecord = errors.New("queue: torn write-ahead log record")
+
+type walRecord struct {
+	kind    byte
+	id      uint64
+	payload []byte
+}
+
+// encode encodes the record as its header followed by the payload,
+// the checksum covers the kind, the id and the payload
+func (r walRecord) encode() []byte {
+	data := make([]byte, walHeaderSize+len(r.payload))
+	binary.LittleEndian.PutUint32(data[0:], uint32(len(r.payload)))
+	data[8] = r.kind
+	binary.LittleEndian.PutUint64(data[9:], r.id)
+	copy(data[walHeaderSize:], r.payload)
+	binary.LittleEndian.PutUint32(data[4:], crc32.ChecksumIEEE(data[8:]))
+	return data
+}
+
+// walSegment file of the write-ahead log, live is the number of its enqueue records not acked yet
+type walSegment struct {
+	seq  uint64
+	path string
+	live int64
+}
+
+func newWALSegment(dir string, seq uint64) *walSegment {
+	return &walSegment{seq: seq, path: filepath.Join(dir, fmt.Sprintf("%016d%s", seq, walExt))}
+}
+
+// listWALSegments returns the segments of the directory ordered by sequence number
+func listWALSegments(dir string) ([]*walSegment, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	segments := []*walSegment{}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, walExt) {
+			continue
+		}
+		seq, err := strconv.ParseUint(strings.TrimSuffix(name, walExt), 10, 64)
+		if err != nil {
+			continue
+		}
+		segments = append(segments, newWALSegment(dir, seq))
+	}
+	slices.SortFunc(segments, func(a, b *walSegment) int {
+		return cmp.Compare(a.seq, b.seq)
+	})
+	return segments, nil
+}
+
+// read calls the callback with the records of the segment, it returns the size of the
+// valid records and errTornRecord when they are followed by an incomplete or corrupted one
+func (s *walSegment) read(callback func(record walRecord) error) (int64, error) {
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return 0, err
+	}
+	offset := 0
+	for offset < len(data) {
+		if len(data)-offset < walHeaderSize {
+			return int64(offset), errTornRecord
+		}
+		header := data[offset : offset+walHeaderSize]
+		end := offset + walHeaderSize + int(binary.LittleEndian.Uint32(header[0:]))
+		if end > len(data) || end < offset {
+			return int64(offset), errTornRecord
+		}
+		if crc32.ChecksumIEEE(data[offset+8:end]) != binary.LittleEndian.Uint32(header[4:]) {
+			return int64(offset), errTornRecord
+		}
+		record := walRecord{
+			kind:    header[8],
+			id:      binary.LittleEndian.Uint64(header[9:]),
+			payload: data[offset+walHeaderSize : end],
+		}
+		if err := callback(record); err != nil {
+			return int64(offset), err
+		}
+		offset = end
+	}
+	return int64(offset), nil
+}
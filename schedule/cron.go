@@ -0,0 +1,218 @@
+package schedule
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var _ Trigger = (*CronTrigger)(nil)
+
+type cronBounds struct {
+	min   int
+	max   int
+	names map[string]int
+}
+
+var (
+	secondBounds = cronBounds{0, 59, nil}
+	minuteBounds = cronBounds{0, 59, nil}
+	hourBounds   = cronBounds{0, 23, nil}
+	domBounds    = cronBounds{1, 31, nil}
+	monthBounds  = cronBounds{1, 12, map[string]int{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}}
+	dowBounds = cronBounds{0, 7, map[string]int{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}}
+)
+
+var cronDescriptors = map[string]string{
+	"@yearly":   "0 0 0 1 1 *",
+	"@annually": "0 0 0 1 1 *",
+	"@monthly":  "0 0 0 1 * *",
+	"@weekly":   "0 0 0 * * 0",
+	"@daily":    "0 0 0 * * *",
+	"@midnight": "0 0 0 * * *",
+	"@hourly":   "0 0 * * * *",
+}
+
+// cronField bit set of the matched values of a cron field
+type cronField uint64
+
+func (f cronField) has(value int) bool {
+	return f&(1<<uint(value)) != 0
+}
+
+// CronTrigger trigger which fires at the times matched by a cron expression
+type CronTrigger struct {
+	second   cronField
+	minute   cronField
+	hour     cronField
+	dom      cronField
+	month    cronField
+	dow      cronField
+	domStar  bool
+	dowStar  bool
+	location *time.Location
+}
+
+// ParseCron parses a cron expression in the local time zone.
+//
+// Both the standard 5-field syntax (minute hour day-of-month month day-of-week)
+// and the 6-field syntax with a leading second field are supported, as well as
+// the @yearly, @monthly, @weekly, @daily and @hourly descriptors.
+func ParseCron(expr string) (*CronTrigger, error) {
+	return ParseCronInLocation(expr, time.Local)
+}
+
+// ParseCronInLocation parses a cron expression in the given time zone
+func ParseCronInLocation(expr string, location *time.Location) (*CronTrigger, error) {
+	spec := strings.TrimSpace(expr)
+	if descriptor, ok := cronDescriptors[strings.ToLower(spec)]; ok {
+		spec = descriptor
+	}
+	fields := strings.Fields(spec)
+	switch len(fields) {
+	case 5:
+		fields = append([]string{"0"}, fields...)
+	case 6:
+	default:
+		return nil, fmt.Errorf("schedule: invalid cron expression %q: expected 5 or 6 fields, got %d", expr, len(fields))
+	}
+	trigger := &CronTrigger{location: location}
+	var err error
+	for _, field := range []struct {
+		value  string
+		bounds cronBounds
+		dst    *cronField
+	}{
+		{fields[0], secondBounds, &trigger.second},
+		{fields[1], minuteBounds, &trigger.minute},
+		{fields[2], hourBounds, &trigger.hour},
+		{fields[3], domBounds, &trigger.dom},
+		{fields[4], monthBounds, &trigger.month},
+		{fields[5], dowBounds, &trigger.dow},
+	} {
+		if *field.dst, err = parseCronField(field.value, field.bounds); err != nil {
+			return nil, fmt.Errorf("schedule: invalid cron expression %q: %w", expr, err)
+		}
+	}
+	if trigger.dow.has(7) {
+		trigger.dow |= 1
+	}
+	trigger.domStar = isCronStar(fields[3])
+	trigger.dowStar = isCronStar(fields[5])
+	return trigger, nil
+}
+
+func isCronStar(field string) bool {
+	return field == "*" || field == "?"
+}
+
+func parseCronField(field string, bounds cronBounds) (cronField, error) {
+	var bits cronField
+	for _, term := range strings.Split(field, ",") {
+		rangeExpr, stepExpr, hasStep := strings.Cut(term, "/")
+		step := 1
+		if hasStep {
+			var err error
+			if step, err = strconv.Atoi(stepExpr); err != nil || step <= 0 {
+				return 0, fmt.Errorf("invalid step %q", stepExpr)
+			}
+		}
+		var from, to int
+		if rangeExpr == "*" || rangeExpr == "?" {
+			from, to = bounds.min, bounds.max
+		} else {
+			fromExpr, toExpr, hasTo := strings.Cut(rangeExpr, "-")
+			var err error
+			if from, err = parseCronValue(fromExpr, bounds); err != nil {
+				return 0, err
+			}
+			to = from
+			if hasTo {
+				if to, err = parseCronValue(toExpr, bounds); err != nil {
+					return 0, err
+				}
+			} else if hasStep {
+				to = bounds.max
+			}
+		}
+		if from > to {
+			return 0, fmt.Errorf("invalid range %q", rangeExpr)
+		}
+		for value := from; value <= to; value += step {
+			bits |= 1 << uint(value)
+		}
+	}
+	return bits, nil
+}
+
+func parseCronValue(expr string, bounds cronBounds) (int, error) {
+	if value, ok := bounds.names[strings.ToLower(expr)]; ok {
+		return value, nil
+	}
+	value, err := strconv.Atoi(expr)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value %q", expr)
+	}
+	if value < bounds.min || value > bounds.max {
+		return 0, fmt.Errorf("value %d out of range [%d, %d]", value, bounds.min, bounds.max)
+	}
+	return value, nil
+}
+
+func (c *CronTrigger) dayMatches(t time.Time) bool {
+	domMatches := c.dom.has(t.Day())
+	dowMatches := c.dow.has(int(t.Weekday()))
+	if c.domStar || c.dowStar {
+		return domMatches && dowMatches
+	}
+	return domMatches || dowMatches
+}
+
+// Next returns the first time matched by the expression after the scheduled time
+func (c *CronTrigger) Next(scheduled, _ time.Time) time.Time {
+	loc := c.location
+	t := scheduled.In(loc).Truncate(time.Second).Add(time.Second)
+	yearLimit := t.Year() + 5
+
+WRAP:
+	if t.Year() > yearLimit {
+		return time.Time{}
+	}
+	for !c.month.has(int(t.Month())) {
+		t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+		if t.Month() == time.January {
+			goto WRAP
+		}
+	}
+	for !c.dayMatches(t) {
+		t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+		if t.Day() == 1 {
+			goto WRAP
+		}
+	}
+	for !c.hour.has(t.Hour()) {
+		t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+		if t.Hour() == 0 {
+			goto WRAP
+		}
+	}
+	for !c.minute.has(t.Minute()) {
+		t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute()+1, 0, 0, loc)
+		if t.Minute() == 0 {
+			goto WRAP
+		}
+	}
+	for !c.second.has(t.Second()) {
+		t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second()+1, 0, loc)
+		if t.Second() == 0 {
+			goto WRAP
+		}
+	}
+	return t
+}
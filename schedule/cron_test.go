@@ -0,0 +1,47 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCron(t *testing.T) {
+	for _, expr := range []string{"* * * * *", "*/5 * * * * *", "0 9-17 * * MON-FRI", "0 0 1,15 jan,jul ?", "@daily"} {
+		_, err := ParseCron(expr)
+		assert.Nil(t, err, expr)
+	}
+	for _, expr := range []string{"", "* * * *", "60 * * * *", "* * * * * * *", "5-1 * * * *", "*/0 * * * *", "* * * foo *"} {
+		_, err := ParseCron(expr)
+		assert.NotNil(t, err, expr)
+	}
+}
+
+func TestCronTrigger_Next(t *testing.T) {
+	cases := []struct {
+		expr string
+		from string
+		next string
+	}{
+		{"* * * * *", "2024-05-17 10:20:30", "2024-05-17 10:21:00"},
+		{"*/15 * * * * *", "2024-05-17 10:20:30", "2024-05-17 10:20:45"},
+		{"0 9-17 * * MON-FRI", "2024-05-17 17:30:00", "2024-05-20 09:00:00"},
+		{"30 2 * * *", "2024-12-31 03:00:00", "2025-01-01 02:30:00"},
+		{"0 0 29 2 *", "2024-03-01 00:00:00", "2028-02-29 00:00:00"},
+		{"0 0 13 * 5", "2024-05-17 12:00:00", "2024-05-24 00:00:00"},
+		{"@monthly", "2024-05-17 12:00:00", "2024-06-01 00:00:00"},
+	}
+	for _, c := range cases {
+		trigger, err := ParseCronInLocation(c.expr, time.UTC)
+		assert.Nil(t, err)
+		from, _ := time.ParseInLocation(time.DateTime, c.from, time.UTC)
+		assert.Equal(t, c.next, trigger.Next(from, from).Format(time.DateTime), c.expr)
+	}
+}
+
+func TestCronTrigger_NextNever(t *testing.T) {
+	trigger, err := ParseCronInLocation("0 0 31 2 *", time.UTC)
+	assert.Nil(t, err)
+	assert.True(t, trigger.Next(time.Now(), time.Now()).IsZero())
+}
@@ -0,0 +1,264 @@
+package schedule
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gopi-frame/support/clock"
+	"github.com/gopi-frame/support/queue"
+)
+
+// ErrStopped is returned when scheduling a task on a stopped scheduler
+var ErrStopped = errors.New("schedule: scheduler stopped")
+
+// ErrInvalidInterval is returned when scheduling a task with a non-positive interval
+var ErrInvalidInterval = errors.New("schedule: interval must be positive")
+
+// Option scheduler option
+type Option func(s *Scheduler)
+
+// WithClock sets the clock used to compute and wait for the run times
+func WithClock(c clock.Clock) Option {
+	return func(s *Scheduler) {
+		s.clock = c
+	}
+}
+
+// WithMissedHandler sets the callback invoked with the number of runs
+// a task skipped because its previous run ended too late
+func WithMissedHandler(handler func(task *Task, missed int64)) Option {
+	return func(s *Scheduler) {
+		s.onMissed = handler
+	}
+}
+
+// maxMissedRuns bounds the number of missed runs counted by calling the trigger, beyond it
+// the task resumes at the first run time after the end of its run and the count stops there
+const maxMissedRuns = 1024
+
+// skipMissed returns the first run time from next which is not before finished, and the number of skipped runs
+func skipMissed(trigger Trigger, next, finished time.Time) (time.Time, int64) {
+	if next.IsZero() || !next.Before(finished) {
+		return next, 0
+	}
+	if interval, ok := trigger.(fixedRate); ok && interval > 0 {
+		elapsed := finished.Sub(next)
+		missed := elapsed / time.Duration(interval)
+		if elapsed%time.Duration(interval) != 0 {
+			missed++
+		}
+		return next.Add(missed * time.Duration(interval)), int64(missed)
+	}
+	missed := int64(0)
+	for !next.IsZero() && next.Before(finished) {
+		if missed == maxMissedRuns {
+			return trigger.Next(finished, finished), missed
+		}
+		missed++
+		next = trigger.Next(next, finished)
+	}
+	return next, missed
+}
+
+// run a pending run of a task
+type run struct {
+	task      *Task
+	scheduled time.Time
+}
+
+func (r *run) Until() time.Time {
+	return r.scheduled
+}
+
+func (r *run) Value() *Task {
+	return r.task
+}
+
+// Task recurring task
+type Task struct {
+	scheduler *Scheduler
+	trigger   Trigger
+	fn        func()
+	handle    *queue.DelayedHandle[*run]
+	cancelled atomic.Bool
+	runs      atomic.Int64
+	missed    atomic.Int64
+}
+
+// Cancel cancels the pending runs of the task, the running one is not interrupted
+func (t *Task) Cancel() {
+	t.scheduler.mu.Lock()
+	defer t.scheduler.mu.Unlock()
+	t.cancelled.Store(true)
+	if t.handle != nil {
+		t.scheduler.queue.Cancel(t.handle)
+		t.handle = nil
+	}
+}
+
+// IsCancelled returns whether the task is cancelled
+func (t *Task) IsCancelled() bool {
+	return t.cancelled.Load()
+}
+
+// Runs returns the number of completed runs
+func (t *Task) Runs() int64 {
+	return t.runs.Load()
+}
+
+// Missed returns the number of skipped runs
+func (t *Task) Missed() int64 {
+	return t.missed.Load()
+}
+
+// NewScheduler new scheduler
+func NewScheduler(options ...Option) *Scheduler {
+	s := new(Scheduler)
+	s.clock = clock.NewRealClock()
+	s.done = make(chan struct{})
+	for _, option := range options {
+		option(s)
+	}
+	s.queue = queue.NewDelayedQueue[*run](queue.WithClock(s.clock))
+	return s
+}
+
+// Scheduler runs recurring tasks on top of a delayed queue.
+//
+// A single dispatcher takes the due runs from the queue and executes each of
+// them in its own goroutine; the next run of a task is only scheduled once the
+// current one has finished, so the runs of a task never overlap.
+type Scheduler struct {
+	mu       sync.Mutex
+	clock    clock.Clock
+	queue    *queue.DelayedQueue[*run, *Task]
+	onMissed func(task *Task, missed int64)
+	running  sync.WaitGroup
+	started  bool
+	stopped  bool
+	done     chan struct{}
+}
+
+// Start starts dispatching the scheduled tasks
+func (s *Scheduler) Start() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.started || s.stopped {
+		return
+	}
+	s.started = true
+	go s.dispatch()
+}
+
+// Stop stops the scheduler, the pending runs are discarded and the running ones
+// are waited for until the context is done
+func (s *Scheduler) Stop(ctx context.Context) error {
+	s.mu.Lock()
+	if !s.stopped {
+		s.stopped = true
+		s.queue.Clear()
+		if s.started {
+			// wake the dispatcher up with a run without task
+			s.queue.Enqueue(&run{})
+		} else {
+			close(s.done)
+		}
+	}
+	s.mu.Unlock()
+	drained := make(chan struct{})
+	go func() {
+		<-s.done
+		s.running.Wait()
+		close(drained)
+	}()
+	select {
+	case <-drained:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Schedule schedules fn with the trigger
+func (s *Scheduler) Schedule(trigger Trigger, fn func()) (*Task, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stopped {
+		return nil, ErrStopped
+	}
+	task := &Task{scheduler: s, trigger: trigger, fn: fn}
+	now := s.clock.Now()
+	if next := trigger.Next(now, now); !next.IsZero() {
+		task.handle = s.queue.Schedule(&run{task: task, scheduled: next})
+	}
+	return task, nil
+}
+
+// ScheduleAtFixedRate schedules fn to run every interval
+func (s *Scheduler) ScheduleAtFixedRate(interval time.Duration, fn func()) (*Task, error) {
+	if interval <= 0 {
+		return nil, ErrInvalidInterval
+	}
+	return s.Schedule(FixedRate(interval), fn)
+}
+
+// ScheduleWithFixedDelay schedules fn to run with the delay between the end of a run and the start of the next one
+func (s *Scheduler) ScheduleWithFixedDelay(delay time.Duration, fn func()) (*Task, error) {
+	if delay <= 0 {
+		return nil, ErrInvalidInterval
+	}
+	return s.Schedule(FixedDelay(delay), fn)
+}
+
+// ScheduleCron schedules fn to run at the times matched by the cron expression
+func (s *Scheduler) ScheduleCron(expr string, fn func()) (*Task, error) {
+	trigger, err := ParseCron(expr)
+	if err != nil {
+		return nil, err
+	}
+	return s.Schedule(trigger, fn)
+}
+
+func (s *Scheduler) dispatch() {
+	defer close(s.done)
+	for {
+		r, _ := s.queue.Dequeue()
+		s.mu.Lock()
+		if s.stopped {
+			s.mu.Unlock()
+			return
+		}
+		if r.task == nil || r.task.IsCancelled() {
+			s.mu.Unlock()
+			continue
+		}
+		r.task.handle = nil
+		s.running.Add(1)
+		s.mu.Unlock()
+		go s.execute(r)
+	}
+}
+
+func (s *Scheduler) execute(r *run) {
+	defer s.running.Done()
+	task := r.task
+	task.fn()
+	task.runs.Add(1)
+	finished := s.clock.Now()
+	next, missed := skipMissed(task.trigger, task.trigger.Next(r.scheduled, finished), finished)
+	if missed > 0 {
+		task.missed.Add(missed)
+		if s.onMissed != nil {
+			s.onMissed(task, missed)
+		}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stopped || task.IsCancelled() || next.IsZero() {
+		return
+	}
+	task.handle = s.queue.Schedule(&run{task: task, scheduled: next})
+}
@@ -0,0 +1,206 @@
+package schedule
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gopi-frame/support/clock"
+	"github.com/stretchr/testify/assert"
+)
+
+var _start = time.Date(2024, 5, 17, 0, 0, 0, 0, time.UTC)
+
+// _rateTrigger fixed rate trigger which is not known to the scheduler
+type _rateTrigger time.Duration
+
+func (r _rateTrigger) Next(scheduled, _ time.Time) time.Time {
+	return scheduled.Add(time.Duration(r))
+}
+
+func TestScheduler_ScheduleAtFixedRate(t *testing.T) {
+	fake := clock.NewFakeClock(_start)
+	scheduler := NewScheduler(WithClock(fake))
+	scheduler.Start()
+	runs := make(chan time.Time, 10)
+	task, err := scheduler.ScheduleAtFixedRate(time.Minute, func() {
+		runs <- fake.Now()
+	})
+	assert.Nil(t, err)
+	for i := 1; i <= 5; i++ {
+		fake.BlockUntil(1)
+		fake.Advance(time.Minute)
+		assert.Equal(t, _start.Add(time.Duration(i)*time.Minute), <-runs)
+	}
+	assert.Nil(t, scheduler.Stop(context.Background()))
+	assert.Equal(t, int64(5), task.Runs())
+	_, err = scheduler.ScheduleAtFixedRate(0, func() {})
+	assert.ErrorIs(t, err, ErrInvalidInterval)
+}
+
+func TestScheduler_ScheduleWithFixedDelay(t *testing.T) {
+	fake := clock.NewFakeClock(_start)
+	scheduler := NewScheduler(WithClock(fake))
+	scheduler.Start()
+	runs := make(chan time.Time, 10)
+	task, err := scheduler.ScheduleWithFixedDelay(time.Minute, func() {
+		runs <- fake.Now()
+		// every run lasts 30 seconds
+		fake.Advance(30 * time.Second)
+	})
+	assert.Nil(t, err)
+	for i := 0; i < 3; i++ {
+		fake.BlockUntil(1)
+		fake.Advance(time.Minute)
+		assert.Equal(t, _start.Add(time.Minute+time.Duration(i)*90*time.Second), <-runs)
+	}
+	assert.Nil(t, scheduler.Stop(context.Background()))
+	assert.Equal(t, int64(3), task.Runs())
+	assert.Equal(t, int64(0), task.Missed())
+}
+
+func TestScheduler_ScheduleCron(t *testing.T) {
+	fake := clock.NewFakeClock(_start)
+	scheduler := NewScheduler(WithClock(fake))
+	scheduler.Start()
+	runs := make(chan time.Time, 10)
+	_, err := scheduler.ScheduleCron("* * * * * *", func() {
+		runs <- fake.Now()
+	})
+	assert.Nil(t, err)
+	for i := 1; i <= 2; i++ {
+		fake.BlockUntil(1)
+		fake.Advance(time.Second)
+		assert.Equal(t, _start.Add(time.Duration(i)*time.Second), <-runs)
+	}
+	assert.Nil(t, scheduler.Stop(context.Background()))
+	_, err = scheduler.ScheduleCron("* *", func() {})
+	assert.NotNil(t, err)
+}
+
+func TestScheduler_Missed(t *testing.T) {
+	fake := clock.NewFakeClock(_start)
+	var reported atomic.Int64
+	scheduler := NewScheduler(WithClock(fake), WithMissedHandler(func(task *Task, missed int64) {
+		reported.Add(missed)
+	}))
+	scheduler.Start()
+	runs := make(chan time.Time, 10)
+	var first atomic.Bool
+	first.Store(true)
+	task, _ := scheduler.ScheduleAtFixedRate(time.Millisecond, func() {
+		runs <- fake.Now()
+		// the first run lasts an hour, the runs it overlaps are counted without calling the trigger
+		if first.Swap(false) {
+			fake.Advance(time.Hour)
+		}
+	})
+	fake.BlockUntil(1)
+	fake.Advance(time.Millisecond)
+	assert.Equal(t, _start.Add(time.Millisecond), <-runs)
+	// the run due at the end of the long one is not missed
+	assert.Equal(t, _start.Add(time.Hour+time.Millisecond), <-runs)
+	fake.BlockUntil(1)
+	assert.Equal(t, int64(time.Hour/time.Millisecond-1), task.Missed())
+	assert.Equal(t, task.Missed(), reported.Load())
+	assert.Nil(t, scheduler.Stop(context.Background()))
+	assert.Equal(t, int64(2), task.Runs())
+}
+
+func TestScheduler_MissedLimit(t *testing.T) {
+	fake := clock.NewFakeClock(_start)
+	scheduler := NewScheduler(WithClock(fake))
+	scheduler.Start()
+	runs := make(chan time.Time, 10)
+	var first atomic.Bool
+	first.Store(true)
+	task, _ := scheduler.Schedule(_rateTrigger(time.Millisecond), func() {
+		runs <- fake.Now()
+		if first.Swap(false) {
+			fake.Advance(time.Hour)
+		}
+	})
+	fake.BlockUntil(1)
+	fake.Advance(time.Millisecond)
+	<-runs
+	// the task resumes after the end of the long run
+	fake.BlockUntil(1)
+	assert.Equal(t, int64(maxMissedRuns), task.Missed())
+	fake.Advance(time.Millisecond)
+	assert.Equal(t, _start.Add(time.Hour+2*time.Millisecond), <-runs)
+	assert.Nil(t, scheduler.Stop(context.Background()))
+}
+
+func TestTask_Cancel(t *testing.T) {
+	fake := clock.NewFakeClock(_start)
+	scheduler := NewScheduler(WithClock(fake))
+	scheduler.Start()
+	var count atomic.Int64
+	started := make(chan struct{})
+	release := make(chan struct{})
+	task, _ := scheduler.ScheduleAtFixedRate(time.Minute, func() {
+		count.Add(1)
+		started <- struct{}{}
+		<-release
+	})
+	fake.BlockUntil(1)
+	fake.Advance(time.Minute)
+	<-started
+	// the task is cancelled while it runs, the run in progress does not schedule the next one
+	task.Cancel()
+	assert.True(t, task.IsCancelled())
+	close(release)
+	scheduler.running.Wait()
+	assert.True(t, scheduler.queue.IsEmpty())
+	assert.Equal(t, int64(1), task.Runs())
+
+	pending, _ := scheduler.ScheduleAtFixedRate(time.Minute, func() {
+		count.Add(1)
+	})
+	pending.Cancel()
+	assert.True(t, scheduler.queue.IsEmpty())
+	fake.Advance(time.Hour)
+	assert.Nil(t, scheduler.Stop(context.Background()))
+	assert.Equal(t, int64(1), count.Load())
+}
+
+func TestScheduler_Stop(t *testing.T) {
+	fake := clock.NewFakeClock(_start)
+	scheduler := NewScheduler(WithClock(fake))
+	scheduler.Start()
+	started := make(chan struct{})
+	release := make(chan struct{})
+	_, _ = scheduler.ScheduleAtFixedRate(time.Minute, func() {
+		close(started)
+		<-release
+	})
+	fake.BlockUntil(1)
+	fake.Advance(time.Minute)
+	<-started
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, scheduler.Stop(ctx), context.DeadlineExceeded)
+	close(release)
+	assert.Nil(t, scheduler.Stop(context.Background()))
+	_, err := scheduler.ScheduleAtFixedRate(time.Second, func() {})
+	assert.ErrorIs(t, err, ErrStopped)
+}
+
+func TestScheduler_WithClock(t *testing.T) {
+	fake := clock.NewFakeClock(_start)
+	scheduler := NewScheduler(WithClock(fake))
+	scheduler.Start()
+	runs := make(chan time.Time, 10)
+	task, _ := scheduler.ScheduleAtFixedRate(time.Minute, func() {
+		runs <- fake.Now()
+	})
+	for i := 1; i <= 3; i++ {
+		fake.BlockUntil(1)
+		fake.Advance(time.Minute)
+		assert.Equal(t, time.Date(2024, 5, 17, 0, i, 0, 0, time.UTC), <-runs)
+	}
+	assert.Nil(t, scheduler.Stop(context.Background()))
+	assert.Equal(t, int64(3), task.Runs())
+	assert.Equal(t, int64(0), task.Missed())
+}
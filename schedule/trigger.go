@@ -0,0 +1,32 @@
+package schedule
+
+import "time"
+
+// Trigger computes the run times of a recurring task
+type Trigger interface {
+	// Next returns the next run time from the scheduled and finished time of the
+	// previous run, a zero time means the task will not run anymore.
+	Next(scheduled, finished time.Time) time.Time
+}
+
+// FixedRate returns a trigger which fires every interval, measured between the scheduled times of two runs
+func FixedRate(interval time.Duration) Trigger {
+	return fixedRate(interval)
+}
+
+// FixedDelay returns a trigger which fires after the delay, measured from the end of the previous run
+func FixedDelay(delay time.Duration) Trigger {
+	return fixedDelay(delay)
+}
+
+type fixedRate time.Duration
+
+func (r fixedRate) Next(scheduled, _ time.Time) time.Time {
+	return scheduled.Add(time.Duration(r))
+}
+
+type fixedDelay time.Duration
+
+func (d fixedDelay) Next(_, finished time.Time) time.Time {
+	return finished.Add(time.Duration(d))
+}
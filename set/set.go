@@ -3,6 +3,7 @@ package set
 import (
 	"encoding/json"
 	"fmt"
+	"slices"
 	"strings"
 	"sync"
 
@@ -110,9 +111,9 @@ func (s *Set[E]) Clone() *Set[E] {
 	return NewSet(s.items...)
 }
 
-// ToArray to array
+// ToArray returns a copy of the elements
 func (s *Set[E]) ToArray() []E {
-	return s.items
+	return slices.Clone(s.items)
 }
 
 // ToJSON to json
@@ -83,6 +83,10 @@ func TestSet_Clone(t *testing.T) {
 func TestSet_ToArray(t *testing.T) {
 	set := NewSet[types.Int](1, 2, 3)
 	assert.Equal(t, []types.Int{1, 2, 3}, set.ToArray())
+	values := set.ToArray()
+	values[0] = 10
+	assert.True(t, set.Contains(1))
+	assert.False(t, set.Contains(10))
 }
 
 func TestSet_ToJSON(t *testing.T) {
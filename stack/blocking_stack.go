@@ -0,0 +1,188 @@
+package stack
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/gopi-frame/support/internal/syncutil"
+)
+
+// NewBlockingStack new blocking stack
+func NewBlockingStack[E any](cap int) *BlockingStack[E] {
+	stack := new(BlockingStack[E])
+	stack.items = make([]E, 0, cap)
+	stack.cap = cap
+	stack.notEmpty = sync.NewCond(&stack.mu)
+	stack.notFull = sync.NewCond(&stack.mu)
+	return stack
+}
+
+// BlockingStack blocking stack
+//
+// It is bounded, Push blocks while the stack is full and Pop while it is empty.
+// Both ends of a stack are the same, so a single lock guards it.
+type BlockingStack[E any] struct {
+	mu       sync.Mutex
+	items    []E
+	cap      int
+	notEmpty *sync.Cond
+	notFull  *sync.Cond
+}
+
+// push pushes the value, it must be called with the lock held
+func (s *BlockingStack[E]) push(value E) {
+	s.items = append(s.items, value)
+	s.notEmpty.Signal()
+}
+
+// pop pops the top value, it must be called with the lock held
+func (s *BlockingStack[E]) pop() E {
+	last := len(s.items) - 1
+	value := s.items[last]
+	s.items[last] = *new(E)
+	s.items = s.items[:last]
+	s.notFull.Signal()
+	return value
+}
+
+func (s *BlockingStack[E]) Count() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return int64(len(s.items))
+}
+
+func (s *BlockingStack[E]) IsEmpty() bool {
+	return s.Count() == 0
+}
+
+func (s *BlockingStack[E]) IsNotEmpty() bool {
+	return !s.IsEmpty()
+}
+
+func (s *BlockingStack[E]) Clear() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	clear(s.items)
+	s.items = s.items[:0]
+	s.notFull.Broadcast()
+}
+
+func (s *BlockingStack[E]) Peek() (E, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.items) == 0 {
+		return *new(E), false
+	}
+	return s.items[len(s.items)-1], true
+}
+
+func (s *BlockingStack[E]) TryPush(value E) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.items) == s.cap {
+		return false
+	}
+	s.push(value)
+	return true
+}
+
+func (s *BlockingStack[E]) TryPop() (E, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.items) == 0 {
+		return *new(E), false
+	}
+	return s.pop(), true
+}
+
+func (s *BlockingStack[E]) Push(value E) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for len(s.items) == s.cap {
+		s.notFull.Wait()
+	}
+	s.push(value)
+	return true
+}
+
+func (s *BlockingStack[E]) Pop() (E, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for len(s.items) == 0 {
+		s.notEmpty.Wait()
+	}
+	return s.pop(), true
+}
+
+func (s *BlockingStack[E]) PushTimeout(value E, duration time.Duration) bool {
+	deadline := time.Now().Add(duration)
+	timer := syncutil.BroadcastAfter(s.notFull, duration)
+	defer timer.Stop()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for len(s.items) == s.cap {
+		if !time.Now().Before(deadline) {
+			return false
+		}
+		s.notFull.Wait()
+	}
+	s.push(value)
+	return true
+}
+
+func (s *BlockingStack[E]) PopTimeout(duration time.Duration) (E, bool) {
+	deadline := time.Now().Add(duration)
+	timer := syncutil.BroadcastAfter(s.notEmpty, duration)
+	defer timer.Stop()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for len(s.items) == 0 {
+		if !time.Now().Before(deadline) {
+			return *new(E), false
+		}
+		s.notEmpty.Wait()
+	}
+	return s.pop(), true
+}
+
+// ToArray returns the values from the bottom to the top
+func (s *BlockingStack[E]) ToArray() []E {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.items)
+}
+
+func (s *BlockingStack[E]) ToJSON() ([]byte, error) {
+	return json.Marshal(s.ToArray())
+}
+
+func (s *BlockingStack[E]) MarshalJSON() ([]byte, error) {
+	return s.ToJSON()
+}
+
+// UnmarshalJSON replaces the elements of the stack
+func (s *BlockingStack[E]) UnmarshalJSON(data []byte) error {
+	values := make([]E, 0)
+	if err := json.Unmarshal(data, &values); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(values) > s.cap {
+		return fmt.Errorf("stack: %d values exceed the capacity %d", len(values), s.cap)
+	}
+	clear(s.items)
+	s.items = append(s.items[:0], values...)
+	s.notEmpty.Broadcast()
+	s.notFull.Broadcast()
+	return nil
+}
+
+func (s *BlockingStack[E]) String() string {
+	values := s.ToArray()
+	slices.Reverse(values)
+	return format("BlockingStack", values)
+}
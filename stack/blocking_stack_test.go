@@ -0,0 +1,134 @@
+package stack
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBlockingStack_Count(t *testing.T) {
+	stack := NewBlockingStack[int](5)
+	assert.True(t, stack.IsEmpty())
+	for i := 0; i < 5; i++ {
+		stack.Push(i)
+	}
+	assert.Equal(t, int64(5), stack.Count())
+	stack.Clear()
+	assert.True(t, stack.IsEmpty())
+}
+
+func TestBlockingStack_Peek(t *testing.T) {
+	stack := NewBlockingStack[int](5)
+	_, ok := stack.Peek()
+	assert.False(t, ok)
+	stack.Push(1)
+	stack.Push(2)
+	v, ok := stack.Peek()
+	assert.True(t, ok)
+	assert.Equal(t, 2, v)
+	assert.Equal(t, int64(2), stack.Count())
+}
+
+func TestBlockingStack_TryPush(t *testing.T) {
+	stack := NewBlockingStack[int](2)
+	assert.True(t, stack.TryPush(1))
+	assert.True(t, stack.TryPush(2))
+	assert.False(t, stack.TryPush(3))
+	v, ok := stack.TryPop()
+	assert.True(t, ok)
+	assert.Equal(t, 2, v)
+	v, ok = stack.TryPop()
+	assert.True(t, ok)
+	assert.Equal(t, 1, v)
+	_, ok = stack.TryPop()
+	assert.False(t, ok)
+}
+
+func TestBlockingStack_PushTimeout(t *testing.T) {
+	stack := NewBlockingStack[int](1)
+	assert.True(t, stack.PushTimeout(1, time.Millisecond))
+	start := time.Now()
+	assert.False(t, stack.PushTimeout(2, 20*time.Millisecond))
+	assert.GreaterOrEqual(t, time.Since(start), 20*time.Millisecond)
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		stack.Pop()
+	}()
+	assert.True(t, stack.PushTimeout(3, time.Second))
+	v, _ := stack.Peek()
+	assert.Equal(t, 3, v)
+}
+
+func TestBlockingStack_PopTimeout(t *testing.T) {
+	stack := NewBlockingStack[int](1)
+	start := time.Now()
+	_, ok := stack.PopTimeout(20 * time.Millisecond)
+	assert.False(t, ok)
+	assert.GreaterOrEqual(t, time.Since(start), 20*time.Millisecond)
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		stack.Push(1)
+	}()
+	v, ok := stack.PopTimeout(time.Second)
+	assert.True(t, ok)
+	assert.Equal(t, 1, v)
+}
+
+func TestBlockingStack_ProducersConsumers(t *testing.T) {
+	const workers, perWorker = 4, 1000
+	stack := NewBlockingStack[int](8)
+	seen := make([]int, workers*perWorker)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(2)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < perWorker; i++ {
+				stack.Push(w*perWorker + i)
+			}
+		}(w)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < perWorker; i++ {
+				v, _ := stack.Pop()
+				mu.Lock()
+				seen[v]++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	for i := range seen {
+		assert.Equal(t, 1, seen[i])
+	}
+	assert.True(t, stack.IsEmpty())
+}
+
+func TestBlockingStack_JSON(t *testing.T) {
+	stack := NewBlockingStack[int](5)
+	stack.Push(9)
+	err := json.Unmarshal([]byte(`[1,2,3]`), stack)
+	assert.Nil(t, err)
+	assert.Equal(t, []int{1, 2, 3}, stack.ToArray())
+	assert.NotNil(t, json.Unmarshal([]byte(`[1,2,3,4,5,6]`), stack))
+	assert.Equal(t, []int{1, 2, 3}, stack.ToArray())
+	jsonBytes, err := json.Marshal(stack)
+	assert.Nil(t, err)
+	assert.JSONEq(t, `[1,2,3]`, string(jsonBytes))
+}
+
+func TestBlockingStack_String(t *testing.T) {
+	stack := NewBlockingStack[int](10)
+	for i := 1; i <= 7; i++ {
+		stack.Push(i)
+	}
+	str := stack.String()
+	pattern := regexp.MustCompile(fmt.Sprintf(`BlockingStack\[int\]\(len=%d\)\{\n\t7,\n(\t\d+,\n){4}\t(\.){3}\n\}`, stack.Count()))
+	assert.True(t, pattern.Match([]byte(str)))
+}
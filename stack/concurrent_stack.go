@@ -0,0 +1,142 @@
+package stack
+
+import (
+	"encoding/json"
+	"slices"
+	"sync/atomic"
+)
+
+type concurrentStackNode[E any] struct {
+	value E
+	next  *concurrentStackNode[E]
+}
+
+// NewConcurrentStack new concurrent stack, the last value is on the top
+func NewConcurrentStack[E any](values ...E) *ConcurrentStack[E] {
+	stack := new(ConcurrentStack[E])
+	stack.Push(values...)
+	return stack
+}
+
+// ConcurrentStack lock-free stack.
+//
+// It is a Treiber stack: the nodes are immutable once pushed, and push and pop
+// swap the top pointer with a CAS. Count and the traversals are weakly
+// consistent while the stack is accessed concurrently.
+type ConcurrentStack[E any] struct {
+	top   atomic.Pointer[concurrentStackNode[E]]
+	count atomic.Int64
+}
+
+func (s *ConcurrentStack[E]) Count() int64 {
+	return s.count.Load()
+}
+
+func (s *ConcurrentStack[E]) IsEmpty() bool {
+	return s.top.Load() == nil
+}
+
+func (s *ConcurrentStack[E]) IsNotEmpty() bool {
+	return !s.IsEmpty()
+}
+
+func (s *ConcurrentStack[E]) Clear() {
+	for {
+		if _, ok := s.Pop(); !ok {
+			return
+		}
+	}
+}
+
+func (s *ConcurrentStack[E]) Peek() (E, bool) {
+	top := s.top.Load()
+	if top == nil {
+		return *new(E), false
+	}
+	return top.value, true
+}
+
+func (s *ConcurrentStack[E]) Push(values ...E) {
+	for _, value := range values {
+		node := &concurrentStackNode[E]{value: value}
+		s.count.Add(1)
+		for {
+			top := s.top.Load()
+			node.next = top
+			if s.top.CompareAndSwap(top, node) {
+				break
+			}
+		}
+	}
+}
+
+func (s *ConcurrentStack[E]) Pop() (E, bool) {
+	for {
+		top := s.top.Load()
+		if top == nil {
+			return *new(E), false
+		}
+		if s.top.CompareAndSwap(top, top.next) {
+			s.count.Add(-1)
+			return top.value, true
+		}
+	}
+}
+
+func (s *ConcurrentStack[E]) TryPush(value E) bool {
+	s.Push(value)
+	return true
+}
+
+func (s *ConcurrentStack[E]) TryPop() (E, bool) {
+	return s.Pop()
+}
+
+// ToArray returns the values from the bottom to the top
+func (s *ConcurrentStack[E]) ToArray() []E {
+	values := []E{}
+	for node := s.top.Load(); node != nil; node = node.next {
+		values = append(values, node.value)
+	}
+	slices.Reverse(values)
+	return values
+}
+
+func (s *ConcurrentStack[E]) ToJSON() ([]byte, error) {
+	return json.Marshal(s.ToArray())
+}
+
+func (s *ConcurrentStack[E]) MarshalJSON() ([]byte, error) {
+	return s.ToJSON()
+}
+
+// UnmarshalJSON replaces the elements of the stack, the new ones are swapped in at once
+func (s *ConcurrentStack[E]) UnmarshalJSON(data []byte) error {
+	values := []E{}
+	if err := json.Unmarshal(data, &values); err != nil {
+		return err
+	}
+	var top *concurrentStackNode[E]
+	for _, value := range values {
+		top = &concurrentStackNode[E]{value: value, next: top}
+	}
+	for {
+		old := s.top.Load()
+		if s.top.CompareAndSwap(old, top) {
+			removed := 0
+			for node := old; node != nil; node = node.next {
+				removed++
+			}
+			s.count.Add(int64(len(values) - removed))
+			return nil
+		}
+	}
+}
+
+func (s *ConcurrentStack[E]) String() string {
+	values := []E{}
+	for node := s.top.Load(); node != nil; node = node.next {
+		values = append(values, node.value)
+	}
+	return format("ConcurrentStack", values)
+}
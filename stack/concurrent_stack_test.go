@@ -0,0 +1,102 @@
+package stack
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConcurrentStack_Count(t *testing.T) {
+	stack := NewConcurrentStack(1, 2, 3)
+	assert.Equal(t, int64(3), stack.Count())
+	assert.True(t, stack.IsNotEmpty())
+	stack.Clear()
+	assert.True(t, stack.IsEmpty())
+	assert.Equal(t, int64(0), stack.Count())
+}
+
+func TestConcurrentStack_Peek(t *testing.T) {
+	stack := NewConcurrentStack(1, 2, 3)
+	v, ok := stack.Peek()
+	assert.True(t, ok)
+	assert.Equal(t, 3, v)
+	assert.Equal(t, int64(3), stack.Count())
+	_, ok = NewConcurrentStack[int]().Peek()
+	assert.False(t, ok)
+}
+
+func TestConcurrentStack_Pop(t *testing.T) {
+	stack := NewConcurrentStack(1, 2)
+	stack.Push(3, 4)
+	for i := 4; i >= 1; i-- {
+		v, ok := stack.Pop()
+		assert.True(t, ok)
+		assert.Equal(t, i, v)
+	}
+	_, ok := stack.TryPop()
+	assert.False(t, ok)
+}
+
+func TestConcurrentStack_JSON(t *testing.T) {
+	stack := NewConcurrentStack(1, 2, 3)
+	assert.Equal(t, []int{1, 2, 3}, stack.ToArray())
+	jsonBytes, err := json.Marshal(stack)
+	assert.Nil(t, err)
+	assert.JSONEq(t, `[1,2,3]`, string(jsonBytes))
+	stack = NewConcurrentStack(9)
+	err = json.Unmarshal([]byte(`[1,2,3]`), stack)
+	assert.Nil(t, err)
+	v, _ := stack.Peek()
+	assert.Equal(t, 3, v)
+	assert.Equal(t, []int{1, 2, 3}, stack.ToArray())
+	assert.Equal(t, int64(3), stack.Count())
+}
+
+func TestConcurrentStack_String(t *testing.T) {
+	stack := NewConcurrentStack(1, 2, 3, 4, 5, 6, 7)
+	str := stack.String()
+	pattern := regexp.MustCompile(fmt.Sprintf(`ConcurrentStack\[int\]\(len=%d\)\{\n\t7,\n(\t\d+,\n){4}\t(\.){3}\n\}`, stack.Count()))
+	assert.True(t, pattern.Match([]byte(str)))
+}
+
+func TestConcurrentStack_Stress(t *testing.T) {
+	const producers, consumers, perProducer = 8, 8, 5000
+	stack := NewConcurrentStack[int]()
+	seen := make([]atomic.Int32, producers*perProducer)
+	var consumed atomic.Int64
+	var wg sync.WaitGroup
+	for p := 0; p < producers; p++ {
+		wg.Add(1)
+		go func(p int) {
+			defer wg.Done()
+			for i := 0; i < perProducer; i++ {
+				stack.Push(p*perProducer + i)
+			}
+		}(p)
+	}
+	for c := 0; c < consumers; c++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for consumed.Load() < producers*perProducer {
+				if v, ok := stack.Pop(); ok {
+					seen[v].Add(1)
+					consumed.Add(1)
+				} else {
+					runtime.Gosched()
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	for i := range seen {
+		assert.Equal(t, int32(1), seen[i].Load())
+	}
+	assert.True(t, stack.IsEmpty())
+}
@@ -0,0 +1,107 @@
+package stack
+
+import (
+	"encoding/json"
+	"slices"
+
+	"github.com/gopi-frame/support/lists"
+)
+
+// NewLinkedStack new linked stack, the last value is on the top
+func NewLinkedStack[E any](values ...E) *LinkedStack[E] {
+	stack := new(LinkedStack[E])
+	stack.items = lists.NewLinkedList(values...)
+	return stack
+}
+
+// LinkedStack linked stack
+type LinkedStack[E any] struct {
+	items *lists.LinkedList[E]
+}
+
+func (s *LinkedStack[E]) Lock() {
+	s.items.Lock()
+}
+
+func (s *LinkedStack[E]) Unlock() {
+	s.items.Unlock()
+}
+
+func (s *LinkedStack[E]) TryLock() bool {
+	return s.items.TryLock()
+}
+
+func (s *LinkedStack[E]) RLock() {
+	s.items.RLock()
+}
+
+func (s *LinkedStack[E]) RUnlock() {
+	s.items.RUnlock()
+}
+
+func (s *LinkedStack[E]) TryRLock() bool {
+	return s.items.TryRLock()
+}
+
+func (s *LinkedStack[E]) Count() int64 {
+	return s.items.Count()
+}
+
+func (s *LinkedStack[E]) IsEmpty() bool {
+	return s.items.IsEmpty()
+}
+
+func (s *LinkedStack[E]) IsNotEmpty() bool {
+	return s.items.IsNotEmpty()
+}
+
+func (s *LinkedStack[E]) Clear() {
+	s.items.Clear()
+}
+
+func (s *LinkedStack[E]) Peek() (E, bool) {
+	return s.items.Last()
+}
+
+func (s *LinkedStack[E]) Push(values ...E) {
+	s.items.Push(values...)
+}
+
+func (s *LinkedStack[E]) Pop() (E, bool) {
+	return s.items.Pop()
+}
+
+// ToArray returns the values from the bottom to the top
+func (s *LinkedStack[E]) ToArray() []E {
+	values := s.items.ToArray()
+	if values == nil {
+		return []E{}
+	}
+	return values
+}
+
+func (s *LinkedStack[E]) ToJSON() ([]byte, error) {
+	return json.Marshal(s.ToArray())
+}
+
+func (s *LinkedStack[E]) MarshalJSON() ([]byte, error) {
+	return s.ToJSON()
+}
+
+// UnmarshalJSON replaces the elements of the stack
+func (s *LinkedStack[E]) UnmarshalJSON(data []byte) error {
+	var values = []E{}
+	err := json.Unmarshal(data, &values)
+	if err != nil {
+		return err
+	}
+	s.items.Clear()
+	s.items.Push(values...)
+	return nil
+}
+
+func (s *LinkedStack[E]) String() string {
+	values := s.ToArray()
+	slices.Reverse(values)
+	return format("LinkedStack", values)
+}
@@ -0,0 +1,67 @@
+package stack
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLinkedStack_Count(t *testing.T) {
+	stack := NewLinkedStack(1, 2, 3)
+	assert.Equal(t, int64(3), stack.Count())
+	assert.True(t, stack.IsNotEmpty())
+	stack.Clear()
+	assert.True(t, stack.IsEmpty())
+}
+
+func TestLinkedStack_Peek(t *testing.T) {
+	stack := NewLinkedStack(1, 2, 3)
+	v, ok := stack.Peek()
+	assert.True(t, ok)
+	assert.Equal(t, 3, v)
+	assert.Equal(t, int64(3), stack.Count())
+	_, ok = NewLinkedStack[int]().Peek()
+	assert.False(t, ok)
+}
+
+func TestLinkedStack_Pop(t *testing.T) {
+	stack := NewLinkedStack(1, 2)
+	stack.Push(3, 4)
+	for i := 4; i >= 1; i-- {
+		v, ok := stack.Pop()
+		assert.True(t, ok)
+		assert.Equal(t, i, v)
+	}
+	_, ok := stack.Pop()
+	assert.False(t, ok)
+}
+
+func TestLinkedStack_ToJSON(t *testing.T) {
+	stack := NewLinkedStack(1, 2, 3)
+	jsonBytes, err := stack.ToJSON()
+	assert.Nil(t, err)
+	assert.JSONEq(t, `[1,2,3]`, string(jsonBytes))
+	jsonBytes, err = json.Marshal(NewLinkedStack[int]())
+	assert.Nil(t, err)
+	assert.JSONEq(t, `[]`, string(jsonBytes))
+}
+
+func TestLinkedStack_UnmarshalJSON(t *testing.T) {
+	stack := NewLinkedStack[int](9)
+	err := json.Unmarshal([]byte(`[1,2,3]`), stack)
+	assert.Nil(t, err)
+	assert.Equal(t, []int{1, 2, 3}, stack.ToArray())
+	v, _ := stack.Pop()
+	assert.Equal(t, 3, v)
+}
+
+func TestLinkedStack_String(t *testing.T) {
+	stack := NewLinkedStack(1, 2, 3, 4, 5, 6, 7)
+	str := stack.String()
+	pattern := regexp.MustCompile(fmt.Sprintf(`LinkedStack\[int\]\(len=%d\)\{\n\t7,\n\t6,\n(\t\d+,\n){3}\t(\.){3}\n\}`, stack.Count()))
+	assert.True(t, pattern.Match([]byte(str)))
+	assert.Equal(t, []int{1, 2, 3, 4, 5, 6, 7}, stack.ToArray())
+}
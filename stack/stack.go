@@ -0,0 +1,135 @@
+package stack
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/gopi-frame/contract/support"
+	"github.com/gopi-frame/support/lists"
+)
+
+// format formats the stack like the other containers of the module,
+// values are given from the top and only the first five are written
+func format[E any](name string, values []E) string {
+	str := new(strings.Builder)
+	str.WriteString(fmt.Sprintf("%s[%T](len=%d)", name, *new(E), len(values)))
+	str.WriteByte('{')
+	str.WriteByte('\n')
+	for index, value := range values {
+		if index >= 5 {
+			str.WriteString("\t...\n")
+			break
+		}
+		str.WriteByte('\t')
+		if v, ok := any(value).(support.Stringable); ok {
+			str.WriteString(v.String())
+		} else {
+			str.WriteString(fmt.Sprintf("%v", value))
+		}
+		str.WriteByte(',')
+		str.WriteByte('\n')
+	}
+	str.WriteByte('}')
+	return str.String()
+}
+
+// NewStack new stack, the last value is on the top
+func NewStack[E any](values ...E) *Stack[E] {
+	stack := new(Stack[E])
+	stack.items = lists.NewList(values...)
+	return stack
+}
+
+// Stack array stack
+type Stack[E any] struct {
+	items *lists.List[E]
+}
+
+func (s *Stack[E]) Lock() {
+	s.items.Lock()
+}
+
+func (s *Stack[E]) Unlock() {
+	s.items.Unlock()
+}
+
+func (s *Stack[E]) TryLock() bool {
+	return s.items.TryLock()
+}
+
+func (s *Stack[E]) RLock() {
+	s.items.RLock()
+}
+
+func (s *Stack[E]) TryRLock() bool {
+	return s.items.TryRLock()
+}
+
+func (s *Stack[E]) RUnlock() {
+	s.items.RUnlock()
+}
+
+func (s *Stack[E]) Count() int64 {
+	return s.items.Count()
+}
+
+func (s *Stack[E]) IsEmpty() bool {
+	return s.Count() == 0
+}
+
+func (s *Stack[E]) IsNotEmpty() bool {
+	return !s.IsEmpty()
+}
+
+func (s *Stack[E]) Clear() {
+	s.items.Clear()
+}
+
+func (s *Stack[E]) Peek() (E, bool) {
+	return s.items.Last()
+}
+
+func (s *Stack[E]) Push(values ...E) {
+	s.items.Push(values...)
+}
+
+func (s *Stack[E]) Pop() (E, bool) {
+	return s.items.Pop()
+}
+
+// ToArray returns the values from the bottom to the top
+func (s *Stack[E]) ToArray() []E {
+	return append([]E{}, s.items.ToArray()...)
+}
+
+func (s *Stack[E]) ToJSON() ([]byte, error) {
+	return json.Marshal(s.ToArray())
+}
+
+func (s *Stack[E]) MarshalJSON() ([]byte, error) {
+	return s.ToJSON()
+}
+
+// UnmarshalJSON replaces the elements of the stack, the list holding the lock is kept
+func (s *Stack[E]) UnmarshalJSON(data []byte) error {
+	var values = []E{}
+	err := json.Unmarshal(data, &values)
+	if err != nil {
+		return err
+	}
+	if s.items == nil {
+		s.items = lists.NewList[E](values...)
+		return nil
+	}
+	s.items.Clear()
+	s.items.Push(values...)
+	return nil
+}
+
+func (s *Stack[E]) String() string {
+	values := s.ToArray()
+	slices.Reverse(values)
+	return format("Stack", values)
+}
@@ -0,0 +1,78 @@
+package stack
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStack_Count(t *testing.T) {
+	stack := NewStack(1, 2, 3)
+	assert.Equal(t, int64(3), stack.Count())
+	assert.True(t, stack.IsNotEmpty())
+	stack.Clear()
+	assert.True(t, stack.IsEmpty())
+}
+
+func TestStack_Peek(t *testing.T) {
+	stack := NewStack(1, 2, 3)
+	v, ok := stack.Peek()
+	assert.True(t, ok)
+	assert.Equal(t, 3, v)
+	assert.Equal(t, int64(3), stack.Count())
+	_, ok = NewStack[int]().Peek()
+	assert.False(t, ok)
+}
+
+func TestStack_Pop(t *testing.T) {
+	stack := NewStack(1, 2)
+	stack.Push(3, 4)
+	for i := 4; i >= 1; i-- {
+		v, ok := stack.Pop()
+		assert.True(t, ok)
+		assert.Equal(t, i, v)
+	}
+	_, ok := stack.Pop()
+	assert.False(t, ok)
+}
+
+func TestStack_ToJSON(t *testing.T) {
+	stack := NewStack(1, 2, 3)
+	jsonBytes, err := stack.ToJSON()
+	assert.Nil(t, err)
+	assert.JSONEq(t, `[1,2,3]`, string(jsonBytes))
+	jsonBytes, err = json.Marshal(NewStack[int]())
+	assert.Nil(t, err)
+	assert.JSONEq(t, `[]`, string(jsonBytes))
+}
+
+func TestStack_UnmarshalJSON(t *testing.T) {
+	stack := NewStack[int]()
+	err := json.Unmarshal([]byte(`[1,2,3]`), stack)
+	assert.Nil(t, err)
+	assert.Equal(t, []int{1, 2, 3}, stack.ToArray())
+	v, _ := stack.Pop()
+	assert.Equal(t, 3, v)
+
+	// the elements are replaced while the caller holds the lock
+	stack.Lock()
+	err = json.Unmarshal([]byte(`[4,5]`), stack)
+	stack.Unlock()
+	assert.Nil(t, err)
+	assert.Equal(t, []int{4, 5}, stack.ToArray())
+
+	var zero Stack[int]
+	assert.Nil(t, json.Unmarshal([]byte(`[1]`), &zero))
+	assert.Equal(t, []int{1}, zero.ToArray())
+}
+
+func TestStack_String(t *testing.T) {
+	stack := NewStack(1, 2, 3, 4, 5, 6, 7)
+	str := stack.String()
+	pattern := regexp.MustCompile(fmt.Sprintf(`Stack\[int\]\(len=%d\)\{\n\t7,\n\t6,\n(\t\d+,\n){3}\t(\.){3}\n\}`, stack.Count()))
+	assert.True(t, pattern.Match([]byte(str)))
+	assert.Equal(t, []int{1, 2, 3, 4, 5, 6, 7}, stack.ToArray())
+}
@@ -0,0 +1,356 @@
+package tree
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/gopi-frame/contract/support"
+)
+
+// persistentRBNode node of the persistent red black tree, it is never modified once
+// reachable from a tree: the operations modify copies of the nodes on their path
+type persistentRBNode[E any] struct {
+	value E
+	left  *persistentRBNode[E]
+	right *persistentRBNode[E]
+	color bool
+}
+
+func (node *persistentRBNode[E]) clone() *persistentRBNode[E] {
+	n := *node
+	return &n
+}
+
+func (node *persistentRBNode[E]) isRed() bool {
+	return node != nil && node.color == red
+}
+
+func (node *persistentRBNode[E]) leftRotate() *persistentRBNode[E] {
+	node = node.clone()
+	pivot := node.right.clone()
+	node.right = pivot.left
+	pivot.left = node
+	pivot.color = node.color
+	node.color = red
+	return pivot
+}
+
+func (node *persistentRBNode[E]) rightRotate() *persistentRBNode[E] {
+	node = node.clone()
+	pivot := node.left.clone()
+	node.left = pivot.right
+	pivot.right = node
+	pivot.color = node.color
+	node.color = red
+	return pivot
+}
+
+func (node *persistentRBNode[E]) switchColor() *persistentRBNode[E] {
+	node = node.clone()
+	node.color = !node.color
+	node.left = node.left.clone()
+	node.left.color = !node.left.color
+	node.right = node.right.clone()
+	node.right.color = !node.right.color
+	return node
+}
+
+func (node *persistentRBNode[E]) moveRedLeft() *persistentRBNode[E] {
+	node = node.switchColor()
+	if node.right.left.isRed() {
+		node.right = node.right.rightRotate()
+		node = node.leftRotate()
+		node = node.switchColor()
+	}
+	return node
+}
+
+func (node *persistentRBNode[E]) moveRedRight() *persistentRBNode[E] {
+	node = node.switchColor()
+	if node.left.left.isRed() {
+		node = node.rightRotate()
+		node = node.switchColor()
+	}
+	return node
+}
+
+func (node *persistentRBNode[E]) fix() *persistentRBNode[E] {
+	if node.right.isRed() && !node.left.isRed() {
+		node = node.leftRotate()
+	}
+	if node.left.isRed() && node.left.left.isRed() {
+		node = node.rightRotate()
+	}
+	if node.left.isRed() && node.right.isRed() {
+		node = node.switchColor()
+	}
+	return node
+}
+
+// insert returns the new subtree with the value, an equal value is replaced.
+// The result is false when an equal value was replaced.
+func (node *persistentRBNode[E]) insert(value E, comparator support.Comparator[E]) (*persistentRBNode[E], bool) {
+	if node == nil {
+		return &persistentRBNode[E]{value: value, color: red}, true
+	}
+	node = node.clone()
+	added := false
+	result := comparator.Compare(value, node.value)
+	if result == 0 {
+		node.value = value
+	} else if result < 0 {
+		node.left, added = node.left.insert(value, comparator)
+	} else {
+		node.right, added = node.right.insert(value, comparator)
+	}
+	return node.fix(), added
+}
+
+// remove returns the new subtree without the value, which must be present
+func (node *persistentRBNode[E]) remove(value E, comparator support.Comparator[E]) *persistentRBNode[E] {
+	if comparator.Compare(value, node.value) < 0 {
+		if !node.left.isRed() && !node.left.left.isRed() {
+			node = node.moveRedLeft()
+		} else {
+			node = node.clone()
+		}
+		node.left = node.left.remove(value, comparator)
+	} else {
+		if node.left.isRed() {
+			node = node.rightRotate()
+		}
+		if comparator.Compare(value, node.value) == 0 && node.right == nil {
+			return nil
+		}
+		if !node.right.isRed() && !node.right.left.isRed() {
+			node = node.moveRedRight()
+		} else {
+			node = node.clone()
+		}
+		if comparator.Compare(value, node.value) == 0 {
+			node.value = node.right.min().value
+			node.right = node.right.removeMin()
+		} else {
+			node.right = node.right.remove(value, comparator)
+		}
+	}
+	return node.fix()
+}
+
+func (node *persistentRBNode[E]) removeMin() *persistentRBNode[E] {
+	if node.left == nil {
+		return nil
+	}
+	if !node.left.isRed() && !node.left.left.isRed() {
+		node = node.moveRedLeft()
+	} else {
+		node = node.clone()
+	}
+	node.left = node.left.removeMin()
+	return node.fix()
+}
+
+func (node *persistentRBNode[E]) min() *persistentRBNode[E] {
+	for node.left != nil {
+		node = node.left
+	}
+	return node
+}
+
+func (node *persistentRBNode[E]) max() *persistentRBNode[E] {
+	for node.right != nil {
+		node = node.right
+	}
+	return node
+}
+
+func (node *persistentRBNode[E]) find(value E, comparator support.Comparator[E]) *persistentRBNode[E] {
+	for node != nil {
+		result := comparator.Compare(value, node.value)
+		if result == 0 {
+			return node
+		} else if result < 0 {
+			node = node.left
+		} else {
+			node = node.right
+		}
+	}
+	return nil
+}
+
+func (node *persistentRBNode[E]) each(callback func(value E) bool) bool {
+	if node == nil {
+		return true
+	}
+	return node.left.each(callback) && callback(node.value) && node.right.each(callback)
+}
+
+// NewPersistentRBTree new persistent rb tree
+func NewPersistentRBTree[E any](comparator support.Comparator[E], values ...E) *PersistentRBTree[E] {
+	return (&PersistentRBTree[E]{comparator: comparator}).Push(values...)
+}
+
+// PersistentRBTree immutable red black tree
+//
+// It is the left-leaning red black tree of RBTree made persistent by path copying:
+// Push and Remove return a new tree which shares all the nodes off the updated path
+// with the previous one, in O(log n). Unlike RBTree it holds distinct values, pushing
+// a value equal to an existing one replaces it. A tree never changes once built, so it
+// can be shared between goroutines without locking.
+type PersistentRBTree[E any] struct {
+	root       *persistentRBNode[E]
+	count      int
+	comparator support.Comparator[E]
+}
+
+func (t *PersistentRBTree[E]) Count() int64 {
+	return int64(t.count)
+}
+
+func (t *PersistentRBTree[E]) IsEmpty() bool {
+	return t.count == 0
+}
+
+func (t *PersistentRBTree[E]) IsNotEmpty() bool {
+	return !t.IsEmpty()
+}
+
+func (t *PersistentRBTree[E]) Comparator() support.Comparator[E] {
+	return t.comparator
+}
+
+func (t *PersistentRBTree[E]) Contains(value E) bool {
+	return t.root.find(value, t.comparator) != nil
+}
+
+// Find returns the value equal to the given one
+func (t *PersistentRBTree[E]) Find(value E) (E, bool) {
+	if node := t.root.find(value, t.comparator); node != nil {
+		return node.value, true
+	}
+	return *new(E), false
+}
+
+// Push returns a new tree with the values added
+func (t *PersistentRBTree[E]) Push(values ...E) *PersistentRBTree[E] {
+	next := *t
+	for _, value := range values {
+		root, added := next.root.insert(value, next.comparator)
+		root.color = black
+		next.root = root
+		if added {
+			next.count++
+		}
+	}
+	return &next
+}
+
+// Remove returns a new tree without the value
+func (t *PersistentRBTree[E]) Remove(value E) *PersistentRBTree[E] {
+	if !t.Contains(value) {
+		return t
+	}
+	next := *t
+	if !next.root.left.isRed() && !next.root.right.isRed() {
+		next.root = next.root.clone()
+		next.root.color = red
+	}
+	next.root = next.root.remove(value, next.comparator)
+	if next.root.isRed() {
+		next.root = next.root.clone()
+		next.root.color = black
+	}
+	next.count--
+	return &next
+}
+
+// Clear returns an empty tree with the same comparator
+func (t *PersistentRBTree[E]) Clear() *PersistentRBTree[E] {
+	return &PersistentRBTree[E]{comparator: t.comparator}
+}
+
+func (t *PersistentRBTree[E]) First() (E, bool) {
+	if t.root == nil {
+		return *new(E), false
+	}
+	return t.root.min().value, true
+}
+
+func (t *PersistentRBTree[E]) FirstOr(value E) E {
+	if v, ok := t.First(); ok {
+		return v
+	}
+	return value
+}
+
+func (t *PersistentRBTree[E]) Last() (E, bool) {
+	if t.root == nil {
+		return *new(E), false
+	}
+	return t.root.max().value, true
+}
+
+func (t *PersistentRBTree[E]) LastOr(value E) E {
+	if v, ok := t.Last(); ok {
+		return v
+	}
+	return value
+}
+
+// Each calls the callback with the values in order until it returns false
+func (t *PersistentRBTree[E]) Each(callback func(value E) bool) {
+	t.root.each(callback)
+}
+
+func (t *PersistentRBTree[E]) ToArray() []E {
+	values := make([]E, 0, t.count)
+	t.Each(func(value E) bool {
+		values = append(values, value)
+		return true
+	})
+	return values
+}
+
+func (t *PersistentRBTree[E]) ToJSON() ([]byte, error) {
+	return json.Marshal(t.ToArray())
+}
+
+func (t *PersistentRBTree[E]) MarshalJSON() ([]byte, error) {
+	return t.ToJSON()
+}
+
+// UnmarshalJSON replaces the values of the tree, it keeps the comparator of the tree
+func (t *PersistentRBTree[E]) UnmarshalJSON(data []byte) error {
+	values := make([]E, 0)
+	if err := json.Unmarshal(data, &values); err != nil {
+		return err
+	}
+	*t = *t.Clear().Push(values...)
+	return nil
+}
+
+func (t *PersistentRBTree[E]) String() string {
+	str := new(strings.Builder)
+	str.WriteString(fmt.Sprintf("PersistentRBTree[%T](len=%d)", *new(E), t.count))
+	str.WriteByte('{')
+	str.WriteByte('\n')
+	index := 0
+	t.Each(func(value E) bool {
+		str.WriteByte('\t')
+		if v, ok := any(value).(support.Stringable); ok {
+			str.WriteString(v.String())
+		} else {
+			str.WriteString(fmt.Sprintf("%v", value))
+		}
+		str.WriteByte(',')
+		str.WriteByte('\n')
+		index++
+		return index < 5
+	})
+	if t.count > 5 {
+		str.WriteString("\t...\n")
+	}
+	str.WriteByte('}')
+	return str.String()
+}
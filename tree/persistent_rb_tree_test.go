@@ -0,0 +1,136 @@
+package tree
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"regexp"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// checkPersistentRBTree asserts the invariants of the left-leaning red black tree
+// and returns the values in order
+func checkPersistentRBTree(t *testing.T, tree *PersistentRBTree[int]) []int {
+	var check func(node *persistentRBNode[int]) int
+	check = func(node *persistentRBNode[int]) int {
+		if node == nil {
+			return 1
+		}
+		assert.False(t, node.right.isRed(), "red right link at %d", node.value)
+		if node.isRed() {
+			assert.False(t, node.left.isRed(), "two red links in a row at %d", node.value)
+		}
+		left, right := check(node.left), check(node.right)
+		assert.Equal(t, left, right, "unbalanced at %d", node.value)
+		if node.isRed() {
+			return left
+		}
+		return left + 1
+	}
+	assert.False(t, tree.root.isRed())
+	check(tree.root)
+	values := tree.ToArray()
+	assert.True(t, slices.IsSorted(values))
+	assert.Equal(t, int64(len(values)), tree.Count())
+	return values
+}
+
+func TestPersistentRBTree_Push(t *testing.T) {
+	tree := NewPersistentRBTree[int](_cmp{})
+	next := tree.Push(3, 1, 2, 2)
+	assert.True(t, tree.IsEmpty())
+	assert.Equal(t, []int{1, 2, 3}, checkPersistentRBTree(t, next))
+	assert.True(t, next.Contains(2))
+	assert.False(t, next.Contains(4))
+	first, _ := next.First()
+	last, _ := next.Last()
+	assert.Equal(t, 1, first)
+	assert.Equal(t, 3, last)
+}
+
+func TestPersistentRBTree_Remove(t *testing.T) {
+	tree := NewPersistentRBTree[int](_cmp{}, 1, 2, 3, 4, 5)
+	next := tree.Remove(3).Remove(1).Remove(10)
+	assert.Equal(t, []int{1, 2, 3, 4, 5}, checkPersistentRBTree(t, tree))
+	assert.Equal(t, []int{2, 4, 5}, checkPersistentRBTree(t, next))
+	assert.Same(t, next, next.Remove(10))
+	assert.True(t, next.Remove(2).Remove(4).Remove(5).IsEmpty())
+}
+
+func TestPersistentRBTree_Model(t *testing.T) {
+	rand := rand.New(rand.NewSource(1))
+	tree := NewPersistentRBTree[int](_cmp{})
+	model := map[int]struct{}{}
+	type snapshot struct {
+		tree   *PersistentRBTree[int]
+		values []int
+	}
+	snapshots := []snapshot{}
+	for round := 0; round < 3000; round++ {
+		value := rand.Intn(500)
+		if rand.Intn(3) == 0 {
+			tree = tree.Remove(value)
+			delete(model, value)
+		} else {
+			tree = tree.Push(value)
+			model[value] = struct{}{}
+		}
+		if round%100 == 0 {
+			values := make([]int, 0, len(model))
+			for value := range model {
+				values = append(values, value)
+			}
+			slices.Sort(values)
+			assert.Equal(t, values, checkPersistentRBTree(t, tree))
+			snapshots = append(snapshots, snapshot{tree, values})
+		}
+	}
+	// the previous versions are left untouched
+	for _, s := range snapshots {
+		assert.Equal(t, s.values, checkPersistentRBTree(t, s.tree))
+	}
+}
+
+func TestPersistentRBTree_Find(t *testing.T) {
+	tree := NewPersistentRBTree[_entry](_entryCmp{}, _entry{1, "a"}, _entry{2, "b"})
+	next := tree.Push(_entry{1, "c"})
+	found, ok := next.Find(_entry{key: 1})
+	assert.True(t, ok)
+	assert.Equal(t, "c", found.value)
+	found, _ = tree.Find(_entry{key: 1})
+	assert.Equal(t, "a", found.value)
+	assert.Equal(t, int64(2), next.Count())
+}
+
+type _entry struct {
+	key   int
+	value string
+}
+
+type _entryCmp struct{}
+
+func (c _entryCmp) Compare(a, b _entry) int {
+	return _cmp{}.Compare(a.key, b.key)
+}
+
+func TestPersistentRBTree_JSON(t *testing.T) {
+	tree := NewPersistentRBTree[int](_cmp{}, 3, 1, 2)
+	jsonBytes, err := json.Marshal(tree)
+	assert.Nil(t, err)
+	assert.JSONEq(t, `[1,2,3]`, string(jsonBytes))
+
+	unmarshalled := NewPersistentRBTree[int](_cmp{}, 10)
+	err = json.Unmarshal([]byte(`[6,4,5]`), unmarshalled)
+	assert.Nil(t, err)
+	assert.Equal(t, []int{4, 5, 6}, checkPersistentRBTree(t, unmarshalled))
+}
+
+func TestPersistentRBTree_String(t *testing.T) {
+	tree := NewPersistentRBTree[int](_cmp{}, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10)
+	str := tree.String()
+	pattern := regexp.MustCompile(fmt.Sprintf(`PersistentRBTree\[int\]\(len=%d\)\{\n(\t\d+,\n){5}\t(\.){3}\n\}`, tree.Count()))
+	assert.True(t, pattern.Match([]byte(str)))
+}